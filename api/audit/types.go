@@ -0,0 +1,54 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+// Config captures the configuration of the audit log to which every scaling and pod deletion done by DWD is appended.
+type Config struct {
+	// Sink is the type of sink to which audit records are written. Allowed values are File and ConfigMap.
+	Sink SinkType `json:"sink"`
+	// File captures the configuration of the File sink. It is only considered if Sink is File.
+	File *FileSink `json:"file,omitempty"`
+	// ConfigMap captures the configuration of the ConfigMap sink. It is only considered if Sink is ConfigMap.
+	ConfigMap *ConfigMapSink `json:"configMap,omitempty"`
+}
+
+// SinkType is the type of sink to which audit records are written.
+type SinkType string
+
+const (
+	// SinkTypeFile writes audit records as JSON lines to a file which is rotated once it exceeds its maximum size.
+	SinkTypeFile SinkType = "File"
+	// SinkTypeConfigMap writes audit records as JSON lines to a ConfigMap per namespace which retains only the latest records.
+	SinkTypeConfigMap SinkType = "ConfigMap"
+)
+
+// FileSink captures the configuration of a sink which writes audit records to a rotating file.
+type FileSink struct {
+	// Path is the path of the file to which audit records are appended.
+	Path string `json:"path"`
+	// MaxSizeBytes is the size beyond which the file is rotated. If not specified its default value will be 10MiB.
+	MaxSizeBytes *int64 `json:"maxSizeBytes,omitempty"`
+	// MaxBackups is the number of rotated files which are retained. If not specified its default value will be 3.
+	MaxBackups *int `json:"maxBackups,omitempty"`
+}
+
+// ConfigMapSink captures the configuration of a sink which writes audit records to a ConfigMap in the namespace of the shoot
+// control plane. The ConfigMap acts as a ring buffer, once it holds MaxRecords records the oldest record is dropped.
+type ConfigMapSink struct {
+	// Name is the name of the ConfigMap. If not specified its default value will be dependency-watchdog-audit-log.
+	Name *string `json:"name,omitempty"`
+	// MaxRecords is the maximum number of records retained in the ConfigMap. If not specified its default value will be 100.
+	MaxRecords *int `json:"maxRecords,omitempty"`
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifier
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config captures the configuration of the notifier which POSTs a JSON payload to HTTP webhooks on transitions observed by DWD.
+type Config struct {
+	// Webhooks are the endpoints which are notified.
+	Webhooks []Webhook `json:"webhooks"`
+	// QueueSize is the maximum number of notifications which are queued for delivery per webhook. Notifications which do not fit
+	// into the queue are dropped. If not specified its default value will be 100.
+	QueueSize *int `json:"queueSize,omitempty"`
+	// MaxAttempts is the maximum number of attempts to deliver a notification to a webhook. If not specified its default value will be 3.
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+	// InitialBackOff is the duration to back off after the first failed delivery attempt. It is doubled after every subsequent
+	// failed attempt up to MaxBackOff. If not specified its default value will be 1s.
+	InitialBackOff *metav1.Duration `json:"initialBackOff,omitempty"`
+	// MaxBackOff is the maximum duration to back off between delivery attempts. If not specified its default value will be 30s.
+	MaxBackOff *metav1.Duration `json:"maxBackOff,omitempty"`
+	// Timeout is the timeout of a single delivery attempt. If not specified its default value will be 10s.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Webhook is an HTTP endpoint to which notifications are POSTed.
+type Webhook struct {
+	// Name identifies the webhook in logs. It must be unique amongst all webhooks.
+	Name string `json:"name"`
+	// URL is the http or https URL of the webhook.
+	URL string `json:"url"`
+	// SigningKeyFile is the path to a file which contains the key with which the payload is signed. If specified, the hex encoded
+	// HMAC-SHA256 of the payload is sent in the X-DWD-Signature header as `sha256=<signature>`.
+	SigningKeyFile *string `json:"signingKeyFile,omitempty"`
+	// Events are the events of which the webhook is notified. If not specified, the webhook is notified of all events.
+	Events []EventType `json:"events,omitempty"`
+}
+
+// EventType is the type of transition of which a webhook is notified.
+type EventType string
+
+const (
+	// EventTypeExternalProbeUnhealthy is sent by the prober once the external probe has turned unhealthy.
+	EventTypeExternalProbeUnhealthy EventType = "ExternalProbeUnhealthy"
+	// EventTypeScaleDownStarted is sent by the prober once it has decided to scale down the dependent resources.
+	EventTypeScaleDownStarted EventType = "ScaleDownStarted"
+	// EventTypeScaleDownCompleted is sent by the prober once a scale-down flow has scaled down dependent resources.
+	EventTypeScaleDownCompleted EventType = "ScaleDownCompleted"
+	// EventTypeScaleDownFailed is sent by the prober once a scale-down flow has failed.
+	EventTypeScaleDownFailed EventType = "ScaleDownFailed"
+	// EventTypeScaleUpCompleted is sent by the prober once a scale-up flow has scaled up dependent resources.
+	EventTypeScaleUpCompleted EventType = "ScaleUpCompleted"
+	// EventTypePodDeleted is sent by the weeder once it has deleted or evicted a dependant pod.
+	EventTypePodDeleted EventType = "PodDeleted"
+	// EventTypeWorkloadRestarted is sent by the weeder once it has triggered a rollout restart of the workload owning a dependant pod.
+	EventTypeWorkloadRestarted EventType = "WorkloadRestarted"
+	// EventTypeWeedingLimitReached is sent by the weeder once it has stopped deleting pods as a configured limit has been reached.
+	EventTypeWeedingLimitReached EventType = "WeedingLimitReached"
+)
+
+// AllEventTypes are all types of events of which a webhook can be notified.
+var AllEventTypes = []EventType{
+	EventTypeExternalProbeUnhealthy,
+	EventTypeScaleDownStarted,
+	EventTypeScaleDownCompleted,
+	EventTypeScaleDownFailed,
+	EventTypeScaleUpCompleted,
+	EventTypePodDeleted,
+	EventTypeWorkloadRestarted,
+	EventTypeWeedingLimitReached,
+}
@@ -15,6 +15,8 @@
 package prober
 
 import (
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -23,7 +25,8 @@ import (
 type Config struct {
 	// InternalKubeConfigSecretName is the name of the kubernetes secret which has the kubeconfig to connect to the shoot control plane API server via internal domain
 	InternalKubeConfigSecretName string `json:"internalKubeConfigSecretName"`
-	// ExternalKubeConfigSecretName is the name of the kubernetes secret which has the kubeconfig to connect to the shoot control plane API server via external domain
+	// ExternalKubeConfigSecretName is the name of the kubernetes secret which has the kubeconfig to connect to the shoot control plane API server via external domain.
+	// It is only required if no ExternalProbeTargets are configured or at least one of them does not specify its own KubeConfigSecretName.
 	ExternalKubeConfigSecretName string `json:"externalKubeConfigSecretName"`
 	// ProbeInterval is the interval with which the probe will be run
 	ProbeInterval *metav1.Duration `json:"probeInterval,omitempty"`
@@ -41,8 +44,218 @@ type Config struct {
 	BackoffJitterFactor *float64 `json:"backoffJitterFactor,omitempty"`
 	// DependentResourceInfos are the dependent resources that should be considered for scaling in case the shoot control API server cannot be reached via external domain
 	DependentResourceInfos []DependentResourceInfo `json:"dependentResourceInfos"`
+	// ScaleDownFailurePolicy defines how resources which have already been scaled down are treated when a scale-down flow fails part way.
+	// If this field is not specified, then ScaleDownFailurePolicyRetry will be assumed.
+	ScaleDownFailurePolicy *ScaleDownFailurePolicy `json:"scaleDownFailurePolicy,omitempty"`
+	// ResourceCheckTimeout is the timeout to wait for a dependent resource to reach its minimum target replicas once it has been scaled
+	ResourceCheckTimeout *metav1.Duration `json:"resourceCheckTimeout,omitempty"`
+	// ResourceCheckInterval is the interval with which a dependent resource is checked to have reached its minimum target replicas once it has been scaled
+	ResourceCheckInterval *metav1.Duration `json:"resourceCheckInterval,omitempty"`
+	// MaxScaleDownDuration is the maximum duration for which dependent resources are kept scaled down. Once exceeded, the prober
+	// scales up the dependent resources irrespective of the state of the external probe and stays fail-open until the external
+	// probe is healthy again. If not specified, dependent resources are kept scaled down for as long as the external probe is unhealthy.
+	MaxScaleDownDuration *metav1.Duration `json:"maxScaleDownDuration,omitempty"`
+	// ScaledDownResourceRestoration captures the configuration to restore dependent resources which have been left scaled down by DWD.
+	ScaledDownResourceRestoration *ScaledDownResourceRestoration `json:"scaledDownResourceRestoration,omitempty"`
+	// EscalationStages define which dependent resources are scaled down depending on how long the external probe has been unhealthy.
+	// Stages must be ordered by After and every dependent resource must be part of exactly one stage. If not specified, all
+	// dependent resources are scaled down as soon as the external probe is unhealthy.
+	EscalationStages []EscalationStage `json:"escalationStages,omitempty"`
+	// DecisionMatrix maps each combination of internal and external probe health to the action taken by the prober. Combinations
+	// which are not specified are filled from the default decision matrix which scales down when the internal probe is healthy
+	// and the external probe is unhealthy, scales up when both are healthy and does nothing otherwise.
+	DecisionMatrix []Decision `json:"decisionMatrix,omitempty"`
+	// ExternalProbeTargets are the targets via which the shoot control plane API server is probed externally. If not specified,
+	// a single target using ExternalKubeConfigSecretName is probed.
+	ExternalProbeTargets []ExternalProbeTarget `json:"externalProbeTargets,omitempty"`
+	// ExternalProbePolicy defines how the health of the external probe is computed from the health of its targets.
+	// If this field is not specified, then ExternalProbePolicyAny will be assumed.
+	ExternalProbePolicy *ExternalProbePolicy `json:"externalProbePolicy,omitempty"`
+	// ExternalProbeProxyURL is the URL of an HTTP CONNECT or SOCKS proxy via which the external probe reaches the shoot control
+	// plane API server. It applies to all external probe targets which do not specify their own ProxyURL.
+	ExternalProbeProxyURL *string `json:"externalProbeProxyURL,omitempty"`
+	// AdaptiveProbeInterval captures the configuration to probe faster during a suspected outage of the shoot control plane.
+	AdaptiveProbeInterval *AdaptiveProbeInterval `json:"adaptiveProbeInterval,omitempty"`
+	// Notifier captures the configuration of the webhooks which are notified once the external probe turns unhealthy and
+	// about the outcome of scale flows. If not specified, no webhooks are notified.
+	Notifier *napi.Config `json:"notifier,omitempty"`
+	// Audit captures the configuration of the audit log to which every scaling of a dependent resource is appended.
+	// If not specified, no audit log is written.
+	Audit *aapi.Config `json:"audit,omitempty"`
+	// VerdictAnnotation captures the configuration to publish the verdict of the prober as an annotation on the Cluster resource.
+	VerdictAnnotation *VerdictAnnotation `json:"verdictAnnotation,omitempty"`
+	// ProbeStatusResource captures the configuration to expose the state of the prober as a ProbeStatus resource in the shoot
+	// control namespace.
+	ProbeStatusResource *ProbeStatusResource `json:"probeStatusResource,omitempty"`
 }
 
+// ProbeStatusResource captures the configuration to expose the state of the prober as a ProbeStatus resource in the shoot
+// control namespace. The ProbeStatus custom resource definition has to be installed if it is enabled.
+type ProbeStatusResource struct {
+	// Enabled determines if the ProbeStatus resource is maintained. If not specified its default value will be false.
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinUpdateInterval is the minimum duration between two updates of the ProbeStatus resource. A changed status is written
+	// with the first probe run after MinUpdateInterval has elapsed since the last update. If not specified its default value will be 30s.
+	MinUpdateInterval *metav1.Duration `json:"minUpdateInterval,omitempty"`
+	// ScaleHistoryLimit is the number of most recent scale flows which are kept in the scale history. If not specified its
+	// default value will be 10.
+	ScaleHistoryLimit *int `json:"scaleHistoryLimit,omitempty"`
+}
+
+// VerdictAnnotation captures the configuration to publish the verdict of the prober as JSON in the VerdictAnnotationKey
+// annotation of the Cluster resource of the shoot.
+type VerdictAnnotation struct {
+	// Enabled determines if the verdict is published. If not specified its default value will be false.
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinUpdateInterval is the minimum duration between two updates of the annotation. A changed verdict is published with the
+	// first probe run after MinUpdateInterval has elapsed since the last update. If not specified its default value will be 30s.
+	MinUpdateInterval *metav1.Duration `json:"minUpdateInterval,omitempty"`
+}
+
+// VerdictAnnotationKey is the key of the annotation on the Cluster resource in which the prober publishes its Verdict.
+const VerdictAnnotationKey = "dependency-watchdog.gardener.cloud/prober-verdict"
+
+// Verdict is the verdict of the prober of a shoot as published in the VerdictAnnotationKey annotation of the Cluster resource.
+type Verdict struct {
+	// Internal is the verdict of the internal probe.
+	Internal ProbeVerdict `json:"internal"`
+	// External is the verdict of the external probe.
+	External ProbeVerdict `json:"external"`
+	// ScaledDownResources are the dependent resources, formatted as kind/name, which have been scaled down by the prober
+	// and have not been scaled up since.
+	ScaledDownResources []string `json:"scaledDownResources,omitempty"`
+	// ScaledDownSince is the time at which the external probe has been found unhealthy for the current scale-down.
+	ScaledDownSince *metav1.Time `json:"scaledDownSince,omitempty"`
+	// FailOpen is true if the prober has given up keeping the dependent resources scaled down since MaxScaleDownDuration has been exceeded.
+	FailOpen bool `json:"failOpen,omitempty"`
+	// UpdateTime is the time at which the verdict has been published.
+	UpdateTime metav1.Time `json:"updateTime"`
+}
+
+// ProbeVerdict is the verdict of a single probe.
+type ProbeVerdict struct {
+	// Health is the health of the probe.
+	Health ProbeHealth `json:"health"`
+	// LastTransitionTime is the time at which Health last changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// AdaptiveProbeInterval captures the configuration to adapt the interval of a probe. Once the external probe fails, the interval
+// is shortened to MinInterval so that FailureThreshold is reached faster. Once the external probe is healthy again or is not
+// run, the interval is relaxed back to ProbeInterval. While the internal probe is unhealthy, it is backed off exponentially
+// up to MaxBackoffDuration.
+type AdaptiveProbeInterval struct {
+	// Enabled determines if the probe interval is adapted. If not specified its default value will be false.
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinInterval is the interval with which the probe is run once the external probe has failed. It must not be greater than
+	// ProbeInterval. If not specified its default value will be 2s.
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+	// MaxBackoffDuration is the maximum duration for which the internal probe is backed off while it is unhealthy. It does
+	// not bound the probe interval. It must not be less than InternalProbeFailureBackoffDuration. If not specified its
+	// default value will be 5m.
+	MaxBackoffDuration *metav1.Duration `json:"maxBackoffDuration,omitempty"`
+}
+
+// ExternalProbeTarget is a target via which the shoot control plane API server is probed externally.
+type ExternalProbeTarget struct {
+	// Name identifies the target in logs and in the probe status. It must be unique amongst all targets.
+	Name string `json:"name"`
+	// KubeConfigSecretName is the name of the kubernetes secret which has the kubeconfig to connect to the shoot control plane
+	// API server. If not specified, ExternalKubeConfigSecretName is used.
+	KubeConfigSecretName *string `json:"kubeConfigSecretName,omitempty"`
+	// ServerURL overrides the URL of the API server in the kubeconfig. The TLS server name of the kubeconfig is retained so that
+	// the serving certificate can still be verified when the API server is reached via e.g. the IP of its load balancer.
+	ServerURL *string `json:"serverURL,omitempty"`
+	// ProxyURL is the URL of an HTTP CONNECT (http, https) or SOCKS (socks5, socks5h) proxy via which the API server is reached.
+	// If not specified, Config.ExternalProbeProxyURL is used.
+	ProxyURL *string `json:"proxyURL,omitempty"`
+}
+
+// ExternalProbePolicy defines how the health of the external probe is computed from the health of its targets.
+type ExternalProbePolicy string
+
+const (
+	// ExternalProbePolicyAny considers the external probe healthy if any target is healthy and unhealthy if all targets are unhealthy.
+	ExternalProbePolicyAny ExternalProbePolicy = "Any"
+	// ExternalProbePolicyAll considers the external probe healthy if all targets are healthy and unhealthy if any target is unhealthy.
+	ExternalProbePolicyAll ExternalProbePolicy = "All"
+	// ExternalProbePolicyQuorum considers the external probe healthy if a majority of the targets is healthy and unhealthy
+	// if a majority of the targets can no longer be healthy.
+	ExternalProbePolicyQuorum ExternalProbePolicy = "Quorum"
+)
+
+// ProbeHealth is the health of a probe as determined by the success and failure thresholds.
+type ProbeHealth string
+
+const (
+	// ProbeHealthHealthy indicates that the probe has succeeded at least successThreshold times consecutively.
+	ProbeHealthHealthy ProbeHealth = "Healthy"
+	// ProbeHealthUnhealthy indicates that the probe has failed at least failureThreshold times consecutively.
+	ProbeHealthUnhealthy ProbeHealth = "Unhealthy"
+	// ProbeHealthUnknown indicates that the probe has neither reached the success nor the failure threshold, or has not been run.
+	ProbeHealthUnknown ProbeHealth = "Unknown"
+)
+
+// DecisionAction is the action taken by the prober for a combination of internal and external probe health.
+type DecisionAction string
+
+const (
+	// DecisionActionNone does not scale any dependent resource.
+	DecisionActionNone DecisionAction = "None"
+	// DecisionActionScaleDown scales down dependent resources. These are either the resources given by Decision.ResourceNames
+	// or, if none are given, the resources of the escalation stages which are due.
+	DecisionActionScaleDown DecisionAction = "ScaleDown"
+	// DecisionActionScaleUp scales up all dependent resources.
+	DecisionActionScaleUp DecisionAction = "ScaleUp"
+	// DecisionActionAlertOnly does not scale any dependent resource but records a warning event and increments a metric.
+	DecisionActionAlertOnly DecisionAction = "AlertOnly"
+)
+
+// Decision maps a combination of internal and external probe health to an action.
+type Decision struct {
+	// Internal is the health of the internal probe.
+	Internal ProbeHealth `json:"internal"`
+	// External is the health of the external probe.
+	External ProbeHealth `json:"external"`
+	// Action is the action taken by the prober for this combination.
+	Action DecisionAction `json:"action"`
+	// ResourceNames are the names of the dependent resources, as given by DependentResourceInfo.Ref.Name, which are scaled down.
+	// It is only allowed for DecisionActionScaleDown. If not specified, escalation stages are used to determine the resources.
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+// EscalationStage captures the dependent resources which are scaled down once the external probe has been unhealthy for
+// at least After. Resources of all previous stages remain scaled down. Scale-up de-escalates the stages in reverse order.
+type EscalationStage struct {
+	// After is the duration for which the external probe has to be unhealthy before the dependent resources of this stage are scaled down.
+	After metav1.Duration `json:"after"`
+	// ResourceNames are the names of the dependent resources, as given by DependentResourceInfo.Ref.Name, which are scaled down in this stage.
+	ResourceNames []string `json:"resourceNames"`
+}
+
+// ScaledDownResourceRestoration captures the configuration to restore dependent resources which carry the replicas annotation
+// set by DWD during scale-down, but whose shoot no longer has an active prober or has a healthy prober.
+type ScaledDownResourceRestoration struct {
+	// Enabled determines if resources which have been left scaled down are restored. If not specified its default value will be true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// GracePeriod is the duration for which a shoot control namespace has to be continuously found eligible for restoration before
+	// its resources are restored. If not specified its default value will be 5m.
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+	// Interval is the interval with which shoot control namespaces are checked for resources that have been left scaled down.
+	// If not specified its default value will be 1m.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ScaleDownFailurePolicy is the compensation policy which is applied when a scale-down flow fails.
+type ScaleDownFailurePolicy string
+
+const (
+	// ScaleDownFailurePolicyRetry leaves the resources that have already been scaled down as they are. The scale-down flow will be re-attempted with the next probe run.
+	ScaleDownFailurePolicyRetry ScaleDownFailurePolicy = "Retry"
+	// ScaleDownFailurePolicyRollback restores the resources that have been scaled down by a failed scale-down flow to the replicas they had prior to the scale-down.
+	ScaleDownFailurePolicyRollback ScaleDownFailurePolicy = "Rollback"
+)
+
 // DependentResourceInfo captures a dependent resource which should be scaled
 type DependentResourceInfo struct {
 	// Ref identifies a resource
@@ -65,4 +278,42 @@ type ScaleInfo struct {
 	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
 	// ScaleTimeout is the time timeout duration to wait for when attempting to update the scaling sub-resource.
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// RetryPolicy captures how a failed attempt to scale the resource is retried.
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+	// Condition is an optional CEL expression which has to evaluate to true for the resource to be scaled. It is evaluated
+	// against the variables `shoot`, `resource` (metadata and status of the resource) and `probe` (status of the internal and
+	// external probe). If it evaluates to false then scaling of the resource is skipped.
+	Condition *string `json:"condition,omitempty"`
 }
+
+// RetryPolicy captures the configuration to retry scaling of a dependent resource
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts to scale the resource. If not specified its default value will be 3.
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+	// InitialBackOff is the back off duration after the first failed attempt. The back off is doubled after every subsequent failed attempt.
+	// If not specified its default value will be 100ms.
+	InitialBackOff *metav1.Duration `json:"initialBackOff,omitempty"`
+	// MaxBackOff caps the back off duration between two attempts. If not specified its default value will be 5s.
+	MaxBackOff *metav1.Duration `json:"maxBackOff,omitempty"`
+	// NonRetriableErrors are the classes of errors for which scaling of the resource will not be re-attempted.
+	// If not specified, all errors are retried.
+	NonRetriableErrors []ErrorClass `json:"nonRetriableErrors,omitempty"`
+}
+
+// ErrorClass classifies the errors returned by the kubernetes API server.
+type ErrorClass string
+
+const (
+	// ErrorClassNotFound classifies errors where the resource could not be found.
+	ErrorClassNotFound ErrorClass = "NotFound"
+	// ErrorClassInvalid classifies errors where the request has been rejected as invalid.
+	ErrorClassInvalid ErrorClass = "Invalid"
+	// ErrorClassForbidden classifies errors where the request has been forbidden.
+	ErrorClassForbidden ErrorClass = "Forbidden"
+	// ErrorClassUnauthorized classifies errors where the request has not been authorized.
+	ErrorClassUnauthorized ErrorClass = "Unauthorized"
+	// ErrorClassBadRequest classifies errors where the request has been rejected as malformed.
+	ErrorClassBadRequest ErrorClass = "BadRequest"
+	// ErrorClassConflict classifies errors where the request could not be completed due to a conflict.
+	ErrorClassConflict ErrorClass = "Conflict"
+)
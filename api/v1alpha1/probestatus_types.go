@@ -0,0 +1,198 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProbeStatusName is the name of the ProbeStatus which the prober maintains in the shoot control namespace.
+const ProbeStatusName = "prober"
+
+// ProbeHealth is the health of a probe as determined by the success and failure thresholds.
+type ProbeHealth string
+
+const (
+	// ProbeHealthHealthy indicates that the probe has succeeded at least successThreshold times consecutively.
+	ProbeHealthHealthy ProbeHealth = "Healthy"
+	// ProbeHealthUnhealthy indicates that the probe has failed at least failureThreshold times consecutively.
+	ProbeHealthUnhealthy ProbeHealth = "Unhealthy"
+	// ProbeHealthUnknown indicates that the probe has neither reached the success nor the failure threshold, or has not been run.
+	ProbeHealthUnknown ProbeHealth = "Unknown"
+)
+
+// ProbeErrorClass classifies the errors with which a probe has failed.
+type ProbeErrorClass string
+
+const (
+	// ProbeErrorClassTimeout classifies errors where the API server could not be reached in time.
+	ProbeErrorClassTimeout ProbeErrorClass = "Timeout"
+	// ProbeErrorClassProxy classifies errors where the proxy could not be reached or has refused to connect to the API server.
+	ProbeErrorClassProxy ProbeErrorClass = "Proxy"
+	// ProbeErrorClassUnauthorized classifies errors where the request of the probe has not been authenticated or authorized.
+	ProbeErrorClassUnauthorized ProbeErrorClass = "Unauthorized"
+	// ProbeErrorClassThrottled classifies errors where the API server has throttled the request of the probe.
+	ProbeErrorClassThrottled ProbeErrorClass = "Throttled"
+	// ProbeErrorClassAPIServer classifies errors which have been returned by the API server.
+	ProbeErrorClassAPIServer ProbeErrorClass = "APIServer"
+	// ProbeErrorClassNetwork classifies all other errors, e.g. where the connection to the API server could not be established.
+	ProbeErrorClassNetwork ProbeErrorClass = "Network"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=dwdps
+// +kubebuilder:printcolumn:name="Internal",type=string,JSONPath=`.status.internal.health`
+// +kubebuilder:printcolumn:name="External",type=string,JSONPath=`.status.external.health`
+// +kubebuilder:printcolumn:name="Scaled Down",type=string,JSONPath=`.status.scaledDownResources`,priority=1
+// +kubebuilder:printcolumn:name="Fail Open",type=boolean,JSONPath=`.status.failOpen`
+// +kubebuilder:printcolumn:name="Updated",type=date,JSONPath=`.status.lastUpdateTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ProbeStatus exposes the state of the prober of a shoot. It is maintained by the prober in the shoot control namespace and
+// owned by the Cluster resource of the shoot.
+type ProbeStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Status is the most recently observed state of the prober.
+	Status ProbeStatusStatus `json:"status,omitempty"`
+}
+
+// ProbeStatusStatus is the most recently observed state of the prober of a shoot.
+type ProbeStatusStatus struct {
+	// Internal is the result of the internal probe.
+	Internal ProbeResult `json:"internal"`
+	// External is the result of the external probe as computed from its targets by the external probe policy.
+	External ProbeResult `json:"external"`
+	// ExternalTargets are the results of the external probe for each of its targets.
+	// +optional
+	ExternalTargets []ExternalTargetResult `json:"externalTargets,omitempty"`
+	// ScaledDownResources are the dependent resources, formatted as kind/name, which have been scaled down by the prober
+	// and have not been scaled up since.
+	// +optional
+	ScaledDownResources []string `json:"scaledDownResources,omitempty"`
+	// ScaledDownSince is the time at which the prober has first decided to scale down the dependent resources.
+	// +optional
+	ScaledDownSince *metav1.Time `json:"scaledDownSince,omitempty"`
+	// FailOpen is true if the prober has given up keeping the dependent resources scaled down since maxScaleDownDuration has been exceeded.
+	// +optional
+	FailOpen bool `json:"failOpen,omitempty"`
+	// ScaleHistory are the most recent scale flows which have scaled at least one dependent resource or have failed, latest first.
+	// +optional
+	ScaleHistory []ScaleFlowRecord `json:"scaleHistory,omitempty"`
+	// Configuration is the configuration of the prober in effect.
+	Configuration ProbeConfiguration `json:"configuration"`
+	// LastUpdateTime is the time at which the status has last been updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// ProbeResult is the result of a single probe.
+type ProbeResult struct {
+	// Health is the health of the probe.
+	Health ProbeHealth `json:"health"`
+	// LastTransitionTime is the time at which Health last changed.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// SuccessCount is the number of consecutive successful attempts of the probe, capped at successThreshold.
+	SuccessCount int `json:"successCount"`
+	// ErrorCount is the number of consecutive failed attempts of the probe, capped at failureThreshold.
+	ErrorCount int `json:"errorCount"`
+	// LastError is the error with which the probe has last failed. It is cleared once the probe has succeeded.
+	// +optional
+	LastError *ProbeError `json:"lastError,omitempty"`
+}
+
+// ExternalTargetResult is the result of the external probe for one of its targets.
+type ExternalTargetResult struct {
+	// Name is the name of the target.
+	Name        string `json:"name"`
+	ProbeResult `json:",inline"`
+}
+
+// ProbeError is an error with which a probe has failed.
+type ProbeError struct {
+	// Class classifies the error.
+	Class ProbeErrorClass `json:"class"`
+	// Message is the message of the error.
+	Message string `json:"message"`
+	// Time is the time at which the probe has failed with the error.
+	Time metav1.Time `json:"time"`
+}
+
+// ScaleFlowRecord is the record of a scale flow run.
+type ScaleFlowRecord struct {
+	// Operation is the operation of the scale flow, either scale-up, scale-down or rollback.
+	Operation string `json:"operation"`
+	// StartTime is the time at which the scale flow has started.
+	StartTime metav1.Time `json:"startTime"`
+	// Duration is the time taken by the scale flow.
+	Duration metav1.Duration `json:"duration"`
+	// Error is the error with which the scale flow has failed.
+	// +optional
+	Error string `json:"error,omitempty"`
+	// Resources are the outcomes of the scale flow for each dependent resource.
+	// +optional
+	Resources []ScaleResourceRecord `json:"resources,omitempty"`
+}
+
+// ScaleResourceRecord is the outcome of a scale flow run for a single dependent resource.
+type ScaleResourceRecord struct {
+	// Resource is the dependent resource formatted as kind/name.
+	Resource string `json:"resource"`
+	// Outcome is the outcome of scaling the resource, e.g. Scaled, Failed or one of the reasons it has been skipped.
+	Outcome string `json:"outcome"`
+	// ReplicasBefore are the replicas of the resource prior to scaling.
+	ReplicasBefore int32 `json:"replicasBefore"`
+	// ReplicasAfter are the replicas of the resource after scaling.
+	ReplicasAfter int32 `json:"replicasAfter"`
+	// Error is the error with which scaling the resource has failed.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ProbeConfiguration is the configuration of the prober in effect.
+type ProbeConfiguration struct {
+	// ProbeInterval is the interval with which the probe is currently run. It deviates from the configured probe interval
+	// if the adaptive probe interval is enabled.
+	ProbeInterval metav1.Duration `json:"probeInterval"`
+	// SuccessThreshold is the number of consecutive successful attempts for a probe to be considered healthy.
+	SuccessThreshold int `json:"successThreshold"`
+	// FailureThreshold is the number of consecutive failed attempts for a probe to be considered unhealthy.
+	FailureThreshold int `json:"failureThreshold"`
+	// ExternalProbePolicy is the policy with which the health of the external probe is computed from the health of its targets.
+	ExternalProbePolicy string `json:"externalProbePolicy"`
+	// ScaleDownFailurePolicy is the compensation policy which is applied when a scale-down flow fails.
+	ScaleDownFailurePolicy string `json:"scaleDownFailurePolicy"`
+	// MaxScaleDownDuration is the maximum duration for which dependent resources are kept scaled down.
+	// +optional
+	MaxScaleDownDuration *metav1.Duration `json:"maxScaleDownDuration,omitempty"`
+	// DependentResources are the dependent resources, formatted as kind/name, which are scaled by the prober.
+	// +optional
+	DependentResources []string `json:"dependentResources,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProbeStatusList contains a list of ProbeStatus
+type ProbeStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProbeStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProbeStatus{}, &ProbeStatusList{})
+}
@@ -15,9 +15,20 @@
 package weeder
 
 import (
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// IgnoreWeedingAnnotationKey is the key of an annotation which, if set to true on a dependant pod, excludes the pod from
+	// weeding. If set to true on a namespace, no weeders are started for any service in the namespace.
+	IgnoreWeedingAnnotationKey = "dependency-watchdog.gardener.cloud/ignore-weeding"
+	// WeedingServicesAnnotationKey is the key of an annotation on a namespace whose value is a comma separated list of
+	// service names. If set, weeders are only started for these services in the namespace.
+	WeedingServicesAnnotationKey = "dependency-watchdog.gardener.cloud/weeding-services"
+)
+
 // Config provides typed access weeder configuration
 type Config struct {
 	// WatchDuration is the duration for which all dependent pods for a service under surveillance will be watched after the service has recovered.
@@ -25,6 +36,45 @@ type Config struct {
 	WatchDuration *metav1.Duration `json:"watchDuration,omitempty"`
 	// ServicesAndDependantSelectors is a map whose key is the service name and the value is a DependantSelectors
 	ServicesAndDependantSelectors map[string]DependantSelectors `json:"servicesAndDependantSelectors"`
+	// Notifier captures the configuration of the webhooks which are notified once a weeder has deleted a pod.
+	// If not specified, no webhooks are notified.
+	Notifier *napi.Config `json:"notifier,omitempty"`
+	// Audit captures the configuration of the audit log to which every pod deleted by a weeder is appended.
+	// If not specified, no audit log is written.
+	Audit *aapi.Config `json:"audit,omitempty"`
+	// UseEndpointSlices switches the endpoint controller from watching the deprecated v1.Endpoints to watching the
+	// discovery.k8s.io/v1 EndpointSlices of the services. The readiness of a service is then aggregated across all its
+	// EndpointSlices. Defaults to false.
+	UseEndpointSlices *bool `json:"useEndpointSlices,omitempty"`
+	// PodDeletion configures how a weeder removes the dependant pods which are in CrashLoopBackOff.
+	// If not specified, pods are deleted directly.
+	PodDeletion *PodDeletion `json:"podDeletion,omitempty"`
+}
+
+// PodDeletionMode defines how a weeder removes a pod.
+type PodDeletionMode string
+
+const (
+	// PodDeletionModeDelete deletes the pod directly, which ignores any PodDisruptionBudget.
+	PodDeletionModeDelete PodDeletionMode = "Delete"
+	// PodDeletionModeEvict evicts the pod via the policy/v1 Eviction subresource, which respects PodDisruptionBudgets.
+	// An eviction which is rejected with 429 (Too Many Requests) is retried until it succeeds or the weeder exits.
+	PodDeletionModeEvict PodDeletionMode = "Evict"
+)
+
+// PodDeletion configures how a weeder removes the dependant pods which are in CrashLoopBackOff.
+type PodDeletion struct {
+	// Mode is the mode with which pods are removed.
+	// If this field is not specified, then PodDeletionModeDelete will be assumed.
+	Mode *PodDeletionMode `json:"mode,omitempty"`
+	// EvictionRetryInterval is the interval with which an eviction that has been rejected with 429 is retried.
+	// It is only used if Mode is PodDeletionModeEvict. If this field is not specified, then it defaults to 5s.
+	EvictionRetryInterval *metav1.Duration `json:"evictionRetryInterval,omitempty"`
+	// MaxConcurrentPerOwner is the maximum number of pods of the same owner workload, e.g. a ReplicaSet, which a weeder
+	// removes at the same time. A removed pod counts against the limit until it is gone. Further pods of the owner are
+	// only removed once the number of removed pods has dropped below the limit.
+	// If this field is not specified, then the number of pods removed at the same time is not limited.
+	MaxConcurrentPerOwner *int `json:"maxConcurrentPerOwner,omitempty"`
 }
 
 // DependantSelectors encapsulates LabelSelector's used to identify dependants for a service.
@@ -32,4 +82,68 @@ type Config struct {
 type DependantSelectors struct {
 	// PodSelectors is a slice of LabelSelector's used to identify dependant pods
 	PodSelectors []*metav1.LabelSelector `json:"podSelectors"`
+	// TriggerConditions are the conditions of a dependant pod which trigger its deletion. A pod is deleted if it meets any of them.
+	// If not specified, a pod is deleted if any of its containers is waiting with reason CrashLoopBackOff.
+	TriggerConditions *TriggerConditions `json:"triggerConditions,omitempty"`
+	// Strategy is the strategy with which dependant pods which meet a trigger condition are weeded out.
+	// If not specified, then WeedingStrategyDeletePod will be assumed.
+	Strategy *WeedingStrategy `json:"strategy,omitempty"`
+	// WatchDuration overrides the WatchDuration of the Config for the weeders of this service.
+	// If not specified, the WatchDuration of the Config is used.
+	WatchDuration *metav1.Duration `json:"watchDuration,omitempty"`
+	// Limits limit the number of pods which are deleted by a weeder of this service. They stop endless deletion loops
+	// of dependants which are broken for reasons unrelated to the service. If not specified, deletions are not limited.
+	Limits *WeedingLimits `json:"limits,omitempty"`
+	// AddressChangeTrigger opts the service in to also starting a weeder when the set of its ready addresses changes
+	// while it stays ready, e.g. because its pods have been replaced and dependants still use the old addresses.
+	// If not specified, weeders are only started when the service turns ready.
+	AddressChangeTrigger *AddressChangeTrigger `json:"addressChangeTrigger,omitempty"`
+}
+
+// AddressChangeTrigger configures how changes to the ready addresses of a service start a weeder.
+type AddressChangeTrigger struct {
+	// DebounceWindow is the duration for which the ready addresses have to stay unchanged after they have changed before
+	// a weeder is started, so that a rolling update of the service starts a single weeder once it has completed.
+	// If this field is not specified, then it defaults to 30s.
+	DebounceWindow *metav1.Duration `json:"debounceWindow,omitempty"`
+}
+
+// WeedingLimits limit the number of pods which are deleted by a weeder. Limits which are not specified are not applied.
+// They do not apply to rollout restarts, as every workload is restarted at most once per weeder anyway.
+type WeedingLimits struct {
+	// MaxDeletionsPerPod is the maximum number of times a pod with the same name is deleted, e.g. a pod of a StatefulSet.
+	MaxDeletionsPerPod *int `json:"maxDeletionsPerPod,omitempty"`
+	// MaxDeletionsPerSelector is the maximum number of pods which are deleted for each of the PodSelectors.
+	MaxDeletionsPerSelector *int `json:"maxDeletionsPerSelector,omitempty"`
+	// MaxDeletionsPerWeeder is the maximum number of pods which are deleted by a weeder in total.
+	MaxDeletionsPerWeeder *int `json:"maxDeletionsPerWeeder,omitempty"`
+}
+
+// WeedingStrategy defines how dependant pods which meet a trigger condition are weeded out.
+type WeedingStrategy string
+
+const (
+	// WeedingStrategyDeletePod removes the pod itself as configured by PodDeletion.
+	WeedingStrategyDeletePod WeedingStrategy = "DeletePod"
+	// WeedingStrategyRolloutRestart triggers a rollout restart of the workload owning the pod, i.e. the Deployment owning
+	// its ReplicaSet or its StatefulSet. Each workload is restarted at most once per weeder.
+	WeedingStrategyRolloutRestart WeedingStrategy = "RolloutRestart"
+)
+
+// TriggerConditions are the conditions of a dependant pod which trigger its deletion. Conditions which are not specified are not checked.
+type TriggerConditions struct {
+	// WaitingReasons triggers the deletion of a pod if any of its containers is waiting with one of these reasons,
+	// e.g. CrashLoopBackOff or Error.
+	WaitingReasons []string `json:"waitingReasons,omitempty"`
+	// RestartCountAbove triggers the deletion of a pod if any of its containers has restarted more often than this number
+	// since the service has become ready.
+	RestartCountAbove *int32 `json:"restartCountAbove,omitempty"`
+	// NotReadyFor triggers the deletion of a pod if it has not been ready for longer than this duration.
+	NotReadyFor *metav1.Duration `json:"notReadyFor,omitempty"`
+	// LastTerminationExitCodes triggers the deletion of a pod if the last termination of any of its containers, which is
+	// not ready or is waiting, has ended with one of these exit codes.
+	LastTerminationExitCodes []int32 `json:"lastTerminationExitCodes,omitempty"`
+	// IncludeInitContainers determines if WaitingReasons, RestartCountAbove and LastTerminationExitCodes also check the init
+	// containers of a pod. If not specified its default value will be false.
+	IncludeInitContainers *bool `json:"includeInitContainers,omitempty"`
 }
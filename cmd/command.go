@@ -18,6 +18,10 @@ import (
 	"flag"
 	"time"
 
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/go-logr/logr"
@@ -123,3 +127,29 @@ func bindLeaderElectionFlags(fs *flag.FlagSet, opts *SharedOpts) {
 	fs.DurationVar(&opts.LeaderElection.RetryPeriod, "leader-elect-retry-period", defaultRetryPeriod, "The duration the clients should wait between attempting acquisition and renewal "+
 		"of a leadership. This is only applicable if leader election is enabled.")
 }
+
+// setupNotifier creates a notifier for the given configuration and registers it with the manager so that notifications are
+// only delivered while the manager runs. It returns nil if no notifier has been configured.
+func setupNotifier(mgr manager.Manager, config *napi.Config, logger logr.Logger) (notifier.Notifier, error) {
+	if config == nil {
+		return nil, nil
+	}
+	webhookNotifier, err := notifier.NewWebhookNotifier(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err = mgr.Add(webhookNotifier); err != nil {
+		return nil, err
+	}
+	return webhookNotifier, nil
+}
+
+// setupAuditSink creates the audit log for the given configuration. actor identifies the DWD component in the audit records.
+// It returns nil if no audit log has been configured.
+func setupAuditSink(mgr manager.Manager, config *aapi.Config, actor string) (audit.Sink, error) {
+	if config == nil {
+		return nil, nil
+	}
+	// the API reader is used so that ConfigMaps are not cached cluster-wide.
+	return audit.NewSink(config, mgr.GetAPIReader(), mgr.GetClient(), actor)
+}
@@ -18,6 +18,7 @@ import (
 	"flag"
 	"fmt"
 
+	dwdv1alpha1 "github.com/gardener/dependency-watchdog/api/v1alpha1"
 	"github.com/gardener/dependency-watchdog/controllers/cluster"
 	"github.com/gardener/dependency-watchdog/internal/prober"
 	"github.com/gardener/dependency-watchdog/internal/util"
@@ -34,6 +35,8 @@ import (
 const (
 	proberLeaderElectionID = "dwd-prober-leader-election"
 	weederLeaderElectionID = "dwd-weeder-leader-election"
+	proberEventSource      = "dependency-watchdog-prober"
+	weederEventSource      = "dependency-watchdog-weeder"
 )
 
 var (
@@ -85,6 +88,7 @@ func init() {
 	localSchemeBuilder := runtime.NewSchemeBuilder(
 		clientgoscheme.AddToScheme,
 		extensionsv1alpha1.AddToScheme,
+		dwdv1alpha1.AddToScheme,
 	)
 	utilruntime.Must(localSchemeBuilder.AddToScheme(scheme))
 }
@@ -126,15 +130,43 @@ func startClusterControllerMgr(logger logr.Logger) (manager.Manager, error) {
 		return nil, fmt.Errorf("failed to create clientSet for scalesGetter %w", err)
 	}
 
+	proberNotifier, err := setupNotifier(mgr, proberConfig.Notifier, proberLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the notifier of the prober controller manager %w", err)
+	}
+
+	proberAuditSink, err := setupAuditSink(mgr, proberConfig.Audit, "prober")
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the audit log of the prober controller manager %w", err)
+	}
+
+	proberMgr := prober.NewManager()
 	if err := (&cluster.Reconciler{
 		Client:                  mgr.GetClient(),
 		Scheme:                  mgr.GetScheme(),
 		ScaleGetter:             scalesGetter,
-		ProberMgr:               prober.NewManager(),
+		ProberMgr:               proberMgr,
 		ProbeConfig:             proberConfig,
+		EventRecorder:           mgr.GetEventRecorderFor(proberEventSource),
+		Notifier:                proberNotifier,
+		AuditSink:               proberAuditSink,
 		MaxConcurrentReconciles: proberOpts.ConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		return nil, fmt.Errorf("failed to register cluster reconciler with the prober controller manager %w", err)
 	}
+
+	if *proberConfig.ScaledDownResourceRestoration.Enabled {
+		if err := mgr.Add(&cluster.Restorer{
+			Client:      mgr.GetClient(),
+			APIReader:   mgr.GetAPIReader(),
+			ScaleGetter: scalesGetter,
+			ProberMgr:   proberMgr,
+			ProbeConfig: proberConfig,
+			AuditSink:   proberAuditSink,
+			Logger:      logger.WithName("restorer"),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to register restorer with the prober controller manager %w", err)
+		}
+	}
 	return mgr, nil
 }
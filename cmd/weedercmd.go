@@ -15,14 +15,16 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
 
 	"github.com/gardener/dependency-watchdog/controllers/endpoint"
-	internalutils "github.com/gardener/dependency-watchdog/internal/util"
 	"github.com/gardener/dependency-watchdog/internal/weeder"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/go-logr/logr"
@@ -99,22 +101,43 @@ func startEndpointsControllerMgr(logger logr.Logger) (manager.Manager, error) {
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaderElectionID:           weederLeaderElectionID,
 		Logger:                     weederLogger,
+		// pods of the whole seed are cached for the shared pod informer, so they are stripped down to what weeders read
+		NewCache: cache.BuilderWithOptions(cache.Options{
+			TransformByObject: cache.TransformByObject{&corev1.Pod{}: weeder.StripPod},
+		}),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to start the weeder controller manager %w", err)
 	}
 
-	// create clientSet
-	clientSet, err := internalutils.CreateClientSetFromRestConfig(restConf)
+	// all weeders share a single pod informer of the manager cache instead of opening their own watches
+	podInformer, err := mgr.GetCache().GetInformer(context.Background(), &corev1.Pod{})
 	if err != nil {
-		return nil, fmt.Errorf("failed creating clientset for dwd-weeder %w", err)
+		return nil, fmt.Errorf("failed to get the pod informer for dwd-weeder %w", err)
+	}
+	podEventSource, err := weeder.NewPodEventSource(podInformer, weederLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the pod event source for dwd-weeder %w", err)
+	}
+
+	weederNotifier, err := setupNotifier(mgr, weederConfig.Notifier, weederLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the notifier of the weeder controller manager %w", err)
+	}
+
+	weederAuditSink, err := setupAuditSink(mgr, weederConfig.Audit, "weeder")
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the audit log of the weeder controller manager %w", err)
 	}
 
 	if err := (&endpoint.Reconciler{
-		Client:       mgr.GetClient(),
-		SeedClient:   clientSet,
-		WeederConfig: weederConfig,
-		WeederMgr:    weeder.NewManager(),
+		Client:         mgr.GetClient(),
+		PodEventSource: podEventSource,
+		WeederConfig:   weederConfig,
+		WeederMgr:      weeder.NewManager(),
+		Notifier:       weederNotifier,
+		AuditSink:      weederAuditSink,
+		EventRecorder:  mgr.GetEventRecorderFor(weederEventSource),
 	}).SetupWithManager(mgr); err != nil {
 		return nil, fmt.Errorf("failed to register endpoint reconciler with weeder controller manager %w", err)
 	}
@@ -21,6 +21,9 @@ import (
 	"fmt"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdv1alpha1 "github.com/gardener/dependency-watchdog/api/v1alpha1"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -34,9 +37,11 @@ import (
 	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/scale"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -47,15 +52,23 @@ const controllerName = "cluster"
 // Reconciler reconciles a Cluster object
 type Reconciler struct {
 	client.Client
-	Scheme                  *runtime.Scheme
-	ProberMgr               prober.Manager
-	ScaleGetter             scale.ScalesGetter
-	ProbeConfig             *papi.Config
+	Scheme        *runtime.Scheme
+	ProberMgr     prober.Manager
+	ScaleGetter   scale.ScalesGetter
+	ProbeConfig   *papi.Config
+	EventRecorder record.EventRecorder
+	// Notifier notifies webhooks about transitions observed by the probers. It is nil if no webhooks have been configured.
+	Notifier notifier.Notifier
+	// AuditSink is the audit log to which every scaling of a dependent resource is appended. It is nil if no audit log has been configured.
+	AuditSink               audit.Sink
 	MaxConcurrentReconciles int
 }
 
-//+kubebuilder:rbac:groups=gardener.cloud,resources=clusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gardener.cloud,resources=clusters,verbs=get;list;watch;patch
 //+kubebuilder:rbac:groups=gardener.cloud,resources=clusters/status,verbs=get
+//+kubebuilder:rbac:resources=configmaps,verbs=get;create;update
+//+kubebuilder:rbac:groups=dependency-watchdog.gardener.cloud,resources=probestatuses,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=dependency-watchdog.gardener.cloud,resources=probestatuses/status,verbs=get;update
 
 // Reconcile listens to create/update/delete events for `Cluster` resources and
 // manages probes for the shoot control namespace for these clusters by looking at the cluster state.
@@ -83,7 +96,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if r.ProberMgr.Unregister(req.Name) {
 			log.Info("Cluster has been marked for deletion, existing prober has been removed")
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.removeProberState(ctx, cluster)
 	}
 
 	// if hibernation is enabled then we will remove any existing prober. Any resource scaling that is required in case of hibernation will now be handled as part of worker reconciliation in extension controllers.
@@ -91,7 +104,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if r.ProberMgr.Unregister(req.Name) {
 			log.Info("Cluster hibernation is enabled, existing prober has been removed")
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.removeProberState(ctx, cluster)
 	}
 
 	// if control plane migration has started for a shoot, then any existing probe should be removed as it is no longer needed.
@@ -99,7 +112,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if r.ProberMgr.Unregister(req.Name) {
 			log.Info("Cluster migration is enabled, existing prober has been removed")
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.removeProberState(ctx, cluster)
 	}
 
 	// if a shoot is created without any workers (this can only happen for control-plane-as-a-service use case), then if there is a probe registered then
@@ -110,7 +123,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		} else {
 			log.Info("Cluster does not have any workers. No probe will be created")
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.removeProberState(ctx, cluster)
 	}
 
 	if canStartProber(shoot) {
@@ -131,6 +144,24 @@ func (r *Reconciler) getCluster(ctx context.Context, namespace string, name stri
 	return cluster, false, nil
 }
 
+// removeProberState removes the verdict published by a prober from the cluster and deletes its ProbeStatus, as they would
+// otherwise go stale once the prober has been removed.
+func (r *Reconciler) removeProberState(ctx context.Context, cluster *extensionsv1alpha1.Cluster) error {
+	if _, ok := cluster.Annotations[papi.VerdictAnnotationKey]; ok {
+		patchBytes := []byte(fmt.Sprintf("{\"metadata\":{\"annotations\":{\"%s\":null}}}", papi.VerdictAnnotationKey))
+		if err := r.Patch(ctx, cluster, client.RawPatch(types.MergePatchType, patchBytes)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to remove prober verdict from cluster: %w", err)
+		}
+	}
+	if r.ProbeConfig != nil && r.ProbeConfig.ProbeStatusResource != nil && *r.ProbeConfig.ProbeStatusResource.Enabled {
+		probeStatus := &dwdv1alpha1.ProbeStatus{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Name, Name: dwdv1alpha1.ProbeStatusName}}
+		if err := r.Delete(ctx, probeStatus); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to delete probe status: %w", err)
+		}
+	}
+	return nil
+}
+
 // canStartProber checks if a probe can be registered and started.
 // shoot.Status.LastOperation.Type provides an insight into the current state of the cluster. It is important to identify the following cases:
 // 1. Cluster has been created successfully => This will ensure that the current state of shoot Kube API Server can be acted upon to decide on scaling operations. If the cluster
@@ -155,9 +186,9 @@ func canStartProber(shoot *v1beta1.Shoot) bool {
 func (r *Reconciler) startProber(ctx context.Context, logger logr.Logger, key string) {
 	_, ok := r.ProberMgr.GetProber(key)
 	if !ok {
-		deploymentScaler := scaler.NewScaler(key, r.ProbeConfig, r.Client, r.ScaleGetter, logger)
+		deploymentScaler := scaler.NewScaler(key, r.ProbeConfig, r.Client, r.ScaleGetter, r.AuditSink, logger)
 		shootClientCreator := prober.NewShootClientCreator(r.Client)
-		p := prober.NewProber(ctx, key, r.ProbeConfig, r.Client, deploymentScaler, shootClientCreator, logger)
+		p := prober.NewProber(ctx, key, r.ProbeConfig, r.Client, deploymentScaler, shootClientCreator, r.EventRecorder, r.Notifier, logger)
 		r.ProberMgr.Register(*p)
 		logger.Info("Starting a new prober")
 		go p.Run()
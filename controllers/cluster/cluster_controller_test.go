@@ -27,20 +27,25 @@ import (
 
 	"k8s.io/utils/pointer"
 
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdv1alpha1 "github.com/gardener/dependency-watchdog/api/v1alpha1"
 	proberpackage "github.com/gardener/dependency-watchdog/internal/prober"
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
 	"github.com/gardener/dependency-watchdog/internal/util"
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	gardenerv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -81,6 +86,7 @@ func setupProberEnv(ctx context.Context, g *WithT) (client.Client, *envtest.Envi
 		ScaleGetter:             scalesGetter,
 		ProberMgr:               proberpackage.NewManager(),
 		ProbeConfig:             proberConfig,
+		EventRecorder:           &record.FakeRecorder{},
 		MaxConcurrentReconciles: maxConcurrentReconcilesProber,
 	}
 	err = clusterReconciler.SetupWithManager(mgr)
@@ -359,6 +365,25 @@ func proberShouldNotBePresent(g *WithT, reconciler *Reconciler, cluster *gardene
 	g.Expect(prober).To(Equal(proberpackage.Prober{}))
 }
 
+func TestProberStateIsRemovedWhenProberIsRemoved(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	cluster := createTestCluster(g, func(shoot *gardencorev1beta1.Shoot) {
+		shoot.Spec.Hibernation.Enabled = pointer.Bool(true)
+	})
+	cluster.SetAnnotations(map[string]string{papi.VerdictAnnotationKey: "{}"})
+	probeStatus := &dwdv1alpha1.ProbeStatus{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.GetName(), Name: dwdv1alpha1.ProbeStatusName}}
+	crClient := fake.NewClientBuilder().WithScheme(buildScheme()).WithObjects(cluster, probeStatus).Build()
+	probeConfig := &papi.Config{ProbeStatusResource: &papi.ProbeStatusResource{Enabled: pointer.Bool(true)}}
+	reconciler := &Reconciler{Client: crClient, ProberMgr: proberpackage.NewManager(), ProbeConfig: probeConfig}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cluster)})
+	g.Expect(err).To(BeNil())
+	g.Expect(crClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+	g.Expect(cluster.GetAnnotations()).ToNot(HaveKey(papi.VerdictAnnotationKey))
+	g.Expect(apierrors.IsNotFound(crClient.Get(ctx, client.ObjectKeyFromObject(probeStatus), probeStatus))).To(BeTrue())
+}
+
 func validateIfFileExists(file string, g *WithT) {
 	var err error
 	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
@@ -372,6 +397,7 @@ func buildScheme() *runtime.Scheme {
 	localSchemeBuilder := runtime.NewSchemeBuilder(
 		clientgoscheme.AddToScheme,
 		gardenerv1alpha1.AddToScheme,
+		dwdv1alpha1.AddToScheme,
 	)
 	utilruntime.Must(localSchemeBuilder.AddToScheme(scheme))
 	return scheme
@@ -0,0 +1,192 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/prober"
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/scale"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restoreAction is the action taken by the Restorer for a shoot control namespace.
+type restoreAction uint8
+
+const (
+	// restoreActionNone indicates that the resources in the namespace are left as they are.
+	restoreActionNone restoreAction = iota
+	// restoreActionRemoveStaleAnnotations indicates that only stale replicas annotations are removed.
+	restoreActionRemoveStaleAnnotations
+	// restoreActionRestore indicates that resources which have been left scaled down are scaled up and stale replicas annotations are removed.
+	restoreActionRestore
+)
+
+// Restorer periodically restores dependent resources which carry the replicas annotation set by DWD during scale-down,
+// but whose shoot either has no active prober or has a healthy prober. A shoot control namespace is only acted upon once
+// it has been continuously found eligible for at least the configured grace period. This gives the Reconciler enough time
+// to start probers, e.g. after a restart of DWD.
+type Restorer struct {
+	client.Client
+	// APIReader reads dependent resources directly from the API server, so that no informer is started for all resources
+	// of their kinds in the seed just to find the few which are dependent resources.
+	APIReader   client.Reader
+	ScaleGetter scale.ScalesGetter
+	ProberMgr   prober.Manager
+	ProbeConfig *papi.Config
+	// AuditSink is the audit log to which restored resources are appended. It is nil if no audit log has been configured.
+	AuditSink audit.Sink
+	Logger    logr.Logger
+	// eligibleSince captures the time since when a shoot control namespace has been continuously found eligible for restoration.
+	eligibleSince map[string]time.Time
+}
+
+//+kubebuilder:rbac:groups=gardener.cloud,resources=clusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;patch
+
+// Start runs the Restorer till the context is cancelled. It implements manager.Runnable.
+func (r *Restorer) Start(ctx context.Context) error {
+	r.eligibleSince = make(map[string]time.Time)
+	wait.UntilWithContext(ctx, r.restore, r.ProbeConfig.ScaledDownResourceRestoration.Interval.Duration)
+	return nil
+}
+
+// NeedLeaderElection ensures that only the leader restores resources. It implements manager.LeaderElectionRunnable.
+func (r *Restorer) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *Restorer) restore(ctx context.Context) {
+	namespaces, err := r.getNamespacesWithReplicasAnnotation(ctx)
+	if err != nil {
+		r.Logger.Error(err, "Failed to get namespaces with resources carrying the replicas annotation, will be re-attempted")
+		return
+	}
+	for namespace := range r.eligibleSince {
+		if !namespaces.Has(namespace) {
+			delete(r.eligibleSince, namespace)
+		}
+	}
+	gracePeriod := r.ProbeConfig.ScaledDownResourceRestoration.GracePeriod.Duration
+	for _, namespace := range sets.List(namespaces) {
+		logger := r.Logger.WithValues("shootNamespace", namespace)
+		action, err := r.determineRestoreAction(ctx, namespace)
+		if err != nil {
+			logger.Error(err, "Failed to determine if resources need to be restored, will be re-attempted")
+			continue
+		}
+		if action == restoreActionNone {
+			delete(r.eligibleSince, namespace)
+			continue
+		}
+		since, ok := r.eligibleSince[namespace]
+		if !ok {
+			r.eligibleSince[namespace] = time.Now()
+			continue
+		}
+		if time.Since(since) < gracePeriod {
+			continue
+		}
+		r.restoreNamespace(ctx, logger, namespace, action)
+		delete(r.eligibleSince, namespace)
+	}
+}
+
+func (r *Restorer) restoreNamespace(ctx context.Context, logger logr.Logger, namespace string, action restoreAction) {
+	if action == restoreActionRestore {
+		result := scaler.NewScaler(namespace, r.ProbeConfig, r.Client, r.ScaleGetter, r.AuditSink, logger).ScaleUp(scaler.ContextWithoutScaleConditions(ctx))
+		if result.Err != nil {
+			logger.Error(result.Err, "Failed to restore resources which have been left scaled down, will be re-attempted", "resourceResults", result.ResourceResults)
+			return
+		}
+		if scaledResources := result.ScaledResources(); len(scaledResources) > 0 {
+			logger.Info("Restored resources which have been left scaled down", "scaledResources", scaledResources)
+		}
+	}
+	cleanedResNames, err := scaler.RemoveStaleReplicasAnnotations(ctx, namespace, r.ProbeConfig, r.Client, r.ScaleGetter, logger)
+	if err != nil {
+		logger.Error(err, "Failed to remove stale replicas annotations, will be re-attempted", "cleanedResources", cleanedResNames)
+	}
+}
+
+// determineRestoreAction determines the action to take for the resources in the given shoot control namespace.
+// Resources are restored only if there is no active prober and a prober could be started for the shoot, i.e. it is neither being
+// deleted, hibernated nor migrated, has workers and has been created or restored successfully.
+// Stale annotations are removed if there is an active prober which is healthy, as the prober itself takes care of scaling up resources.
+func (r *Restorer) determineRestoreAction(ctx context.Context, namespace string) (restoreAction, error) {
+	if p, ok := r.ProberMgr.GetProber(namespace); ok && !p.IsClosed() {
+		if p.IsHealthy() {
+			return restoreActionRemoveStaleAnnotations, nil
+		}
+		return restoreActionNone, nil
+	}
+	cluster := &extensionsv1alpha1.Cluster{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, cluster); err != nil {
+		if errors.IsNotFound(err) {
+			return restoreActionNone, nil
+		}
+		return restoreActionNone, err
+	}
+	shoot, err := extensionscontroller.ShootFromCluster(cluster)
+	if err != nil {
+		return restoreActionNone, err
+	}
+	if shoot.DeletionTimestamp != nil || v1beta1helper.HibernationIsEnabled(shoot) || len(shoot.Spec.Provider.Workers) == 0 || !canStartProber(shoot) {
+		return restoreActionNone, nil
+	}
+	return restoreActionRestore, nil
+}
+
+// getNamespacesWithReplicasAnnotation gets all the shoot control namespaces which have at least one dependent resource
+// carrying the replicas annotation. The shoot control namespaces are taken from the Cluster resources, and only the dependent
+// resources are read from each of them.
+func (r *Restorer) getNamespacesWithReplicasAnnotation(ctx context.Context) (sets.Set[string], error) {
+	clusters := &extensionsv1alpha1.ClusterList{}
+	if err := r.List(ctx, clusters); err != nil {
+		return nil, err
+	}
+	namespaces := sets.New[string]()
+	for _, cluster := range clusters.Items {
+		for _, resInfo := range r.ProbeConfig.DependentResourceInfos {
+			objMeta := &metav1.PartialObjectMetadata{}
+			objMeta.SetGroupVersionKind(schema.FromAPIVersionAndKind(resInfo.Ref.APIVersion, resInfo.Ref.Kind))
+			if err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: cluster.Name, Name: resInfo.Ref.Name}, objMeta); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if _, ok := objMeta.Annotations[scaler.ReplicasAnnotationKey]; ok {
+				namespaces.Insert(cluster.Name)
+				break
+			}
+		}
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/prober"
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/test"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const restorerTestNamespace = "shoot--test"
+
+func TestDetermineRestoreAction(t *testing.T) {
+	tests := []struct {
+		title          string
+		mutateShoot    func(shoot *gardencorev1beta1.Shoot)
+		createCluster  bool
+		registerProber bool
+		expectedAction restoreAction
+	}{
+		{"test: no prober and cluster not found", nil, false, false, restoreActionNone},
+		{"test: no prober and shoot is active", nil, true, false, restoreActionRestore},
+		{"test: no prober and shoot is hibernated", func(shoot *gardencorev1beta1.Shoot) { shoot.Status.IsHibernated = true }, true, false, restoreActionNone},
+		{"test: no prober and shoot is being deleted", func(shoot *gardencorev1beta1.Shoot) { now := metav1.Now(); shoot.DeletionTimestamp = &now }, true, false, restoreActionNone},
+		{"test: no prober and shoot is being migrated", func(shoot *gardencorev1beta1.Shoot) {
+			shoot.Status.LastOperation.Type = gardencorev1beta1.LastOperationTypeMigrate
+		}, true, false, restoreActionNone},
+		{"test: no prober and shoot has no workers", func(shoot *gardencorev1beta1.Shoot) { shoot.Spec.Provider.Workers = nil }, true, false, restoreActionNone},
+		{"test: no prober and shoot has no last operation", func(shoot *gardencorev1beta1.Shoot) { shoot.Status.LastOperation = nil }, true, false, restoreActionNone},
+		{"test: no prober and shoot is being created", func(shoot *gardencorev1beta1.Shoot) {
+			shoot.Status.LastOperation.Type = gardencorev1beta1.LastOperationTypeCreate
+			shoot.Status.LastOperation.State = gardencorev1beta1.LastOperationStateProcessing
+		}, true, false, restoreActionNone},
+		{"test: no prober and shoot is being restored", func(shoot *gardencorev1beta1.Shoot) {
+			shoot.Status.LastOperation.Type = gardencorev1beta1.LastOperationTypeRestore
+			shoot.Status.LastOperation.State = gardencorev1beta1.LastOperationStateProcessing
+		}, true, false, restoreActionNone},
+		{"test: prober which has not yet found the shoot healthy", nil, true, true, restoreActionNone},
+	}
+
+	for _, entry := range tests {
+		t.Run(entry.title, func(t *testing.T) {
+			g := NewWithT(t)
+			clientBuilder := fake.NewClientBuilder().WithScheme(buildScheme())
+			if entry.createCluster {
+				clientBuilder.WithObjects(createTestCluster(g, entry.mutateShoot))
+			}
+			r := createTestRestorer(clientBuilder.Build())
+			if entry.registerProber {
+				r.ProberMgr.Register(*prober.NewProber(context.Background(), restorerTestNamespace, r.ProbeConfig, r.Client, nil, nil, nil, nil, logr.Discard()))
+			}
+			action, err := r.determineRestoreAction(context.Background(), restorerTestNamespace)
+			g.Expect(err).To(BeNil())
+			g.Expect(action).To(Equal(entry.expectedAction))
+		})
+	}
+}
+
+// recordingReader records the keys of the objects it gets and rejects lists, like an API server which only grants get
+// on the dependent resources.
+type recordingReader struct {
+	client.Reader
+	keys []client.ObjectKey
+}
+
+func (r *recordingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.keys = append(r.keys, key)
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func (r *recordingReader) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+	return apierrors.NewForbidden(appsv1.Resource("deployments"), "", nil)
+}
+
+func TestGetNamespacesWithReplicasAnnotation(t *testing.T) {
+	g := NewWithT(t)
+	annotated := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "kube-controller-manager", Namespace: restorerTestNamespace, Annotations: map[string]string{scaler.ReplicasAnnotationKey: "1"}}}
+	notAnnotated := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "kube-controller-manager", Namespace: "shoot--other"}}
+	notDependent := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "shoot--other", Annotations: map[string]string{scaler.ReplicasAnnotationKey: "1"}}}
+	withoutCluster := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "kube-controller-manager", Namespace: "shoot--another", Annotations: map[string]string{scaler.ReplicasAnnotationKey: "1"}}}
+	otherCluster := createTestCluster(g, nil)
+	otherCluster.SetName("shoot--other")
+	crClient := fake.NewClientBuilder().WithScheme(buildScheme()).WithObjects(createTestCluster(g, nil), otherCluster, annotated, notAnnotated, notDependent, withoutCluster).Build()
+	r := createTestRestorer(crClient)
+	reader := &recordingReader{Reader: crClient}
+	r.APIReader = reader
+
+	namespaces, err := r.getNamespacesWithReplicasAnnotation(context.Background())
+	g.Expect(err).To(BeNil())
+	g.Expect(namespaces.UnsortedList()).To(ConsistOf(restorerTestNamespace))
+	g.Expect(reader.keys).To(ConsistOf(
+		client.ObjectKey{Namespace: restorerTestNamespace, Name: "kube-controller-manager"},
+		client.ObjectKey{Namespace: "shoot--other", Name: "kube-controller-manager"},
+	), "only the dependent resources in the shoot control namespaces should be read")
+}
+
+func createTestRestorer(client client.Client) *Restorer {
+	return &Restorer{
+		Client:    client,
+		APIReader: client,
+		ProberMgr: prober.NewManager(),
+		ProbeConfig: &papi.Config{
+			DependentResourceInfos: []papi.DependentResourceInfo{
+				{Ref: &autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}},
+			},
+		},
+		Logger: logr.Discard(),
+	}
+}
+
+func createTestCluster(g *WithT, mutateShoot func(shoot *gardencorev1beta1.Shoot)) client.Object {
+	cluster, shoot, err := test.CreateClusterResource(1, false)
+	g.Expect(err).To(BeNil())
+	if mutateShoot != nil {
+		mutateShoot(shoot)
+	}
+	cluster.Spec.Shoot.Object = nil
+	cluster.Spec.Shoot.Raw, err = json.Marshal(shoot)
+	g.Expect(err).To(BeNil())
+	cluster.Name = restorerTestNamespace
+	return cluster
+}
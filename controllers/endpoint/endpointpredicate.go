@@ -19,50 +19,45 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
-// ReadyEndpoints is a predicate to allow events for only ready endpoints. Endpoint is considered ready
+// ReadyEndpoints is a predicate to allow events which change the readiness of endpoints, i.e. the creation of ready
+// endpoints, transitions between ready and not ready and the deletion of ready endpoints. Endpoint is considered ready
 // when there is at least a single endpoint subset that has at least one IP address assigned.
 func ReadyEndpoints(logger logr.Logger) predicate.Predicate {
 	log := logger.WithValues("predicate", "ReadyEndpointsPredicate")
-	isEndpointReady := func(obj runtime.Object) bool {
+	isReady := func(obj runtime.Object) bool {
 		ep, ok := obj.(*v1.Endpoints)
-		if !ok || ep == nil {
-			return false
+		return ok && ep != nil && isEndpointReady(ep)
+	}
+	isReadyOrSkipped := func(obj runtime.Object) bool {
+		if isReady(obj) {
+			return true
 		}
-		for _, subset := range ep.Subsets {
-			if len(subset.Addresses) > 0 {
-				return true
-			}
+		if ep, ok := obj.(*v1.Endpoints); ok && ep != nil {
+			log.Info("Endpoint does not have any IP address. Skipping processing this endpoint", "namespace", ep.Namespace, "endpoint", ep.Name)
 		}
-		log.Info("Endpoint does not have any IP address. Skipping processing this endpoint", "namespace", ep.Namespace, "endpoint", ep.Name)
 		return false
 	}
 
 	return predicate.Funcs{
 		CreateFunc: func(event event.CreateEvent) bool {
-			return isEndpointReady(event.Object)
+			return isReadyOrSkipped(event.Object)
 		},
 
 		UpdateFunc: func(event event.UpdateEvent) bool {
-			isNewReady := isEndpointReady(event.ObjectNew)
-			isOldReady := isEndpointReady(event.ObjectOld)
-
-			if isNewReady && !isOldReady {
-				return true
-			}
-
-			return false
+			return isReady(event.ObjectNew) != isReady(event.ObjectOld)
 		},
 
 		DeleteFunc: func(event event.DeleteEvent) bool {
-			return false
+			return isReady(event.Object)
 		},
 
 		GenericFunc: func(event event.GenericEvent) bool {
-			return isEndpointReady(event.Object)
+			return isReadyOrSkipped(event.Object)
 		},
 	}
 }
@@ -88,7 +83,7 @@ func MatchingEndpoints(epMap map[string]wapi.DependantSelectors) predicate.Predi
 		},
 
 		DeleteFunc: func(event event.DeleteEvent) bool {
-			return false
+			return isMatchingEndpoints(event.Object, epMap)
 		},
 
 		GenericFunc: func(event event.GenericEvent) bool {
@@ -96,3 +91,56 @@ func MatchingEndpoints(epMap map[string]wapi.DependantSelectors) predicate.Predi
 		},
 	}
 }
+
+// ReadyAddressesChanged is a predicate to allow update events of ready endpoints whose ready addresses have changed while
+// they stayed ready, e.g. because the backing pods have been replaced without a gap. Only events for endpoints of services
+// which have opted in to address change triggers are allowed.
+func ReadyAddressesChanged(epMap map[string]wapi.DependantSelectors) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event event.CreateEvent) bool {
+			return false
+		},
+
+		UpdateFunc: func(event event.UpdateEvent) bool {
+			oldEp, ok := event.ObjectOld.(*v1.Endpoints)
+			if !ok || oldEp == nil {
+				return false
+			}
+			newEp, ok := event.ObjectNew.(*v1.Endpoints)
+			if !ok || newEp == nil || epMap[newEp.Name].AddressChangeTrigger == nil {
+				return false
+			}
+			oldAddresses, newAddresses := readyEndpointAddresses(oldEp), readyEndpointAddresses(newEp)
+			return oldAddresses.Len() > 0 && newAddresses.Len() > 0 && !oldAddresses.Equal(newAddresses)
+		},
+
+		DeleteFunc: func(event event.DeleteEvent) bool {
+			return false
+		},
+
+		GenericFunc: func(event event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// isEndpointReady checks if there is at least a single endpoint subset that has at least one IP address assigned.
+func isEndpointReady(ep *v1.Endpoints) bool {
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// readyEndpointAddresses returns the IP addresses of all endpoint subsets which are ready.
+func readyEndpointAddresses(ep *v1.Endpoints) sets.Set[string] {
+	addresses := sets.New[string]()
+	for _, subset := range ep.Subsets {
+		for _, address := range subset.Addresses {
+			addresses.Insert(address.IP)
+		}
+	}
+	return addresses
+}
@@ -17,14 +17,20 @@
 package endpoint
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	v12 "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/weeder"
 	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
@@ -66,7 +72,7 @@ func TestReadyEndpoints(t *testing.T) {
 			ep:                               readyEp,
 			expectedCreateEventFilterOutput:  true,
 			expectedUpdateEventFilterOutput:  true,
-			expectedDeleteEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
 			expectedGenericEventFilterOutput: true,
 		},
 		{
@@ -83,7 +89,7 @@ func TestReadyEndpoints(t *testing.T) {
 			oldEp:                            notReadyEp,
 			expectedCreateEventFilterOutput:  true,
 			expectedUpdateEventFilterOutput:  true,
-			expectedDeleteEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
 			expectedGenericEventFilterOutput: true,
 		},
 		{
@@ -92,14 +98,23 @@ func TestReadyEndpoints(t *testing.T) {
 			oldEp:                            readyEp,
 			expectedCreateEventFilterOutput:  true,
 			expectedUpdateEventFilterOutput:  false,
-			expectedDeleteEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
 			expectedGenericEventFilterOutput: true,
 		},
+		{
+			name:                             "Ready ep -> NotReady ep",
+			ep:                               notReadyEp,
+			oldEp:                            readyEp,
+			expectedCreateEventFilterOutput:  false,
+			expectedUpdateEventFilterOutput:  true,
+			expectedDeleteEventFilterOutput:  false,
+			expectedGenericEventFilterOutput: false,
+		},
 		{
 			name:                             "Ready ep -> no ep",
 			oldEp:                            readyEp,
 			expectedCreateEventFilterOutput:  false,
-			expectedUpdateEventFilterOutput:  false,
+			expectedUpdateEventFilterOutput:  true,
 			expectedDeleteEventFilterOutput:  false,
 			expectedGenericEventFilterOutput: false,
 		},
@@ -172,7 +187,7 @@ func TestMatchingEndpointsPredicate(t *testing.T) {
 			ep:                               epRelevant,
 			expectedCreateEventFilterOutput:  true,
 			expectedUpdateEventFilterOutput:  true,
-			expectedDeleteEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
 			expectedGenericEventFilterOutput: true,
 		},
 		{
@@ -189,7 +204,7 @@ func TestMatchingEndpointsPredicate(t *testing.T) {
 			oldEp:                            epRelevant,
 			expectedCreateEventFilterOutput:  true,
 			expectedUpdateEventFilterOutput:  true,
-			expectedDeleteEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
 			expectedGenericEventFilterOutput: true,
 		},
 		{
@@ -207,7 +222,7 @@ func TestMatchingEndpointsPredicate(t *testing.T) {
 			oldEp:                            epIrrelevant,
 			expectedCreateEventFilterOutput:  true,
 			expectedUpdateEventFilterOutput:  true,
-			expectedDeleteEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
 			expectedGenericEventFilterOutput: true,
 		},
 		{
@@ -260,3 +275,286 @@ func TestMatchingEndpointsPredicate(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileStopsWeederWhenEndpointsTurnNotReadyOrAreDeleted(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	const namespace = "shoot--dev--test"
+	ep := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: epName, Namespace: namespace}}
+	turnReady(ep)
+	crClient := fake.NewClientBuilder().WithObjects(newNamespace(namespace, nil), ep).Build()
+	weederMgr := weeder.NewManager()
+	defer weederMgr.UnregisterAll()
+	r := &Reconciler{
+		Client:         crClient,
+		PodEventSource: noopPodEventSource{},
+		WeederConfig: &v12.Config{
+			WatchDuration:                 &metav1.Duration{Duration: time.Minute},
+			ServicesAndDependantSelectors: map[string]v12.DependantSelectors{epName: {}},
+			UseEndpointSlices:             pointer.Bool(false),
+		},
+		WeederMgr: weederMgr,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: epName}}
+	weederKey := weeder.CreateKey(namespace, epName)
+
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	registration, ok := weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeTrue(), "a weeder should be started for ready endpoints")
+
+	ep.Subsets = nil
+	g.Expect(crClient.Update(ctx, ep)).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(registration.IsClosed()).To(BeTrue(), "the weeder should be stopped once the endpoints are no longer ready")
+	_, ok = weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeFalse(), "the stopped weeder should be unregistered")
+
+	turnReady(ep)
+	g.Expect(crClient.Update(ctx, ep)).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	registration, ok = weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeTrue(), "a new weeder should be started once the endpoints have turned ready again")
+
+	g.Expect(crClient.Delete(ctx, ep)).To(Succeed())
+	result, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero(), "a deleted endpoint should not be requeued")
+	g.Expect(registration.IsClosed()).To(BeTrue(), "the weeder should be stopped once the endpoints have been deleted")
+	_, ok = weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeFalse(), "the stopped weeder should be unregistered")
+}
+
+func TestReadyAddressesChanged(t *testing.T) {
+	g := NewWithT(t)
+	predicate := ReadyAddressesChanged(map[string]v12.DependantSelectors{
+		"ep-opted-in": {AddressChangeTrigger: &v12.AddressChangeTrigger{DebounceWindow: &metav1.Duration{Duration: time.Minute}}},
+		"ep-relevant": {},
+	})
+
+	testcases := []struct {
+		name                            string
+		ep                              *v1.Endpoints
+		oldEp                           *v1.Endpoints
+		expectedUpdateEventFilterOutput bool
+	}{
+		{
+			name:                            "ready addresses of opted in service changed",
+			ep:                              newEndpointsWithAddresses("ep-opted-in", "10.1.0.53"),
+			oldEp:                           newEndpointsWithAddresses("ep-opted-in", "10.1.0.52"),
+			expectedUpdateEventFilterOutput: true,
+		},
+		{
+			name:                            "ready address added to opted in service",
+			ep:                              newEndpointsWithAddresses("ep-opted-in", "10.1.0.52", "10.1.0.53"),
+			oldEp:                           newEndpointsWithAddresses("ep-opted-in", "10.1.0.52"),
+			expectedUpdateEventFilterOutput: true,
+		},
+		{
+			name:                            "ready addresses of opted in service unchanged",
+			ep:                              newEndpointsWithAddresses("ep-opted-in", "10.1.0.52"),
+			oldEp:                           newEndpointsWithAddresses("ep-opted-in", "10.1.0.52"),
+			expectedUpdateEventFilterOutput: false,
+		},
+		{
+			name:                            "opted in service turned ready",
+			ep:                              newEndpointsWithAddresses("ep-opted-in", "10.1.0.52"),
+			oldEp:                           newEndpointsWithAddresses("ep-opted-in"),
+			expectedUpdateEventFilterOutput: false,
+		},
+		{
+			name:                            "opted in service turned not ready",
+			ep:                              newEndpointsWithAddresses("ep-opted-in"),
+			oldEp:                           newEndpointsWithAddresses("ep-opted-in", "10.1.0.52"),
+			expectedUpdateEventFilterOutput: false,
+		},
+		{
+			name:                            "ready addresses of service which has not opted in changed",
+			ep:                              newEndpointsWithAddresses("ep-relevant", "10.1.0.53"),
+			oldEp:                           newEndpointsWithAddresses("ep-relevant", "10.1.0.52"),
+			expectedUpdateEventFilterOutput: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Expect(predicate.Create(event.CreateEvent{Object: tc.ep})).To(BeFalse())
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.oldEp, ObjectNew: tc.ep})).To(Equal(tc.expectedUpdateEventFilterOutput))
+			g.Expect(predicate.Delete(event.DeleteEvent{Object: tc.ep})).To(BeFalse())
+			g.Expect(predicate.Generic(event.GenericEvent{Object: tc.ep})).To(BeFalse())
+		})
+	}
+}
+
+func TestReconcileStartsWeederOnceReadyAddressesAreUnchangedForDebounceWindow(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	const namespace = "shoot--dev--test"
+	ep := newEndpointsWithAddresses(epName, "10.1.0.52")
+	ep.Namespace = namespace
+	crClient := fake.NewClientBuilder().WithObjects(newNamespace(namespace, nil), ep).Build()
+	weederMgr := weeder.NewManager()
+	defer weederMgr.UnregisterAll()
+	r := &Reconciler{
+		Client:         crClient,
+		PodEventSource: noopPodEventSource{},
+		WeederConfig: &v12.Config{
+			WatchDuration: &metav1.Duration{Duration: time.Minute},
+			ServicesAndDependantSelectors: map[string]v12.DependantSelectors{epName: {
+				AddressChangeTrigger: &v12.AddressChangeTrigger{DebounceWindow: &metav1.Duration{Duration: time.Minute}},
+			}},
+			UseEndpointSlices: pointer.Bool(false),
+		},
+		WeederMgr: weederMgr,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: epName}}
+	weederKey := weeder.CreateKey(namespace, epName)
+
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	firstRegistration, ok := weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeTrue(), "a weeder should be started once the endpoints turn ready")
+
+	ep.Subsets = newEndpointsWithAddresses(epName, "10.1.0.53").Subsets
+	g.Expect(crClient.Update(ctx, ep)).To(Succeed())
+	result, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(time.Minute), "the endpoints should be reconciled again after the debounce window")
+	g.Expect(firstRegistration.IsClosed()).To(BeFalse(), "no weeder should be started before the debounce window has passed")
+
+	result, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(And(BeNumerically(">", 0), BeNumerically("<=", time.Minute)), "the endpoints should be reconciled again once the rest of the debounce window has passed")
+	g.Expect(firstRegistration.IsClosed()).To(BeFalse(), "no weeder should be started before the debounce window has passed")
+
+	r.readyServices[req.NamespacedName].addressesChangedAt = time.Now().Add(-2 * time.Minute)
+	result, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(firstRegistration.IsClosed()).To(BeTrue(), "a new weeder should be started once the ready addresses are unchanged for the debounce window")
+	secondRegistration, ok := weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeTrue())
+
+	result, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(secondRegistration.IsClosed()).To(BeFalse(), "no further weeder should be started while the ready addresses stay unchanged")
+}
+
+func TestReconcileIgnoresReadyAddressChangesOfServicesWhichHaveNotOptedIn(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	const namespace = "shoot--dev--test"
+	ep := newEndpointsWithAddresses(epName, "10.1.0.52")
+	ep.Namespace = namespace
+	crClient := fake.NewClientBuilder().WithObjects(newNamespace(namespace, nil), ep).Build()
+	weederMgr := weeder.NewManager()
+	defer weederMgr.UnregisterAll()
+	r := &Reconciler{
+		Client:         crClient,
+		PodEventSource: noopPodEventSource{},
+		WeederConfig: &v12.Config{
+			WatchDuration:                 &metav1.Duration{Duration: time.Minute},
+			ServicesAndDependantSelectors: map[string]v12.DependantSelectors{epName: {}},
+			UseEndpointSlices:             pointer.Bool(false),
+		},
+		WeederMgr: weederMgr,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: epName}}
+
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	registration, ok := weederMgr.GetWeederRegistration(weeder.CreateKey(namespace, epName))
+	g.Expect(ok).To(BeTrue(), "a weeder should be started once the endpoints turn ready")
+
+	ep.Subsets = newEndpointsWithAddresses(epName, "10.1.0.53").Subsets
+	g.Expect(crClient.Update(ctx, ep)).To(Succeed())
+	result, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(registration.IsClosed()).To(BeFalse(), "no weeder should be started for address changes of a service which has not opted in")
+}
+
+func newEndpointsWithAddresses(name string, ips ...string) *v1.Endpoints {
+	ep := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if len(ips) == 0 {
+		return ep
+	}
+	subset := v1.EndpointSubset{}
+	for _, ip := range ips {
+		subset.Addresses = append(subset.Addresses, v1.EndpointAddress{IP: ip})
+	}
+	ep.Subsets = []v1.EndpointSubset{subset}
+	return ep
+}
+
+func TestReconcileHonoursWeedingAnnotationsOfNamespace(t *testing.T) {
+	const namespace = "shoot--dev--test"
+	testcases := []struct {
+		name                string
+		annotations         map[string]string
+		expectWeederToExist bool
+	}{
+		{
+			name:                "namespace without annotations",
+			expectWeederToExist: true,
+		},
+		{
+			name:                "weeding disabled for namespace",
+			annotations:         map[string]string{v12.IgnoreWeedingAnnotationKey: "true"},
+			expectWeederToExist: false,
+		},
+		{
+			name:                "invalid value of ignore weeding annotation",
+			annotations:         map[string]string{v12.IgnoreWeedingAnnotationKey: "yes please"},
+			expectWeederToExist: true,
+		},
+		{
+			name:                "weeding limited to other services",
+			annotations:         map[string]string{v12.WeedingServicesAnnotationKey: "kube-apiserver"},
+			expectWeederToExist: false,
+		},
+		{
+			name:                "weeding limited to services including the service",
+			annotations:         map[string]string{v12.WeedingServicesAnnotationKey: "kube-apiserver, " + epName},
+			expectWeederToExist: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			ep := newEndpointsWithAddresses(epName, "10.1.0.52")
+			ep.Namespace = namespace
+			crClient := fake.NewClientBuilder().WithObjects(newNamespace(namespace, tc.annotations), ep).Build()
+			weederMgr := weeder.NewManager()
+			defer weederMgr.UnregisterAll()
+			r := &Reconciler{
+				Client:         crClient,
+				PodEventSource: noopPodEventSource{},
+				WeederConfig: &v12.Config{
+					WatchDuration:                 &metav1.Duration{Duration: time.Minute},
+					ServicesAndDependantSelectors: map[string]v12.DependantSelectors{epName: {}},
+					UseEndpointSlices:             pointer.Bool(false),
+				},
+				WeederMgr: weederMgr,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: epName}})
+			g.Expect(err).ToNot(HaveOccurred())
+			_, ok := weederMgr.GetWeederRegistration(weeder.CreateKey(namespace, epName))
+			g.Expect(ok).To(Equal(tc.expectWeederToExist))
+		})
+	}
+}
+
+func newNamespace(name string, annotations map[string]string) *v1.Namespace {
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+}
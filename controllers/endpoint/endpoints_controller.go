@@ -16,19 +16,28 @@ package endpoint
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	"github.com/gardener/dependency-watchdog/internal/weeder"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
@@ -37,37 +46,190 @@ const controllerName = "endpoint"
 // Reconciler EndpointReconciler reconciles an Endpoints object
 type Reconciler struct {
 	client.Client
-	SeedClient              kubernetes.Interface
-	WeederConfig            *wapi.Config
-	WeederMgr               weeder.Manager
+	// PodEventSource dispatches the events of the shared pod informer to the weeders, which register interest in the pods they weed.
+	PodEventSource weeder.PodEventSource
+	WeederConfig   *wapi.Config
+	WeederMgr      weeder.Manager
+	// Notifier notifies webhooks about pods deleted by the weeders. It is nil if no webhooks have been configured.
+	Notifier notifier.Notifier
+	// AuditSink is the audit log to which every pod deleted by the weeders is appended. It is nil if no audit log has been configured.
+	AuditSink audit.Sink
+	// EventRecorder records events on the pods deleted by the weeders and on the Endpoints of the services which triggered them.
+	EventRecorder           record.EventRecorder
 	MaxConcurrentReconciles int
+	// readyServices are the services which have last been found to be ready, together with their ready addresses. They
+	// are used to aggregate the readiness across all EndpointSlices of a service and to debounce address changes.
+	readyServices   map[types.NamespacedName]*readyService
+	readyServicesMu sync.Mutex
+}
+
+// readyService is the last observed state of a ready service.
+type readyService struct {
+	// addresses are the ready addresses of the service.
+	addresses sets.Set[string]
+	// addressesChangedAt is the time at which the ready addresses have last changed while the service stayed ready.
+	// It is zero if no weeder is pending for an address change.
+	addressesChangedAt time.Time
 }
 
 // +kubebuilder:rbac:resources=endpoints,verbs=get;list;watch
+// +kubebuilder:rbac:resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 // +kubebuilder:rbac:resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=patch
+// +kubebuilder:rbac:resources=configmaps,verbs=get;create;update
 
-// Reconcile listens to create/update events for `Endpoints` resources and manages weeder which shoot the dependent pods of the configured services, if necessary
+// Reconcile listens to create/update/delete events for `Endpoints` or `EndpointSlices` resources and manages weeder which shoot the dependent pods of the configured services, if necessary.
+// Weeders are stopped as soon as the endpoints of their service are deleted or are no longer ready
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
+	if *r.WeederConfig.UseEndpointSlices {
+		return r.reconcileEndpointSlices(ctx, log, req)
+	}
 	//Get the endpoint object
 	var ep v1.Endpoints
 	err := r.Client.Get(ctx, req.NamespacedName, &ep)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forgetService(req.NamespacedName)
+			r.stopWeeder(log, req.Namespace, req.Name, "endpoint has been deleted")
+			return ctrl.Result{}, nil
+		}
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 	}
+	if !isEndpointReady(&ep) {
+		r.forgetService(req.NamespacedName)
+		r.stopWeeder(log, req.Namespace, req.Name, "endpoint is no longer ready")
+		return ctrl.Result{}, nil
+	}
+	enabled, err := r.isWeedingEnabled(ctx, log, req.Namespace, req.Name)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+	if !enabled {
+		return ctrl.Result{}, nil
+	}
+	start, requeueAfter := r.observeReadyAddresses(req.NamespacedName, readyEndpointAddresses(&ep))
+	if !start {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 	log.Info("Starting a new weeder for endpoint, replacing old weeder, if any exists", "namespace", req.Namespace, "endpoint", ep.Name)
-	r.startWeeder(ctx, log, req.Namespace, &ep)
+	r.startWeeder(ctx, log, req.Namespace, ep.Name)
+	return ctrl.Result{}, nil
+}
+
+// reconcileEndpointSlices aggregates the readiness of all EndpointSlices of the service identified by the request and
+// starts a weeder once the service has turned ready or, if opted in, its ready addresses have changed.
+func (r *Reconciler) reconcileEndpointSlices(ctx context.Context, log logr.Logger, req ctrl.Request) (ctrl.Result, error) {
+	var slices discoveryv1.EndpointSliceList
+	if err := r.Client.List(ctx, &slices, client.InNamespace(req.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: req.Name}); err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+	ready := false
+	for i := range slices.Items {
+		if isEndpointSliceReady(&slices.Items[i]) {
+			ready = true
+			break
+		}
+	}
+	if !ready {
+		r.forgetService(req.NamespacedName)
+		r.stopWeeder(log, req.Namespace, req.Name, "EndpointSlices of service are no longer ready")
+		return ctrl.Result{}, nil
+	}
+	enabled, err := r.isWeedingEnabled(ctx, log, req.Namespace, req.Name)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+	if !enabled {
+		return ctrl.Result{}, nil
+	}
+	start, requeueAfter := r.observeReadyAddresses(req.NamespacedName, readyEndpointSliceAddresses(slices.Items...))
+	if !start {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	log.Info("Starting a new weeder for service whose EndpointSlices turned ready or whose ready addresses changed, replacing old weeder, if any exists", "namespace", req.Namespace, "service", req.Name)
+	r.startWeeder(ctx, log, req.Namespace, req.Name)
 	return ctrl.Result{}, nil
 }
 
-// startWeeder starts a new weeder for the endpoint
-func (r *Reconciler) startWeeder(ctx context.Context, logger logr.Logger, namespace string, ep *v1.Endpoints) {
-	w := weeder.NewWeeder(ctx, namespace, r.WeederConfig, r.Client, r.SeedClient, ep, logger)
+// isWeedingEnabled checks if the annotations of the namespace allow a weeder to be started for the service. If weeding
+// is disabled, the service is forgotten and its weeder, if any, is stopped, so that a weeder is started with the next
+// event of the service once weeding has been enabled again.
+func (r *Reconciler) isWeedingEnabled(ctx context.Context, logger logr.Logger, namespace string, serviceName string) (bool, error) {
+	ns := &metav1.PartialObjectMetadata{}
+	ns.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Namespace"))
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	if weeder.IsWeedingEnabled(ns.Annotations, serviceName) {
+		return true, nil
+	}
+	logger.Info("Weeding is disabled for service via annotation of its namespace", "namespace", namespace, "service", serviceName)
+	r.forgetService(types.NamespacedName{Namespace: namespace, Name: serviceName})
+	r.stopWeeder(logger, namespace, serviceName, "weeding has been disabled via annotation of namespace")
+	return false, nil
+}
+
+// observeReadyAddresses records the ready addresses of a ready service and returns whether a weeder has to be started for
+// it. A weeder is started once the service turns ready. If the service has opted in to address change triggers, a weeder
+// is also started once its ready addresses have changed and then stayed unchanged for the debounce window. Until then,
+// the returned duration is the time after which the service has to be reconciled again.
+func (r *Reconciler) observeReadyAddresses(service types.NamespacedName, addresses sets.Set[string]) (bool, time.Duration) {
+	r.readyServicesMu.Lock()
+	defer r.readyServicesMu.Unlock()
+	rs, wasReady := r.readyServices[service]
+	if !wasReady {
+		if r.readyServices == nil {
+			r.readyServices = make(map[types.NamespacedName]*readyService)
+		}
+		r.readyServices[service] = &readyService{addresses: addresses}
+		return true, 0
+	}
+	trigger := r.WeederConfig.ServicesAndDependantSelectors[service.Name].AddressChangeTrigger
+	if trigger == nil {
+		rs.addresses = addresses
+		return false, 0
+	}
+	if !rs.addresses.Equal(addresses) {
+		rs.addresses = addresses
+		rs.addressesChangedAt = time.Now()
+		return false, trigger.DebounceWindow.Duration
+	}
+	if rs.addressesChangedAt.IsZero() {
+		return false, 0
+	}
+	if remaining := trigger.DebounceWindow.Duration - time.Since(rs.addressesChangedAt); remaining > 0 {
+		return false, remaining
+	}
+	rs.addressesChangedAt = time.Time{}
+	return true, 0
+}
+
+// forgetService forgets the ready addresses of a service which is no longer ready.
+func (r *Reconciler) forgetService(service types.NamespacedName) {
+	r.readyServicesMu.Lock()
+	defer r.readyServicesMu.Unlock()
+	delete(r.readyServices, service)
+}
+
+// startWeeder starts a new weeder for the service
+func (r *Reconciler) startWeeder(ctx context.Context, logger logr.Logger, namespace string, serviceName string) {
+	w := weeder.NewWeeder(ctx, namespace, r.WeederConfig, r.Client, r.PodEventSource, serviceName, r.Notifier, r.AuditSink, r.EventRecorder, logger)
 	// Register the weeder
 	r.WeederMgr.Register(*w)
 	go w.Run()
 }
 
+// stopWeeder closes and unregisters the weeder of the service, if one is running
+func (r *Reconciler) stopWeeder(logger logr.Logger, namespace string, serviceName string, reason string) {
+	if r.WeederMgr.Unregister(weeder.CreateKey(namespace, serviceName)) {
+		logger.Info("Stopped weeder for service", "namespace", namespace, "service", serviceName, "reason", reason)
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	c, err := controller.New(
@@ -80,13 +242,40 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err != nil {
 		return err
 	}
+	if *r.WeederConfig.UseEndpointSlices {
+		return c.Watch(
+			&source.Kind{Type: &discoveryv1.EndpointSlice{}},
+			handler.EnqueueRequestsFromMapFunc(mapEndpointSliceToService),
+			predicate.And(
+				predicate.ResourceVersionChangedPredicate{},
+				MatchingEndpointSlices(r.WeederConfig.ServicesAndDependantSelectors),
+				predicate.Or(
+					ReadyEndpointSlices(c.GetLogger()),
+					ReadyEndpointSliceAddressesChanged(r.WeederConfig.ServicesAndDependantSelectors),
+				),
+			),
+		)
+	}
 	return c.Watch(
 		&source.Kind{Type: &v1.Endpoints{}},
 		&handler.EnqueueRequestForObject{},
 		predicate.And(
 			predicate.ResourceVersionChangedPredicate{},
 			MatchingEndpoints(r.WeederConfig.ServicesAndDependantSelectors),
-			ReadyEndpoints(c.GetLogger()),
+			predicate.Or(
+				ReadyEndpoints(c.GetLogger()),
+				ReadyAddressesChanged(r.WeederConfig.ServicesAndDependantSelectors),
+			),
 		),
 	)
 }
+
+// mapEndpointSliceToService maps an EndpointSlice to a request for the service it belongs to, so that the events of all
+// EndpointSlices of a service are reconciled together.
+func mapEndpointSliceToService(obj client.Object) []reconcile.Request {
+	serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: serviceName}}}
+}
@@ -26,7 +26,6 @@ import (
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
 	"k8s.io/client-go/kubernetes/scheme"
 
-	internalutils "github.com/gardener/dependency-watchdog/internal/util"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -71,27 +70,29 @@ func setupWeederEnv(ctx context.Context, t *testing.T, kubeApiServerFlags map[st
 	cfg := controllerTestEnv.GetConfig()
 	crClient := controllerTestEnv.GetClient()
 
-	clientSet, err := internalutils.CreateClientSetFromRestConfig(cfg)
-	g.Expect(err).NotTo(HaveOccurred())
-
 	weederConfigPath := filepath.Join(testdataPath, "weeder-config.yaml")
 	testutil.ValidateIfFileExists(weederConfigPath, t)
 	weederConfig, err := weederpackage.LoadConfig(weederConfigPath)
 	g.Expect(err).To(BeNil())
 
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme: s,
+	})
+	g.Expect(err).To(BeNil())
+
+	podInformer, err := mgr.GetCache().GetInformer(ctx, &v1.Pod{})
+	g.Expect(err).To(BeNil())
+	podEventSource, err := weederpackage.NewPodEventSource(podInformer, ctrl.Log)
+	g.Expect(err).To(BeNil())
+
 	epReconciler := &Reconciler{
 		Client:                  crClient,
 		WeederConfig:            weederConfig,
-		SeedClient:              clientSet,
+		PodEventSource:          podEventSource,
 		WeederMgr:               weederpackage.NewManager(),
 		MaxConcurrentReconciles: maxConcurrentReconcilesWeeder,
 	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme: s,
-	})
-
-	g.Expect(err).To(BeNil())
 	err = epReconciler.SetupWithManager(mgr)
 	g.Expect(err).To(BeNil())
 	go func() {
@@ -189,7 +190,8 @@ func testOnlyCLBFPodDeletion(ctx context.Context, _ context.CancelFunc, g *WithT
 	g.Expect(err).To(BeNil())
 	turnPodToCrashLoop(ctx, g, reconciler.Client, pC)
 
-	pl, err := reconciler.SeedClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pl := &v1.PodList{}
+	err = reconciler.Client.List(ctx, pl, client.InNamespace(namespace))
 	g.Expect(err).To(BeNil())
 	g.Expect(len(pl.Items)).Should(Equal(2))
 
@@ -214,7 +216,8 @@ func testPodTurningCLBFDeletion(ctx context.Context, _ context.CancelFunc, g *Wi
 	g.Expect(err).To(BeNil())
 	turnPodToHealthy(ctx, g, reconciler.Client, pod)
 
-	pl, err := reconciler.SeedClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pl := &v1.PodList{}
+	err = reconciler.Client.List(ctx, pl, client.InNamespace(namespace))
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(len(pl.Items)).Should(Equal(1))
 
@@ -295,7 +298,8 @@ func testNoCLBFPodDeletionOnContextCancellation(ctx context.Context, cancelFn co
 	// cancel context (like SIGKILL signal to the process)
 	cancelFn()
 
-	currentPod, err := reconciler.SeedClient.CoreV1().Pods(namespace).Get(context.TODO(), crashingPod, metav1.GetOptions{})
+	currentPod := v1.Pod{}
+	err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: crashingPod}, &currentPod)
 	g.Expect(err).To(BeNil())
 	g.Expect(currentPod.DeletionTimestamp).To(BeNil())
 }
@@ -0,0 +1,157 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/go-logr/logr"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ReadyEndpointSlices is a predicate to allow events for EndpointSlices which may change the readiness of their service.
+// As the readiness of a service is aggregated across all its EndpointSlices, a slice which turns unready or is deleted is
+// not filtered out, so that the reconciler can notice when the service has become unready.
+func ReadyEndpointSlices(logger logr.Logger) predicate.Predicate {
+	log := logger.WithValues("predicate", "ReadyEndpointSlicesPredicate")
+	isReady := func(obj runtime.Object) bool {
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
+		if !ok || slice == nil {
+			return false
+		}
+		if isEndpointSliceReady(slice) {
+			return true
+		}
+		log.V(4).Info("EndpointSlice does not have any ready endpoint", "namespace", slice.Namespace, "endpointSlice", slice.Name)
+		return false
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(event event.CreateEvent) bool {
+			return isReady(event.Object)
+		},
+
+		UpdateFunc: func(event event.UpdateEvent) bool {
+			return isReady(event.ObjectNew) != isReady(event.ObjectOld)
+		},
+
+		DeleteFunc: func(event event.DeleteEvent) bool {
+			return isReady(event.Object)
+		},
+
+		GenericFunc: func(event event.GenericEvent) bool {
+			return isReady(event.Object)
+		},
+	}
+}
+
+// ReadyEndpointSliceAddressesChanged is a predicate to allow update events of ready EndpointSlices whose ready addresses
+// have changed while they stayed ready. Only events for EndpointSlices of services which have opted in to address change
+// triggers are allowed. Ready addresses which are added or removed with whole EndpointSlices are already covered by
+// ReadyEndpointSlices.
+func ReadyEndpointSliceAddressesChanged(epMap map[string]wapi.DependantSelectors) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event event.CreateEvent) bool {
+			return false
+		},
+
+		UpdateFunc: func(event event.UpdateEvent) bool {
+			oldSlice, ok := event.ObjectOld.(*discoveryv1.EndpointSlice)
+			if !ok || oldSlice == nil {
+				return false
+			}
+			newSlice, ok := event.ObjectNew.(*discoveryv1.EndpointSlice)
+			if !ok || newSlice == nil || epMap[newSlice.Labels[discoveryv1.LabelServiceName]].AddressChangeTrigger == nil {
+				return false
+			}
+			oldAddresses, newAddresses := readyEndpointSliceAddresses(*oldSlice), readyEndpointSliceAddresses(*newSlice)
+			return oldAddresses.Len() > 0 && newAddresses.Len() > 0 && !oldAddresses.Equal(newAddresses)
+		},
+
+		DeleteFunc: func(event event.DeleteEvent) bool {
+			return false
+		},
+
+		GenericFunc: func(event event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// MatchingEndpointSlices is a predicate to allow events for only EndpointSlices of configured services
+func MatchingEndpointSlices(epMap map[string]wapi.DependantSelectors) predicate.Predicate {
+	isMatchingEndpointSlices := func(obj runtime.Object, epMap map[string]wapi.DependantSelectors) bool {
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
+		if !ok || slice == nil {
+			return false
+		}
+		serviceName, ok := slice.Labels[discoveryv1.LabelServiceName]
+		if !ok {
+			return false
+		}
+		_, exists := epMap[serviceName]
+		return exists
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(event event.CreateEvent) bool {
+			return isMatchingEndpointSlices(event.Object, epMap)
+		},
+
+		UpdateFunc: func(event event.UpdateEvent) bool {
+			return isMatchingEndpointSlices(event.ObjectNew, epMap)
+		},
+
+		DeleteFunc: func(event event.DeleteEvent) bool {
+			return isMatchingEndpointSlices(event.Object, epMap)
+		},
+
+		GenericFunc: func(event event.GenericEvent) bool {
+			return isMatchingEndpointSlices(event.Object, epMap)
+		},
+	}
+}
+
+// isEndpointSliceReady checks if the EndpointSlice has at least a single ready endpoint.
+func isEndpointSliceReady(slice *discoveryv1.EndpointSlice) bool {
+	for _, ep := range slice.Endpoints {
+		if isSliceEndpointReady(ep) {
+			return true
+		}
+	}
+	return false
+}
+
+// readyEndpointSliceAddresses returns the addresses of all ready endpoints across the EndpointSlices.
+func readyEndpointSliceAddresses(slices ...discoveryv1.EndpointSlice) sets.Set[string] {
+	addresses := sets.New[string]()
+	for _, slice := range slices {
+		for _, ep := range slice.Endpoints {
+			if isSliceEndpointReady(ep) {
+				addresses.Insert(ep.Addresses...)
+			}
+		}
+	}
+	return addresses
+}
+
+// isSliceEndpointReady checks if an endpoint of an EndpointSlice is ready. As per the API, an endpoint whose readiness is
+// unknown is to be interpreted as ready.
+func isSliceEndpointReady(ep discoveryv1.Endpoint) bool {
+	return ep.Conditions.Ready == nil || *ep.Conditions.Ready
+}
@@ -0,0 +1,297 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package endpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v12 "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/weeder"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// noopPodEventSource never sends any pod events.
+type noopPodEventSource struct{}
+
+func (noopPodEventSource) Subscribe(_ context.Context, _ string, _ labels.Selector) <-chan *v1.Pod {
+	return make(chan *v1.Pod)
+}
+
+func newEndpointSlice(name, namespace, serviceName string, ready ...*bool) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: serviceName},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	for _, r := range ready {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{"10.1.0.52"},
+			Conditions: discoveryv1.EndpointConditions{Ready: r},
+		})
+	}
+	return slice
+}
+
+func TestReadyEndpointSlices(t *testing.T) {
+	g := NewWithT(t)
+	predicate := ReadyEndpointSlices(logr.Discard())
+
+	readySlice := newEndpointSlice("ep-abc", "default", epName, pointer.Bool(false), pointer.Bool(true))
+	unknownReadinessSlice := newEndpointSlice("ep-abc", "default", epName, nil)
+	notReadySlice := newEndpointSlice("ep-abc", "default", epName, pointer.Bool(false))
+
+	testcases := []struct {
+		name                             string
+		slice                            *discoveryv1.EndpointSlice
+		oldSlice                         *discoveryv1.EndpointSlice
+		expectedCreateEventFilterOutput  bool
+		expectedUpdateEventFilterOutput  bool
+		expectedDeleteEventFilterOutput  bool
+		expectedGenericEventFilterOutput bool
+	}{
+		{
+			name:                             "no slice -> Ready slice",
+			slice:                            readySlice,
+			expectedCreateEventFilterOutput:  true,
+			expectedUpdateEventFilterOutput:  true,
+			expectedDeleteEventFilterOutput:  true,
+			expectedGenericEventFilterOutput: true,
+		},
+		{
+			name:                             "no slice -> slice with unknown readiness",
+			slice:                            unknownReadinessSlice,
+			expectedCreateEventFilterOutput:  true,
+			expectedUpdateEventFilterOutput:  true,
+			expectedDeleteEventFilterOutput:  true,
+			expectedGenericEventFilterOutput: true,
+		},
+		{
+			name:                             "no slice -> NotReady slice",
+			slice:                            notReadySlice,
+			expectedCreateEventFilterOutput:  false,
+			expectedUpdateEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  false,
+			expectedGenericEventFilterOutput: false,
+		},
+		{
+			name:                             "NotReady slice -> Ready slice",
+			slice:                            readySlice,
+			oldSlice:                         notReadySlice,
+			expectedCreateEventFilterOutput:  true,
+			expectedUpdateEventFilterOutput:  true,
+			expectedDeleteEventFilterOutput:  true,
+			expectedGenericEventFilterOutput: true,
+		},
+		{
+			name:                             "Ready slice -> Ready slice",
+			slice:                            readySlice,
+			oldSlice:                         readySlice,
+			expectedCreateEventFilterOutput:  true,
+			expectedUpdateEventFilterOutput:  false,
+			expectedDeleteEventFilterOutput:  true,
+			expectedGenericEventFilterOutput: true,
+		},
+		{
+			name:                             "Ready slice -> NotReady slice",
+			slice:                            notReadySlice,
+			oldSlice:                         readySlice,
+			expectedCreateEventFilterOutput:  false,
+			expectedUpdateEventFilterOutput:  true,
+			expectedDeleteEventFilterOutput:  false,
+			expectedGenericEventFilterOutput: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Expect(predicate.Create(event.CreateEvent{Object: tc.slice})).To(Equal(tc.expectedCreateEventFilterOutput))
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.oldSlice, ObjectNew: tc.slice})).To(Equal(tc.expectedUpdateEventFilterOutput))
+			g.Expect(predicate.Delete(event.DeleteEvent{Object: tc.slice})).To(Equal(tc.expectedDeleteEventFilterOutput))
+			g.Expect(predicate.Generic(event.GenericEvent{Object: tc.slice})).To(Equal(tc.expectedGenericEventFilterOutput))
+		})
+	}
+}
+
+func TestMatchingEndpointSlicesPredicate(t *testing.T) {
+	g := NewWithT(t)
+	predicate := MatchingEndpointSlices(map[string]v12.DependantSelectors{"ep-relevant": {}})
+
+	relevantSlice := newEndpointSlice("ep-relevant-abc", "default", "ep-relevant")
+	irrelevantSlice := newEndpointSlice("ep-irrelevant-abc", "default", "ep-irrelevant")
+	unlabelledSlice := newEndpointSlice("ep-relevant", "default", "")
+	unlabelledSlice.Labels = nil
+
+	testcases := []struct {
+		name     string
+		slice    *discoveryv1.EndpointSlice
+		expected bool
+	}{
+		{"slice of relevant service", relevantSlice, true},
+		{"slice of irrelevant service", irrelevantSlice, false},
+		{"slice without service name label", unlabelledSlice, false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Expect(predicate.Create(event.CreateEvent{Object: tc.slice})).To(Equal(tc.expected))
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.slice, ObjectNew: tc.slice})).To(Equal(tc.expected))
+			g.Expect(predicate.Delete(event.DeleteEvent{Object: tc.slice})).To(Equal(tc.expected))
+			g.Expect(predicate.Generic(event.GenericEvent{Object: tc.slice})).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestMapEndpointSliceToService(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(mapEndpointSliceToService(newEndpointSlice("etcd-main-abc", "shoot--dev--test", epName))).To(Equal([]reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "shoot--dev--test", Name: epName}},
+	}))
+	unlabelledSlice := newEndpointSlice("etcd-main-abc", "shoot--dev--test", epName)
+	unlabelledSlice.Labels = nil
+	g.Expect(mapEndpointSliceToService(unlabelledSlice)).To(BeEmpty())
+}
+
+func TestReconcileEndpointSlicesStartsWeederWhenServiceTurnsReady(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	const namespace = "shoot--dev--test"
+	notReadySlice := newEndpointSlice("etcd-main-abc", namespace, epName, pointer.Bool(false))
+	readySlice := newEndpointSlice("etcd-main-def", namespace, epName, pointer.Bool(true))
+	crClient := fake.NewClientBuilder().WithObjects(newNamespace(namespace, nil), notReadySlice).Build()
+	weederMgr := weeder.NewManager()
+	defer weederMgr.UnregisterAll()
+	r := &Reconciler{
+		Client:         crClient,
+		PodEventSource: noopPodEventSource{},
+		WeederConfig: &v12.Config{
+			WatchDuration:                 &metav1.Duration{Duration: time.Minute},
+			ServicesAndDependantSelectors: map[string]v12.DependantSelectors{epName: {}},
+			UseEndpointSlices:             pointer.Bool(true),
+		},
+		WeederMgr: weederMgr,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: epName}}
+	weederKey := weeder.CreateKey(namespace, epName)
+
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, ok := weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeFalse(), "no weeder should be started while no EndpointSlice of the service is ready")
+
+	g.Expect(crClient.Create(ctx, readySlice)).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	firstRegistration, ok := weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeTrue(), "a weeder should be started once an EndpointSlice of the service is ready")
+
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(firstRegistration.IsClosed()).To(BeFalse(), "the weeder should not be replaced while the service stays ready")
+
+	g.Expect(crClient.Delete(ctx, readySlice)).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(firstRegistration.IsClosed()).To(BeTrue(), "the weeder should be stopped once no EndpointSlice of the service is ready anymore")
+	_, ok = weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeFalse(), "the stopped weeder should be unregistered")
+	g.Expect(crClient.Create(ctx, newEndpointSlice("etcd-main-ghi", namespace, epName, pointer.Bool(true)))).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, ok = weederMgr.GetWeederRegistration(weederKey)
+	g.Expect(ok).To(BeTrue(), "a new weeder should be started once the service has turned ready again")
+}
+
+func TestReadyEndpointSliceAddressesChanged(t *testing.T) {
+	g := NewWithT(t)
+	predicate := ReadyEndpointSliceAddressesChanged(map[string]v12.DependantSelectors{
+		epName:        {AddressChangeTrigger: &v12.AddressChangeTrigger{DebounceWindow: &metav1.Duration{Duration: time.Minute}}},
+		"ep-relevant": {},
+	})
+	withAddress := func(slice *discoveryv1.EndpointSlice, address string) *discoveryv1.EndpointSlice {
+		slice.Endpoints[0].Addresses = []string{address}
+		return slice
+	}
+
+	testcases := []struct {
+		name                            string
+		slice                           *discoveryv1.EndpointSlice
+		oldSlice                        *discoveryv1.EndpointSlice
+		expectedUpdateEventFilterOutput bool
+	}{
+		{
+			name:                            "ready addresses of opted in service changed",
+			slice:                           withAddress(newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)), "10.1.0.53"),
+			oldSlice:                        newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)),
+			expectedUpdateEventFilterOutput: true,
+		},
+		{
+			name:                            "ready addresses of opted in service unchanged",
+			slice:                           newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)),
+			oldSlice:                        newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)),
+			expectedUpdateEventFilterOutput: false,
+		},
+		{
+			name:                            "ready endpoint of opted in service added",
+			slice:                           withAddress(newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true), pointer.Bool(true)), "10.1.0.53"),
+			oldSlice:                        newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)),
+			expectedUpdateEventFilterOutput: true,
+		},
+		{
+			name:                            "not ready endpoint of opted in service added",
+			slice:                           withAddress(newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(false), pointer.Bool(true)), "10.1.0.53"),
+			oldSlice:                        newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)),
+			expectedUpdateEventFilterOutput: false,
+		},
+		{
+			name:                            "opted in service turned ready",
+			slice:                           newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(true)),
+			oldSlice:                        newEndpointSlice("etcd-main-abc", "default", epName, pointer.Bool(false)),
+			expectedUpdateEventFilterOutput: false,
+		},
+		{
+			name:                            "ready addresses of service which has not opted in changed",
+			slice:                           withAddress(newEndpointSlice("ep-relevant-abc", "default", "ep-relevant", pointer.Bool(true)), "10.1.0.53"),
+			oldSlice:                        newEndpointSlice("ep-relevant-abc", "default", "ep-relevant", pointer.Bool(true)),
+			expectedUpdateEventFilterOutput: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Expect(predicate.Create(event.CreateEvent{Object: tc.slice})).To(BeFalse())
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.oldSlice, ObjectNew: tc.slice})).To(Equal(tc.expectedUpdateEventFilterOutput))
+			g.Expect(predicate.Delete(event.DeleteEvent{Object: tc.slice})).To(BeFalse())
+		})
+	}
+}
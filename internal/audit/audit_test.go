@@ -0,0 +1,167 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	multierr "github.com/hashicorp/go-multierror"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "shoot--bingo"
+
+func TestSinkFillsActorReplicaAndTime(t *testing.T) {
+	g := NewWithT(t)
+	t.Setenv(podNameEnvVar, "dependency-watchdog-prober-0")
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s := createTestSink(g, &aapi.Config{Sink: aapi.SinkTypeFile, File: &aapi.FileSink{Path: path}}, nil)
+
+	g.Expect(s.Write(context.Background(), Record{ShootNamespace: testNamespace, Action: ActionScaleDown, Resource: "Deployment/kube-controller-manager", ReplicasBefore: pointer.Int32(2), ReplicasAfter: pointer.Int32(0), Result: ResultSucceeded})).To(Succeed())
+
+	records := readRecords(g, path)
+	g.Expect(records).To(HaveLen(1))
+	g.Expect(records[0].Actor).To(Equal("prober"))
+	g.Expect(records[0].Replica).To(Equal("dependency-watchdog-prober-0"))
+	g.Expect(records[0].Time.IsZero()).To(BeFalse())
+	g.Expect(*records[0].ReplicasBefore).To(Equal(int32(2)))
+	g.Expect(*records[0].ReplicasAfter).To(Equal(int32(0)))
+}
+
+func TestFileSinkIsRotated(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	line := []byte(strings.Repeat("x", 9) + "\n")
+	w := newFileWriter(path, 25, 2)
+
+	for i := 0; i < 7; i++ {
+		g.Expect(w.write(context.Background(), testNamespace, line)).To(Succeed())
+	}
+	for _, entry := range []struct {
+		path          string
+		expectedLines int
+	}{{path, 1}, {path + ".1", 2}, {path + ".2", 2}} {
+		content, err := os.ReadFile(entry.path)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(strings.Count(string(content), "\n")).To(Equal(entry.expectedLines), "unexpected number of records in %s", entry.path)
+	}
+	_, err := os.Stat(path + ".3")
+	g.Expect(os.IsNotExist(err)).To(BeTrue(), "at most maxBackups rotated files should be retained")
+}
+
+func TestFileSinkAppendsToExistingFile(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	g.Expect(os.WriteFile(path, []byte("{}\n"), 0644)).To(Succeed())
+
+	g.Expect(newFileWriter(path, 1024, 1).write(context.Background(), testNamespace, []byte("{}\n"))).To(Succeed())
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(Equal("{}\n{}\n"))
+}
+
+func TestConfigMapSinkRetainsLatestRecords(t *testing.T) {
+	g := NewWithT(t)
+	fakeClient := fake.NewClientBuilder().Build()
+	config := &aapi.Config{Sink: aapi.SinkTypeConfigMap, ConfigMap: &aapi.ConfigMapSink{MaxRecords: pointer.Int(3)}}
+	s := createTestSink(g, config, fakeClient)
+
+	for i := 0; i < 5; i++ {
+		g.Expect(s.Write(context.Background(), Record{ShootNamespace: testNamespace, Action: ActionDeletePod, Resource: fmt.Sprintf("Pod/kube-apiserver-%d", i), Result: ResultSucceeded})).To(Succeed())
+	}
+
+	cm := &corev1.ConfigMap{}
+	g.Expect(fakeClient.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: DefaultConfigMapName}, cm)).To(Succeed())
+	lines := strings.Split(strings.TrimSuffix(cm.Data[recordsKey], "\n"), "\n")
+	g.Expect(lines).To(HaveLen(3))
+	for i, line := range lines {
+		var record Record
+		g.Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+		g.Expect(record.Resource).To(Equal(fmt.Sprintf("Pod/kube-apiserver-%d", i+2)), "the oldest records should have been dropped")
+	}
+}
+
+func TestAppendRecord(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(appendRecord("", "a\n", 2)).To(Equal("a\n"))
+	g.Expect(appendRecord("a\n", "b\n", 2)).To(Equal("a\nb\n"))
+	g.Expect(appendRecord("a\nb\n", "c\n", 2)).To(Equal("b\nc\n"))
+}
+
+func TestValidate(t *testing.T) {
+	table := []struct {
+		description        string
+		config             *aapi.Config
+		expectedErrorCount int
+	}{
+		{"valid file sink", &aapi.Config{Sink: aapi.SinkTypeFile, File: &aapi.FileSink{Path: "/var/log/dwd/audit.log"}}, 0},
+		{"valid configmap sink", &aapi.Config{Sink: aapi.SinkTypeConfigMap}, 0},
+		{"unsupported sink", &aapi.Config{Sink: "Syslog"}, 1},
+		{"file sink without file", &aapi.Config{Sink: aapi.SinkTypeFile}, 1},
+		{"invalid file sink", &aapi.Config{Sink: aapi.SinkTypeFile, File: &aapi.FileSink{MaxSizeBytes: pointer.Int64(0), MaxBackups: pointer.Int(-1)}}, 3},
+		{"invalid configmap sink", &aapi.Config{Sink: aapi.SinkTypeConfigMap, ConfigMap: &aapi.ConfigMapSink{Name: pointer.String(""), MaxRecords: pointer.Int(0)}}, 2},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			FillDefaultValues(entry.config)
+			v := new(util.Validator)
+			Validate(v, "audit", entry.config)
+			if entry.expectedErrorCount == 0 {
+				g.Expect(v.Error).ToNot(HaveOccurred())
+				return
+			}
+			var merr *multierr.Error
+			g.Expect(errors.As(v.Error, &merr)).To(BeTrue())
+			g.Expect(merr.Errors).To(HaveLen(entry.expectedErrorCount))
+		})
+	}
+}
+
+func createTestSink(g *WithT, config *aapi.Config, fakeClient client.Client) Sink {
+	FillDefaultValues(config)
+	s, err := NewSink(config, fakeClient, fakeClient, "prober")
+	g.Expect(err).ToNot(HaveOccurred())
+	return s
+}
+
+func readRecords(g *WithT, path string) []Record {
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		var record Record
+		g.Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"fmt"
+
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	multierr "github.com/hashicorp/go-multierror"
+)
+
+const (
+	// DefaultFileMaxSizeBytes is the default size beyond which the audit log file is rotated.
+	DefaultFileMaxSizeBytes int64 = 10 * 1024 * 1024
+	// DefaultFileMaxBackups is the default number of rotated audit log files which are retained.
+	DefaultFileMaxBackups = 3
+	// DefaultConfigMapName is the default name of the ConfigMap to which audit records are written.
+	DefaultConfigMapName = "dependency-watchdog-audit-log"
+	// DefaultConfigMapMaxRecords is the default maximum number of records retained in the ConfigMap.
+	DefaultConfigMapMaxRecords = 100
+)
+
+// FillDefaultValues sets the default values for all optional fields of the audit configuration which have not been specified.
+func FillDefaultValues(c *aapi.Config) {
+	if c.File != nil {
+		if c.File.MaxSizeBytes == nil {
+			c.File.MaxSizeBytes = new(int64)
+			*c.File.MaxSizeBytes = DefaultFileMaxSizeBytes
+		}
+		if c.File.MaxBackups == nil {
+			c.File.MaxBackups = new(int)
+			*c.File.MaxBackups = DefaultFileMaxBackups
+		}
+	}
+	if c.Sink == aapi.SinkTypeConfigMap && c.ConfigMap == nil {
+		c.ConfigMap = new(aapi.ConfigMapSink)
+	}
+	if c.ConfigMap != nil {
+		if c.ConfigMap.Name == nil {
+			c.ConfigMap.Name = new(string)
+			*c.ConfigMap.Name = DefaultConfigMapName
+		}
+		if c.ConfigMap.MaxRecords == nil {
+			c.ConfigMap.MaxRecords = new(int)
+			*c.ConfigMap.MaxRecords = DefaultConfigMapMaxRecords
+		}
+	}
+}
+
+// Validate validates the audit configuration, whose default values must have been filled, and appends all validation errors
+// to the given validator. key is the key of the audit configuration within the enclosing configuration.
+func Validate(v *util.Validator, key string, c *aapi.Config) {
+	if !v.MustBeOneOf(key+".sink", string(c.Sink), string(aapi.SinkTypeFile), string(aapi.SinkTypeConfigMap)) {
+		return
+	}
+	switch c.Sink {
+	case aapi.SinkTypeFile:
+		if v.MustNotBeNil(key+".file", c.File) {
+			v.MustNotBeEmpty(key+".file.path", c.File.Path)
+			if *c.File.MaxSizeBytes <= 0 {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("value %d for key %s.file.maxSizeBytes must be greater than zero", *c.File.MaxSizeBytes, key))
+			}
+			if *c.File.MaxBackups < 0 {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("value %d for key %s.file.maxBackups must not be negative", *c.File.MaxBackups, key))
+			}
+		}
+	case aapi.SinkTypeConfigMap:
+		v.MustNotBeEmpty(key+".configMap.name", *c.ConfigMap.Name)
+		v.MustBePositive(key+".configMap.maxRecords", *c.ConfigMap.MaxRecords)
+	}
+}
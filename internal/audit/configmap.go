@@ -0,0 +1,83 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordsKey is the key of the ConfigMap data which holds the records as JSON lines, oldest first.
+const recordsKey = "records.jsonl"
+
+// configMapWriter appends records to a ConfigMap in the namespace of the record which retains the latest maxRecords records.
+type configMapWriter struct {
+	reader     client.Reader
+	writer     client.Writer
+	name       string
+	maxRecords int
+}
+
+func newConfigMapWriter(reader client.Reader, writer client.Writer, name string, maxRecords int) *configMapWriter {
+	return &configMapWriter{
+		reader:     reader,
+		writer:     writer,
+		name:       name,
+		maxRecords: maxRecords,
+	}
+}
+
+func (w *configMapWriter) write(ctx context.Context, namespace string, line []byte) error {
+	// records of the same namespace can be written concurrently, e.g. by resources which are scaled at the same level.
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		cm := &corev1.ConfigMap{}
+		err := w.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: w.name}, cm)
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: w.name},
+				Data:       map[string]string{recordsKey: string(line)},
+			}
+			return w.writer.Create(ctx, cm)
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[recordsKey] = appendRecord(cm.Data[recordsKey], string(line), w.maxRecords)
+		return w.writer.Update(ctx, cm)
+	})
+}
+
+// appendRecord appends the line to the given JSON lines and drops the oldest lines so that at most maxRecords are retained.
+func appendRecord(records string, line string, maxRecords int) string {
+	lines := strings.SplitAfter(records+line, "\n")
+	// SplitAfter yields an empty last element as every line is terminated by a new line.
+	lines = lines[:len(lines)-1]
+	if len(lines) > maxRecords {
+		lines = lines[len(lines)-maxRecords:]
+	}
+	return strings.Join(lines, "")
+}
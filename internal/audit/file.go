@@ -0,0 +1,104 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// fileWriter appends records as JSON lines to a file. Once a record would grow the file beyond maxSizeBytes, the file is
+// rotated: <path> is renamed to <path>.1, <path>.1 to <path>.2 and so on, retaining at most maxBackups rotated files.
+type fileWriter struct {
+	sync.Mutex
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	file         *os.File
+	size         int64
+}
+
+func newFileWriter(path string, maxSizeBytes int64, maxBackups int) *fileWriter {
+	return &fileWriter{
+		path:         path,
+		maxSizeBytes: maxSizeBytes,
+		maxBackups:   maxBackups,
+	}
+}
+
+func (w *fileWriter) write(_ context.Context, _ string, line []byte) error {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(line)) > w.maxSizeBytes {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	return err
+}
+
+func (w *fileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *fileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if w.maxBackups == 0 {
+		if err := os.Remove(w.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return w.open()
+	}
+	for i := w.maxBackups; i > 0; i-- {
+		src := w.backupPath(i - 1)
+		if err := os.Rename(src, w.backupPath(i)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return w.open()
+}
+
+// backupPath returns the path of the i-th rotated file, the 0-th being the file which is currently written to.
+func (w *fileWriter) backupPath(i int) string {
+	if i == 0 {
+		return w.path
+	}
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
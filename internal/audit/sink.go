@@ -0,0 +1,152 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Action is the action of DWD which is captured by an audit record.
+type Action string
+
+const (
+	// ActionScaleUp captures that the replicas of a dependent resource have been restored by the prober.
+	ActionScaleUp Action = "ScaleUp"
+	// ActionScaleDown captures that a dependent resource has been scaled down by the prober.
+	ActionScaleDown Action = "ScaleDown"
+	// ActionDeletePod captures that a pod in CrashLoopBackOff has been deleted by the weeder.
+	ActionDeletePod Action = "DeletePod"
+	// ActionEvictPod captures that a pod in CrashLoopBackOff has been evicted by the weeder.
+	ActionEvictPod Action = "EvictPod"
+	// ActionRestartWorkload captures that a rollout restart of the workload owning a dependant pod has been triggered by the weeder.
+	ActionRestartWorkload Action = "RestartWorkload"
+)
+
+// Result is the result of the action captured by an audit record.
+type Result string
+
+const (
+	// ResultSucceeded indicates that the action has succeeded.
+	ResultSucceeded Result = "Succeeded"
+	// ResultFailed indicates that the action has failed.
+	ResultFailed Result = "Failed"
+)
+
+// podNameEnvVar is the environment variable which is expected to carry the name of the pod in which DWD runs.
+const podNameEnvVar = "POD_NAME"
+
+// Record is a single entry of the audit log.
+type Record struct {
+	// Time is the time at which the action has been taken.
+	Time time.Time `json:"time"`
+	// Actor is the DWD component which has taken the action, i.e. prober or weeder.
+	Actor string `json:"actor"`
+	// Replica is the name of the pod of the DWD component which has taken the action.
+	Replica string `json:"replica"`
+	// ShootNamespace is the namespace of the shoot control plane in the seed.
+	ShootNamespace string `json:"shootNamespace"`
+	// Action is the action which has been taken.
+	Action Action `json:"action"`
+	// Resource is the resource on which the action has been taken in the form <kind>/<name>.
+	Resource string `json:"resource"`
+	// ReplicasBefore are the spec replicas of a dependent resource prior to scaling.
+	ReplicasBefore *int32 `json:"replicasBefore,omitempty"`
+	// ReplicasAfter are the spec replicas of a dependent resource that have been set by scaling.
+	ReplicasAfter *int32 `json:"replicasAfter,omitempty"`
+	// ProbeState is the state of the probe which has triggered scaling. It is not set if scaling has not been triggered by a
+	// probe, e.g. if scaled down resources are restored.
+	ProbeState *ProbeState `json:"probeState,omitempty"`
+	// Service is the name of the service whose recovery has triggered the deletion of a pod.
+	Service string `json:"service,omitempty"`
+	// Result is the result of the action.
+	Result Result `json:"result"`
+	// Error is the error encountered while taking the action, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// ProbeState captures the state of the internal and external probe at the time an action has been taken.
+type ProbeState struct {
+	InternalHealthy    bool `json:"internalHealthy"`
+	InternalErrorCount int  `json:"internalErrorCount"`
+	ExternalHealthy    bool `json:"externalHealthy"`
+	ExternalErrorCount int  `json:"externalErrorCount"`
+}
+
+// Sink is an append-only audit log.
+type Sink interface {
+	// Write appends the record to the audit log. Actor, replica and, if not set, the time of the record are filled by the sink.
+	Write(ctx context.Context, record Record) error
+}
+
+// recordWriter persists a single serialized record of the given namespace.
+type recordWriter interface {
+	write(ctx context.Context, namespace string, line []byte) error
+}
+
+type sink struct {
+	actor   string
+	replica string
+	writer  recordWriter
+}
+
+// NewSink creates a Sink for the given configuration whose default values must have been filled. actor is the name of the DWD
+// component which writes the records. reader and writer are only used by the ConfigMap sink, reader should not be backed by
+// a cache so that ConfigMaps are not cached cluster-wide.
+func NewSink(config *aapi.Config, reader client.Reader, writer client.Writer, actor string) (Sink, error) {
+	var rw recordWriter
+	switch config.Sink {
+	case aapi.SinkTypeFile:
+		rw = newFileWriter(config.File.Path, *config.File.MaxSizeBytes, *config.File.MaxBackups)
+	case aapi.SinkTypeConfigMap:
+		rw = newConfigMapWriter(reader, writer, *config.ConfigMap.Name, *config.ConfigMap.MaxRecords)
+	default:
+		return nil, fmt.Errorf("unsupported audit sink %q", config.Sink)
+	}
+	return &sink{
+		actor:   actor,
+		replica: replicaName(),
+		writer:  rw,
+	}, nil
+}
+
+func (s *sink) Write(ctx context.Context, record Record) error {
+	record.Actor = s.actor
+	record.Replica = s.replica
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.writer.write(ctx, record.ShootNamespace, append(line, '\n'))
+}
+
+// replicaName returns the name of the pod in which DWD runs, falling back to the hostname which matches the pod name unless
+// it has been overridden.
+func replicaName() string {
+	if podName := os.Getenv(podNameEnvVar); podName != "" {
+		return podName
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
@@ -7,7 +7,9 @@ package scaler
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	scaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,24 +37,38 @@ func (m *MockScaler) EXPECT() *MockScalerMockRecorder {
 }
 
 // ScaleDown mocks base method.
-func (m *MockScaler) ScaleDown(arg0 context.Context) error {
+func (m *MockScaler) ScaleDown(arg0 context.Context, arg1 time.Duration) scaler.Result {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ScaleDown", arg0)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "ScaleDown", arg0, arg1)
+	ret0, _ := ret[0].(scaler.Result)
 	return ret0
 }
 
 // ScaleDown indicates an expected call of ScaleDown.
-func (mr *MockScalerMockRecorder) ScaleDown(arg0 interface{}) *gomock.Call {
+func (mr *MockScalerMockRecorder) ScaleDown(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleDown", reflect.TypeOf((*MockScaler)(nil).ScaleDown), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleDown", reflect.TypeOf((*MockScaler)(nil).ScaleDown), arg0, arg1)
+}
+
+// ScaleDownResources mocks base method.
+func (m *MockScaler) ScaleDownResources(arg0 context.Context, arg1 []string) scaler.Result {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScaleDownResources", arg0, arg1)
+	ret0, _ := ret[0].(scaler.Result)
+	return ret0
+}
+
+// ScaleDownResources indicates an expected call of ScaleDownResources.
+func (mr *MockScalerMockRecorder) ScaleDownResources(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleDownResources", reflect.TypeOf((*MockScaler)(nil).ScaleDownResources), arg0, arg1)
 }
 
 // ScaleUp mocks base method.
-func (m *MockScaler) ScaleUp(arg0 context.Context) error {
+func (m *MockScaler) ScaleUp(arg0 context.Context) scaler.Result {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ScaleUp", arg0)
-	ret0, _ := ret[0].(error)
+	ret0, _ := ret[0].(scaler.Result)
 	return ret0
 }
 
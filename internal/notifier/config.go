@@ -0,0 +1,101 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	multierr "github.com/hashicorp/go-multierror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultQueueSize is the default maximum number of notifications which are queued for delivery per webhook.
+	DefaultQueueSize = 100
+	// DefaultMaxAttempts is the default maximum number of attempts to deliver a notification to a webhook.
+	DefaultMaxAttempts = 3
+	// DefaultInitialBackOff is the default duration to back off after the first failed delivery attempt.
+	DefaultInitialBackOff = 1 * time.Second
+	// DefaultMaxBackOff is the default maximum duration to back off between delivery attempts.
+	DefaultMaxBackOff = 30 * time.Second
+	// DefaultTimeout is the default timeout of a single delivery attempt.
+	DefaultTimeout = 10 * time.Second
+)
+
+// FillDefaultValues sets the default values for all optional fields of the notifier configuration which have not been specified.
+func FillDefaultValues(c *napi.Config) {
+	if c.QueueSize == nil {
+		c.QueueSize = new(int)
+		*c.QueueSize = DefaultQueueSize
+	}
+	if c.MaxAttempts == nil {
+		c.MaxAttempts = new(int)
+		*c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.InitialBackOff == nil {
+		c.InitialBackOff = &metav1.Duration{
+			Duration: DefaultInitialBackOff,
+		}
+	}
+	if c.MaxBackOff == nil {
+		c.MaxBackOff = &metav1.Duration{
+			Duration: DefaultMaxBackOff,
+		}
+	}
+	if c.Timeout == nil {
+		c.Timeout = &metav1.Duration{
+			Duration: DefaultTimeout,
+		}
+	}
+}
+
+// Validate validates the notifier configuration, whose default values must have been filled, and appends all validation
+// errors to the given validator. key is the key of the notifier configuration within the enclosing configuration.
+func Validate(v *util.Validator, key string, c *napi.Config) {
+	v.MustNotBeEmpty(key+".webhooks", c.Webhooks)
+	v.MustBePositive(key+".queueSize", *c.QueueSize)
+	v.MustBePositive(key+".maxAttempts", *c.MaxAttempts)
+	v.MustBePositiveDuration(key+".initialBackOff", c.InitialBackOff.Duration)
+	v.MustBePositiveDuration(key+".maxBackOff", c.MaxBackOff.Duration)
+	v.MustBePositiveDuration(key+".timeout", c.Timeout.Duration)
+	allowedEventTypes := make([]string, 0, len(napi.AllEventTypes))
+	for _, eventType := range napi.AllEventTypes {
+		allowedEventTypes = append(allowedEventTypes, string(eventType))
+	}
+	names := make(map[string]bool, len(c.Webhooks))
+	for i, webhook := range c.Webhooks {
+		webhookKey := fmt.Sprintf("%s.webhooks[%d]", key, i)
+		if v.MustNotBeEmpty(webhookKey+".name", webhook.Name) {
+			if names[webhook.Name] {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("%s.name %s is not unique", webhookKey, webhook.Name))
+			}
+			names[webhook.Name] = true
+		}
+		webhookURL, err := url.Parse(webhook.URL)
+		if err != nil || (webhookURL.Scheme != "https" && webhookURL.Scheme != "http") || webhookURL.Host == "" {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("%s.url %s is not a valid http(s) URL", webhookKey, webhook.URL))
+		}
+		if webhook.SigningKeyFile != nil {
+			v.MustNotBeEmpty(webhookKey+".signingKeyFile", *webhook.SigningKeyFile)
+		}
+		for _, eventType := range webhook.Events {
+			v.MustBeOneOf(webhookKey+".events", string(eventType), allowedEventTypes...)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/go-logr/logr"
+)
+
+const (
+	// SignatureHeader is the header in which the HMAC-SHA256 signature of the payload is sent.
+	SignatureHeader = "X-DWD-Signature"
+	// EventHeader is the header in which the event type of the payload is sent.
+	EventHeader = "X-DWD-Event"
+	// signaturePrefix is the prefix of the value of the SignatureHeader which identifies the hash function.
+	signaturePrefix = "sha256="
+)
+
+// Notification is the JSON payload which is POSTed to the webhooks.
+type Notification struct {
+	// Event is the type of the transition.
+	Event napi.EventType `json:"event"`
+	// Time is the time at which the transition has been observed.
+	Time time.Time `json:"time"`
+	// ShootNamespace is the namespace of the shoot control plane in the seed.
+	ShootNamespace string `json:"shootNamespace"`
+	// Resources are the resources affected by the transition in the form <kind>/<name>.
+	Resources []string `json:"resources,omitempty"`
+	// Errors are the errors which have caused the transition or have been encountered during it.
+	Errors []string `json:"errors,omitempty"`
+	// Message is a human-readable description of the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// Notifier notifies webhooks about transitions observed by DWD.
+type Notifier interface {
+	// Notify enqueues the notification for delivery to all webhooks which are interested in its event. It never blocks,
+	// notifications which do not fit into the queue of a webhook are dropped.
+	Notify(notification Notification)
+}
+
+// WebhookNotifier delivers notifications to the configured webhooks. Each webhook has its own bounded queue which is
+// drained by a dedicated go-routine once the notifier has been started. A slow webhook therefore neither blocks the
+// caller nor delays the delivery to other webhooks.
+type WebhookNotifier struct {
+	webhooks []*webhook
+	logger   logr.Logger
+}
+
+// webhook captures the delivery state of a single configured webhook.
+type webhook struct {
+	config      napi.Webhook
+	signingKey  []byte
+	events      map[napi.EventType]bool
+	queue       chan Notification
+	httpClient  *http.Client
+	maxAttempts int
+	backOffFn   util.BackOffFn
+	logger      logr.Logger
+}
+
+// statusError is returned if a webhook has responded with a non 2xx status code.
+type statusError struct {
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook responded with status code %d", e.statusCode)
+}
+
+// NewWebhookNotifier creates a WebhookNotifier for the given configuration whose default values must have been filled.
+// It returns an error if the signing key of a webhook cannot be read.
+func NewWebhookNotifier(config *napi.Config, logger logr.Logger) (*WebhookNotifier, error) {
+	nLogger := logger.WithName("notifier")
+	webhooks := make([]*webhook, 0, len(config.Webhooks))
+	for _, whConfig := range config.Webhooks {
+		wh := &webhook{
+			config:      whConfig,
+			queue:       make(chan Notification, *config.QueueSize),
+			httpClient:  &http.Client{Timeout: config.Timeout.Duration},
+			maxAttempts: *config.MaxAttempts,
+			backOffFn:   util.ExponentialBackOff(config.InitialBackOff.Duration, config.MaxBackOff.Duration),
+			logger:      nLogger.WithValues("webhook", whConfig.Name),
+		}
+		if whConfig.SigningKeyFile != nil {
+			signingKey, err := os.ReadFile(*whConfig.SigningKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read signing key of webhook %s: %w", whConfig.Name, err)
+			}
+			wh.signingKey = bytes.TrimSpace(signingKey)
+		}
+		if len(whConfig.Events) > 0 {
+			wh.events = make(map[napi.EventType]bool, len(whConfig.Events))
+			for _, eventType := range whConfig.Events {
+				wh.events[eventType] = true
+			}
+		}
+		webhooks = append(webhooks, wh)
+	}
+	return &WebhookNotifier{webhooks: webhooks, logger: nLogger}, nil
+}
+
+// Notify enqueues the notification for delivery to all webhooks whose event filter accepts it.
+func (n *WebhookNotifier) Notify(notification Notification) {
+	if notification.Time.IsZero() {
+		notification.Time = time.Now().UTC()
+	}
+	for _, wh := range n.webhooks {
+		if wh.events != nil && !wh.events[notification.Event] {
+			continue
+		}
+		select {
+		case wh.queue <- notification:
+		default:
+			wh.logger.Error(errors.New("queue is full"), "Dropping notification", "event", notification.Event, "shootNamespace", notification.ShootNamespace)
+		}
+	}
+}
+
+// Start delivers the queued notifications until the context is cancelled. It implements manager.Runnable.
+func (n *WebhookNotifier) Start(ctx context.Context) error {
+	for _, wh := range n.webhooks {
+		go wh.run(ctx)
+	}
+	n.logger.Info("Started notifier", "webhooks", len(n.webhooks))
+	<-ctx.Done()
+	return nil
+}
+
+func (wh *webhook) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-wh.queue:
+			if err := wh.deliver(ctx, notification); err != nil {
+				wh.logger.Error(err, "Failed to deliver notification", "event", notification.Event, "shootNamespace", notification.ShootNamespace)
+			}
+		}
+	}
+}
+
+// deliver POSTs the notification to the webhook. Network errors and responses with status code 429 or 5xx are retried
+// with an exponential back off, all other responses with a non 2xx status code are not.
+func (wh *webhook) deliver(ctx context.Context, notification Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	operation := fmt.Sprintf("Delivering %s notification to webhook %s", notification.Event, wh.config.Name)
+	result := util.RetryWithBackOff(ctx, wh.logger, operation, func() (interface{}, error) {
+		return nil, wh.post(ctx, notification.Event, payload)
+	}, wh.maxAttempts, wh.backOffFn, canRetryDelivery)
+	return result.Err
+}
+
+func (wh *webhook) post(ctx context.Context, eventType napi.EventType, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	if wh.signingKey != nil {
+		req.Header.Set(SignatureHeader, Sign(wh.signingKey, payload))
+	}
+	resp, err := wh.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+func canRetryDelivery(err error) bool {
+	var sErr *statusError
+	if errors.As(err, &sErr) {
+		return sErr.statusCode == http.StatusTooManyRequests || sErr.statusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// Sign returns the value of the SignatureHeader for the given payload which allows a webhook to verify that the payload
+// has been sent by DWD and has not been tampered with.
+func Sign(key []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/go-logr/logr"
+	multierr "github.com/hashicorp/go-multierror"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+// receivedRequest captures a request received by a testReceiver.
+type receivedRequest struct {
+	header       http.Header
+	payload      []byte
+	notification Notification
+}
+
+// testReceiver is a local webhook which responds with the configured status codes in order and with 200 once they are exhausted.
+type testReceiver struct {
+	sync.Mutex
+	server      *httptest.Server
+	statusCodes []int
+	requests    []receivedRequest
+}
+
+func newTestReceiver(statusCodes ...int) *testReceiver {
+	r := &testReceiver{statusCodes: statusCodes}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		payload, _ := io.ReadAll(req.Body)
+		var notification Notification
+		_ = json.Unmarshal(payload, &notification)
+		r.Lock()
+		defer r.Unlock()
+		r.requests = append(r.requests, receivedRequest{header: req.Header, payload: payload, notification: notification})
+		statusCode := http.StatusOK
+		if len(r.statusCodes) > 0 {
+			statusCode, r.statusCodes = r.statusCodes[0], r.statusCodes[1:]
+		}
+		w.WriteHeader(statusCode)
+	}))
+	return r
+}
+
+func (r *testReceiver) getRequests() []receivedRequest {
+	r.Lock()
+	defer r.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+func TestNotificationIsDeliveredWithSignature(t *testing.T) {
+	g := NewWithT(t)
+	receiver := newTestReceiver()
+	defer receiver.server.Close()
+	keyFile := filepath.Join(t.TempDir(), "key")
+	g.Expect(os.WriteFile(keyFile, []byte("bingo\n"), 0600)).To(Succeed())
+
+	n := startTestNotifier(t, g, napi.Webhook{Name: "on-call", URL: receiver.server.URL, SigningKeyFile: pointer.String(keyFile)})
+	n.Notify(Notification{Event: napi.EventTypeScaleDownFailed, ShootNamespace: "shoot--bingo", Resources: []string{"Deployment/kube-controller-manager"}, Errors: []string{"timed out"}})
+
+	g.Eventually(receiver.getRequests).Should(HaveLen(1))
+	req := receiver.getRequests()[0]
+	g.Expect(req.header.Get(EventHeader)).To(Equal(string(napi.EventTypeScaleDownFailed)))
+	g.Expect(req.header.Get(SignatureHeader)).To(Equal(Sign([]byte("bingo"), req.payload)))
+	g.Expect(req.notification.ShootNamespace).To(Equal("shoot--bingo"))
+	g.Expect(req.notification.Resources).To(ConsistOf("Deployment/kube-controller-manager"))
+	g.Expect(req.notification.Errors).To(ConsistOf("timed out"))
+	g.Expect(req.notification.Time.IsZero()).To(BeFalse())
+}
+
+func TestNotificationsAreFilteredPerWebhook(t *testing.T) {
+	g := NewWithT(t)
+	allEvents := newTestReceiver()
+	defer allEvents.server.Close()
+	scaleEvents := newTestReceiver()
+	defer scaleEvents.server.Close()
+
+	n := startTestNotifier(t, g,
+		napi.Webhook{Name: "all", URL: allEvents.server.URL},
+		napi.Webhook{Name: "scale", URL: scaleEvents.server.URL, Events: []napi.EventType{napi.EventTypeScaleDownCompleted, napi.EventTypeScaleUpCompleted}})
+	n.Notify(Notification{Event: napi.EventTypePodDeleted, ShootNamespace: "shoot--bingo"})
+	n.Notify(Notification{Event: napi.EventTypeScaleUpCompleted, ShootNamespace: "shoot--bingo"})
+
+	g.Eventually(allEvents.getRequests).Should(HaveLen(2))
+	g.Eventually(scaleEvents.getRequests).Should(HaveLen(1))
+	g.Consistently(scaleEvents.getRequests, 100*time.Millisecond).Should(HaveLen(1))
+	g.Expect(scaleEvents.getRequests()[0].notification.Event).To(Equal(napi.EventTypeScaleUpCompleted))
+}
+
+func TestDeliveryIsRetried(t *testing.T) {
+	table := []struct {
+		description          string
+		statusCodes          []int
+		expectedRequestCount int
+	}{
+		{"server errors and throttling are retried", []int{http.StatusInternalServerError, http.StatusTooManyRequests}, 3},
+		{"delivery is given up after max attempts", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, 3},
+		{"client errors are not retried", []int{http.StatusBadRequest}, 1},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			receiver := newTestReceiver(entry.statusCodes...)
+			defer receiver.server.Close()
+			n := startTestNotifier(t, g, napi.Webhook{Name: "on-call", URL: receiver.server.URL})
+			n.Notify(Notification{Event: napi.EventTypeExternalProbeUnhealthy, ShootNamespace: "shoot--bingo"})
+
+			g.Eventually(receiver.getRequests).Should(HaveLen(entry.expectedRequestCount))
+			g.Consistently(receiver.getRequests, 100*time.Millisecond).Should(HaveLen(entry.expectedRequestCount))
+		})
+	}
+}
+
+func TestNotificationsAreDroppedOnceQueueIsFull(t *testing.T) {
+	g := NewWithT(t)
+	config := createTestConfig(napi.Webhook{Name: "on-call", URL: "http://localhost"})
+	config.QueueSize = pointer.Int(2)
+	n, err := NewWebhookNotifier(config, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	for i := 0; i < 3; i++ {
+		n.Notify(Notification{Event: napi.EventTypePodDeleted, ShootNamespace: "shoot--bingo"})
+	}
+	g.Expect(n.webhooks[0].queue).To(HaveLen(2))
+}
+
+func TestNewWebhookNotifierFailsIfSigningKeyCannotBeRead(t *testing.T) {
+	g := NewWithT(t)
+	_, err := NewWebhookNotifier(createTestConfig(napi.Webhook{Name: "on-call", URL: "http://localhost", SigningKeyFile: pointer.String(filepath.Join(t.TempDir(), "missing"))}), logr.Discard())
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestValidate(t *testing.T) {
+	table := []struct {
+		description        string
+		config             *napi.Config
+		expectedErrorCount int
+	}{
+		{"valid configuration", createTestConfig(napi.Webhook{Name: "on-call", URL: "https://hooks.example.com", Events: []napi.EventType{napi.EventTypeScaleDownFailed}}), 0},
+		{"no webhooks", createTestConfig(), 1},
+		{"invalid webhooks", createTestConfig(
+			napi.Webhook{Name: "on-call", URL: "hooks.example.com", Events: []napi.EventType{"Bingo"}},
+			napi.Webhook{Name: "on-call", URL: "https://hooks.example.com", SigningKeyFile: pointer.String("")},
+		), 4},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			v := new(util.Validator)
+			Validate(v, "notifier", entry.config)
+			if entry.expectedErrorCount == 0 {
+				g.Expect(v.Error).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(v.Error).To(HaveOccurred())
+			g.Expect(v.Error.(*multierr.Error).Errors).To(HaveLen(entry.expectedErrorCount))
+		})
+	}
+}
+
+func TestFillDefaultValues(t *testing.T) {
+	g := NewWithT(t)
+	config := createTestConfig()
+	g.Expect(*config.QueueSize).To(Equal(DefaultQueueSize))
+	g.Expect(*config.MaxAttempts).To(Equal(DefaultMaxAttempts))
+	g.Expect(config.InitialBackOff.Duration).To(Equal(DefaultInitialBackOff))
+	g.Expect(config.MaxBackOff.Duration).To(Equal(DefaultMaxBackOff))
+	g.Expect(config.Timeout.Duration).To(Equal(DefaultTimeout))
+}
+
+func createTestConfig(webhooks ...napi.Webhook) *napi.Config {
+	config := &napi.Config{Webhooks: webhooks}
+	FillDefaultValues(config)
+	return config
+}
+
+func startTestNotifier(t *testing.T, g *WithT, webhooks ...napi.Webhook) *WebhookNotifier {
+	config := createTestConfig(webhooks...)
+	config.InitialBackOff = &metav1.Duration{Duration: time.Millisecond}
+	config.MaxBackOff = &metav1.Duration{Duration: 5 * time.Millisecond}
+	n, err := NewWebhookNotifier(config, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	ctx, cancelFn := context.WithCancel(context.Background())
+	t.Cleanup(cancelFn)
+	go func() {
+		_ = n.Start(ctx)
+	}()
+	return n
+}
@@ -15,10 +15,16 @@
 package prober
 
 import (
+	"fmt"
+	"net/url"
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
 	"github.com/gardener/dependency-watchdog/internal/util"
+	multierr "github.com/hashicorp/go-multierror"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -42,6 +48,42 @@ const (
 	DefaultBackoffJitterFactor = 0.2
 	// DefaultScaleUpdateTimeout is the default duration representing a timeout for the scale operation to complete.
 	DefaultScaleUpdateTimeout = 30 * time.Second
+	// DefaultScaleDownFailurePolicy is the default compensation policy applied when a scale-down flow fails.
+	DefaultScaleDownFailurePolicy = papi.ScaleDownFailurePolicyRetry
+	// DefaultExternalProbePolicy is the default policy with which the health of the external probe is computed from the health of its targets.
+	DefaultExternalProbePolicy = papi.ExternalProbePolicyAny
+	// DefaultResourceCheckTimeout is the default duration to wait for a dependent resource to reach its minimum target replicas once it has been scaled.
+	DefaultResourceCheckTimeout = 5 * time.Second
+	// DefaultResourceCheckInterval is the default interval with which a dependent resource is checked to have reached its minimum target replicas.
+	DefaultResourceCheckInterval = 1 * time.Second
+	// DefaultScaleMaxAttempts is the default maximum number of attempts to scale a dependent resource.
+	DefaultScaleMaxAttempts = 3
+	// DefaultScaleInitialBackOff is the default back off duration after the first failed attempt to scale a dependent resource.
+	DefaultScaleInitialBackOff = 100 * time.Millisecond
+	// DefaultScaleMaxBackOff is the default cap on the back off duration between two attempts to scale a dependent resource.
+	DefaultScaleMaxBackOff = 5 * time.Second
+	// DefaultRestorationEnabled determines if resources which have been left scaled down by DWD are restored by default.
+	DefaultRestorationEnabled = true
+	// DefaultRestorationGracePeriod is the default duration for which a shoot control namespace has to be eligible for restoration before its resources are restored.
+	DefaultRestorationGracePeriod = 5 * time.Minute
+	// DefaultRestorationInterval is the default interval with which shoot control namespaces are checked for resources that have been left scaled down.
+	DefaultRestorationInterval = 1 * time.Minute
+	// DefaultAdaptiveProbeIntervalEnabled determines if the probe interval is adapted by default.
+	DefaultAdaptiveProbeIntervalEnabled = false
+	// DefaultAdaptiveProbeMinInterval is the default interval with which a probe is run once the external probe has failed.
+	DefaultAdaptiveProbeMinInterval = 2 * time.Second
+	// DefaultAdaptiveProbeMaxBackoffDuration is the default maximum duration for which the internal probe is backed off while it is unhealthy.
+	DefaultAdaptiveProbeMaxBackoffDuration = 5 * time.Minute
+	// DefaultVerdictAnnotationEnabled determines if the verdict of the prober is published on the Cluster resource by default.
+	DefaultVerdictAnnotationEnabled = false
+	// DefaultVerdictAnnotationMinUpdateInterval is the default minimum duration between two updates of the verdict annotation.
+	DefaultVerdictAnnotationMinUpdateInterval = 30 * time.Second
+	// DefaultProbeStatusResourceEnabled determines if the ProbeStatus resource is maintained by default.
+	DefaultProbeStatusResourceEnabled = false
+	// DefaultProbeStatusResourceMinUpdateInterval is the default minimum duration between two updates of the ProbeStatus resource.
+	DefaultProbeStatusResourceMinUpdateInterval = 30 * time.Second
+	// DefaultProbeStatusResourceScaleHistoryLimit is the default number of scale flows which are kept in the scale history of the ProbeStatus resource.
+	DefaultProbeStatusResourceScaleHistoryLimit = 10
 )
 
 // LoadConfig reads the prober configuration from a file, unmarshalls it, fills in the default values and
@@ -63,12 +105,47 @@ func validate(c *papi.Config, scheme *runtime.Scheme) error {
 	v := new(util.Validator)
 	// Check the mandatory config parameters for which a default will not be set
 	v.MustNotBeEmpty("InternalKubeConfigSecretName", c.InternalKubeConfigSecretName)
-	v.MustNotBeEmpty("ExternalKubeConfigSecretName", c.ExternalKubeConfigSecretName)
+	if isExternalKubeConfigSecretNameRequired(c.ExternalProbeTargets) {
+		v.MustNotBeEmpty("ExternalKubeConfigSecretName", c.ExternalKubeConfigSecretName)
+	}
 	v.MustNotBeEmpty("ScaleResourceInfos", c.DependentResourceInfos)
 	for _, resInfo := range c.DependentResourceInfos {
 		v.ResourceRefMustBeValid(resInfo.Ref, scheme)
-		v.MustNotBeNil("scaleUp", resInfo.ScaleUpInfo)
-		v.MustNotBeNil("scaleDown", resInfo.ScaleDownInfo)
+		if v.MustNotBeNil("scaleUp", resInfo.ScaleUpInfo) {
+			validateRetryPolicy(v, "scaleUp", resInfo.ScaleUpInfo.RetryPolicy)
+			validateScaleCondition(v, "scaleUp", resInfo.ScaleUpInfo.Condition)
+		}
+		if v.MustNotBeNil("scaleDown", resInfo.ScaleDownInfo) {
+			validateRetryPolicy(v, "scaleDown", resInfo.ScaleDownInfo.RetryPolicy)
+			validateScaleCondition(v, "scaleDown", resInfo.ScaleDownInfo.Condition)
+		}
+	}
+	validateEscalationStages(v, c.EscalationStages, c.DependentResourceInfos)
+	validateDecisionMatrix(v, c.DecisionMatrix, c.DependentResourceInfos)
+	if c.MaxScaleDownDuration != nil {
+		v.MustBePositiveDuration("maxScaleDownDuration", c.MaxScaleDownDuration.Duration)
+	}
+	v.MustBeOneOf("scaleDownFailurePolicy", string(*c.ScaleDownFailurePolicy), string(papi.ScaleDownFailurePolicyRetry), string(papi.ScaleDownFailurePolicyRollback))
+	v.MustBeOneOf("externalProbePolicy", string(*c.ExternalProbePolicy), string(papi.ExternalProbePolicyAny), string(papi.ExternalProbePolicyAll), string(papi.ExternalProbePolicyQuorum))
+	validateExternalProbeTargets(v, c.ExternalProbeTargets)
+	if c.ExternalProbeProxyURL != nil {
+		validateProxyURL(v, "externalProbeProxyURL", *c.ExternalProbeProxyURL)
+	}
+	if *c.AdaptiveProbeInterval.Enabled {
+		validateAdaptiveProbeInterval(v, c.AdaptiveProbeInterval, c.ProbeInterval.Duration, c.InternalProbeFailureBackoffDuration.Duration)
+	}
+	if *c.VerdictAnnotation.Enabled {
+		v.MustBePositiveDuration("verdictAnnotation.minUpdateInterval", c.VerdictAnnotation.MinUpdateInterval.Duration)
+	}
+	if *c.ProbeStatusResource.Enabled {
+		v.MustBePositiveDuration("probeStatusResource.minUpdateInterval", c.ProbeStatusResource.MinUpdateInterval.Duration)
+		v.MustBePositive("probeStatusResource.scaleHistoryLimit", *c.ProbeStatusResource.ScaleHistoryLimit)
+	}
+	if c.Notifier != nil {
+		notifier.Validate(v, "notifier", c.Notifier)
+	}
+	if c.Audit != nil {
+		audit.Validate(v, "audit", c.Audit)
 	}
 	if v.Error != nil {
 		return v.Error
@@ -76,6 +153,157 @@ func validate(c *papi.Config, scheme *runtime.Scheme) error {
 	return nil
 }
 
+// isExternalKubeConfigSecretNameRequired checks if the ExternalKubeConfigSecretName is used to probe any of the external
+// probe targets, i.e. if no targets are configured or at least one of them does not have its own KubeConfigSecretName.
+func isExternalKubeConfigSecretNameRequired(targets []papi.ExternalProbeTarget) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	for _, target := range targets {
+		if target.KubeConfigSecretName == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func validateExternalProbeTargets(v *util.Validator, targets []papi.ExternalProbeTarget) {
+	names := make(map[string]bool, len(targets))
+	for i, target := range targets {
+		targetKey := fmt.Sprintf("externalProbeTargets[%d]", i)
+		if v.MustNotBeEmpty(targetKey+".name", target.Name) {
+			if names[target.Name] {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("%s.name %s is not unique", targetKey, target.Name))
+			}
+			names[target.Name] = true
+		}
+		if target.KubeConfigSecretName != nil {
+			v.MustNotBeEmpty(targetKey+".kubeConfigSecretName", *target.KubeConfigSecretName)
+		}
+		if target.ServerURL != nil {
+			serverURL, err := url.Parse(*target.ServerURL)
+			if err != nil || (serverURL.Scheme != "https" && serverURL.Scheme != "http") || serverURL.Host == "" {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("%s.serverURL %s is not a valid http(s) URL", targetKey, *target.ServerURL))
+			}
+		}
+		if target.ProxyURL != nil {
+			validateProxyURL(v, targetKey+".proxyURL", *target.ProxyURL)
+		}
+	}
+}
+
+func validateAdaptiveProbeInterval(v *util.Validator, adaptiveProbeInterval *papi.AdaptiveProbeInterval, probeInterval, internalProbeFailureBackoff time.Duration) {
+	minInterval := adaptiveProbeInterval.MinInterval.Duration
+	maxBackoff := adaptiveProbeInterval.MaxBackoffDuration.Duration
+	v.MustBePositiveDuration("adaptiveProbeInterval.minInterval", minInterval)
+	if minInterval > probeInterval {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("adaptiveProbeInterval.minInterval %s must not be greater than probeInterval %s", minInterval, probeInterval))
+	}
+	if maxBackoff < internalProbeFailureBackoff {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("adaptiveProbeInterval.maxBackoffDuration %s must not be less than internalProbeFailureBackoffDuration %s", maxBackoff, internalProbeFailureBackoff))
+	}
+}
+
+func validateProxyURL(v *util.Validator, key string, proxyURL string) {
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil || parsedURL.Host == "" {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("%s %s is not a valid URL", key, proxyURL))
+		return
+	}
+	v.MustBeOneOf(key+".scheme", parsedURL.Scheme, "http", "https", "socks5", "socks5h")
+}
+
+func validateEscalationStages(v *util.Validator, stages []papi.EscalationStage, resInfos []papi.DependentResourceInfo) {
+	stageByResourceName := make(map[string]int, len(resInfos))
+	for _, resInfo := range resInfos {
+		if resInfo.Ref != nil {
+			stageByResourceName[resInfo.Ref.Name] = -1
+		}
+	}
+	for i, stage := range stages {
+		stageKey := fmt.Sprintf("escalationStages[%d]", i)
+		if stage.After.Duration < 0 {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("%s.after must not be negative", stageKey))
+		}
+		if i > 0 && stage.After.Duration <= stages[i-1].After.Duration {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("%s.after must be greater than the after of the previous stage", stageKey))
+		}
+		v.MustNotBeEmpty(stageKey+".resourceNames", stage.ResourceNames)
+		for _, resourceName := range stage.ResourceNames {
+			previousStage, ok := stageByResourceName[resourceName]
+			if !ok {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("%s refers to resource %s which is not a dependent resource", stageKey, resourceName))
+				continue
+			}
+			if previousStage >= 0 {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("%s refers to resource %s which is already part of escalationStages[%d]", stageKey, resourceName, previousStage))
+				continue
+			}
+			stageByResourceName[resourceName] = i
+		}
+	}
+	for _, resInfo := range resInfos {
+		if resInfo.Ref != nil && stageByResourceName[resInfo.Ref.Name] < 0 {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("dependent resource %s is not part of any of the escalationStages", resInfo.Ref.Name))
+		}
+	}
+}
+
+func validateDecisionMatrix(v *util.Validator, decisions []papi.Decision, resInfos []papi.DependentResourceInfo) {
+	resourceNames := make(map[string]bool, len(resInfos))
+	for _, resInfo := range resInfos {
+		if resInfo.Ref != nil {
+			resourceNames[resInfo.Ref.Name] = true
+		}
+	}
+	allowedHealth := []string{string(papi.ProbeHealthHealthy), string(papi.ProbeHealthUnhealthy), string(papi.ProbeHealthUnknown)}
+	seen := make(map[papi.ProbeHealth]map[papi.ProbeHealth]bool)
+	for i, decision := range decisions {
+		decisionKey := fmt.Sprintf("decisionMatrix[%d]", i)
+		v.MustBeOneOf(decisionKey+".internal", string(decision.Internal), allowedHealth...)
+		v.MustBeOneOf(decisionKey+".external", string(decision.External), allowedHealth...)
+		v.MustBeOneOf(decisionKey+".action", string(decision.Action), string(papi.DecisionActionNone), string(papi.DecisionActionScaleDown), string(papi.DecisionActionScaleUp), string(papi.DecisionActionAlertOnly))
+		if seen[decision.Internal][decision.External] {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("%s is a duplicate decision for internal %s and external %s", decisionKey, decision.Internal, decision.External))
+		}
+		if seen[decision.Internal] == nil {
+			seen[decision.Internal] = make(map[papi.ProbeHealth]bool)
+		}
+		seen[decision.Internal][decision.External] = true
+		if len(decision.ResourceNames) > 0 && decision.Action != papi.DecisionActionScaleDown {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("%s.resourceNames is only allowed for action %s", decisionKey, papi.DecisionActionScaleDown))
+		}
+		for _, resourceName := range decision.ResourceNames {
+			if !resourceNames[resourceName] {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("%s refers to resource %s which is not a dependent resource", decisionKey, resourceName))
+			}
+		}
+	}
+}
+
+func validateScaleCondition(v *util.Validator, scaleInfoKey string, condition *string) {
+	if condition == nil {
+		return
+	}
+	if err := dwdScaler.ValidateScaleCondition(*condition); err != nil {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("%s.condition is invalid: %w", scaleInfoKey, err))
+	}
+}
+
+func validateRetryPolicy(v *util.Validator, scaleInfoKey string, retryPolicy *papi.RetryPolicy) {
+	v.MustBePositive(scaleInfoKey+".retryPolicy.maxAttempts", *retryPolicy.MaxAttempts)
+	initialBackOffValid := v.MustBePositiveDuration(scaleInfoKey+".retryPolicy.initialBackOff", retryPolicy.InitialBackOff.Duration)
+	maxBackOffValid := v.MustBePositiveDuration(scaleInfoKey+".retryPolicy.maxBackOff", retryPolicy.MaxBackOff.Duration)
+	if initialBackOffValid && maxBackOffValid && retryPolicy.MaxBackOff.Duration < retryPolicy.InitialBackOff.Duration {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("%s.retryPolicy.maxBackOff must not be less than %s.retryPolicy.initialBackOff", scaleInfoKey, scaleInfoKey))
+	}
+	for _, errorClass := range retryPolicy.NonRetriableErrors {
+		v.MustBeOneOf(scaleInfoKey+".retryPolicy.nonRetriableErrors", string(errorClass),
+			string(papi.ErrorClassNotFound), string(papi.ErrorClassInvalid), string(papi.ErrorClassForbidden),
+			string(papi.ErrorClassUnauthorized), string(papi.ErrorClassBadRequest), string(papi.ErrorClassConflict))
+	}
+}
+
 func fillDefaultValues(c *papi.Config) {
 	if c.ProbeInterval == nil {
 		c.ProbeInterval = &metav1.Duration{
@@ -109,7 +337,154 @@ func fillDefaultValues(c *papi.Config) {
 		c.BackoffJitterFactor = new(float64)
 		*c.BackoffJitterFactor = DefaultBackoffJitterFactor
 	}
+	if c.ScaleDownFailurePolicy == nil {
+		c.ScaleDownFailurePolicy = new(papi.ScaleDownFailurePolicy)
+		*c.ScaleDownFailurePolicy = DefaultScaleDownFailurePolicy
+	}
+	if c.ExternalProbePolicy == nil {
+		c.ExternalProbePolicy = new(papi.ExternalProbePolicy)
+		*c.ExternalProbePolicy = DefaultExternalProbePolicy
+	}
+	if c.ResourceCheckTimeout == nil {
+		c.ResourceCheckTimeout = &metav1.Duration{
+			Duration: DefaultResourceCheckTimeout,
+		}
+	}
+	if c.ResourceCheckInterval == nil {
+		c.ResourceCheckInterval = &metav1.Duration{
+			Duration: DefaultResourceCheckInterval,
+		}
+	}
+	if c.ScaledDownResourceRestoration == nil {
+		c.ScaledDownResourceRestoration = new(papi.ScaledDownResourceRestoration)
+	}
+	fillDefaultValuesForRestoration(c.ScaledDownResourceRestoration)
+	if c.AdaptiveProbeInterval == nil {
+		c.AdaptiveProbeInterval = new(papi.AdaptiveProbeInterval)
+	}
+	fillDefaultValuesForAdaptiveProbeInterval(c.AdaptiveProbeInterval)
+	if c.VerdictAnnotation == nil {
+		c.VerdictAnnotation = new(papi.VerdictAnnotation)
+	}
+	fillDefaultValuesForVerdictAnnotation(c.VerdictAnnotation)
+	if c.ProbeStatusResource == nil {
+		c.ProbeStatusResource = new(papi.ProbeStatusResource)
+	}
+	fillDefaultValuesForProbeStatusResource(c.ProbeStatusResource)
+	if c.Notifier != nil {
+		notifier.FillDefaultValues(c.Notifier)
+	}
+	if c.Audit != nil {
+		audit.FillDefaultValues(c.Audit)
+	}
 	fillDefaultValuesForResourceInfos(c.DependentResourceInfos)
+	if len(c.EscalationStages) == 0 && len(c.DependentResourceInfos) > 0 {
+		c.EscalationStages = createDefaultEscalationStages(c.DependentResourceInfos)
+	}
+	c.DecisionMatrix = fillDefaultDecisions(c.DecisionMatrix)
+}
+
+// DefaultDecisionMatrix returns the decision matrix which is used for combinations of internal and external probe health
+// that are not configured. Dependent resources are scaled down if the internal probe is healthy and the external probe is
+// unhealthy and scaled up if both are healthy. For any other combination no action is taken.
+func DefaultDecisionMatrix() []papi.Decision {
+	var decisions []papi.Decision
+	for _, internal := range allProbeHealths {
+		for _, external := range allProbeHealths {
+			action := papi.DecisionActionNone
+			if internal == papi.ProbeHealthHealthy && external == papi.ProbeHealthHealthy {
+				action = papi.DecisionActionScaleUp
+			} else if internal == papi.ProbeHealthHealthy && external == papi.ProbeHealthUnhealthy {
+				action = papi.DecisionActionScaleDown
+			}
+			decisions = append(decisions, papi.Decision{Internal: internal, External: external, Action: action})
+		}
+	}
+	return decisions
+}
+
+// fillDefaultDecisions adds a decision from the DefaultDecisionMatrix for every combination of internal and external probe
+// health which is not part of the given decisions.
+func fillDefaultDecisions(decisions []papi.Decision) []papi.Decision {
+	for _, defaultDecision := range DefaultDecisionMatrix() {
+		if _, ok := findDecision(decisions, defaultDecision.Internal, defaultDecision.External); !ok {
+			decisions = append(decisions, defaultDecision)
+		}
+	}
+	return decisions
+}
+
+// createDefaultEscalationStages creates a single escalation stage which scales down all dependent resources as soon as the external probe is unhealthy.
+func createDefaultEscalationStages(resInfos []papi.DependentResourceInfo) []papi.EscalationStage {
+	resourceNames := make([]string, 0, len(resInfos))
+	for _, resInfo := range resInfos {
+		if resInfo.Ref != nil {
+			resourceNames = append(resourceNames, resInfo.Ref.Name)
+		}
+	}
+	return []papi.EscalationStage{{ResourceNames: resourceNames}}
+}
+
+func fillDefaultValuesForRestoration(restoration *papi.ScaledDownResourceRestoration) {
+	if restoration.Enabled == nil {
+		restoration.Enabled = new(bool)
+		*restoration.Enabled = DefaultRestorationEnabled
+	}
+	if restoration.GracePeriod == nil {
+		restoration.GracePeriod = &metav1.Duration{
+			Duration: DefaultRestorationGracePeriod,
+		}
+	}
+	if restoration.Interval == nil {
+		restoration.Interval = &metav1.Duration{
+			Duration: DefaultRestorationInterval,
+		}
+	}
+}
+
+func fillDefaultValuesForAdaptiveProbeInterval(adaptiveProbeInterval *papi.AdaptiveProbeInterval) {
+	if adaptiveProbeInterval.Enabled == nil {
+		adaptiveProbeInterval.Enabled = new(bool)
+		*adaptiveProbeInterval.Enabled = DefaultAdaptiveProbeIntervalEnabled
+	}
+	if adaptiveProbeInterval.MinInterval == nil {
+		adaptiveProbeInterval.MinInterval = &metav1.Duration{
+			Duration: DefaultAdaptiveProbeMinInterval,
+		}
+	}
+	if adaptiveProbeInterval.MaxBackoffDuration == nil {
+		adaptiveProbeInterval.MaxBackoffDuration = &metav1.Duration{
+			Duration: DefaultAdaptiveProbeMaxBackoffDuration,
+		}
+	}
+}
+
+func fillDefaultValuesForVerdictAnnotation(verdictAnnotation *papi.VerdictAnnotation) {
+	if verdictAnnotation.Enabled == nil {
+		verdictAnnotation.Enabled = new(bool)
+		*verdictAnnotation.Enabled = DefaultVerdictAnnotationEnabled
+	}
+	if verdictAnnotation.MinUpdateInterval == nil {
+		verdictAnnotation.MinUpdateInterval = &metav1.Duration{
+			Duration: DefaultVerdictAnnotationMinUpdateInterval,
+		}
+	}
+}
+
+func fillDefaultValuesForProbeStatusResource(probeStatusResource *papi.ProbeStatusResource) {
+	if probeStatusResource.Enabled == nil {
+		probeStatusResource.Enabled = new(bool)
+		*probeStatusResource.Enabled = DefaultProbeStatusResourceEnabled
+	}
+	if probeStatusResource.MinUpdateInterval == nil {
+		probeStatusResource.MinUpdateInterval = &metav1.Duration{
+			Duration: DefaultProbeStatusResourceMinUpdateInterval,
+		}
+	}
+	if probeStatusResource.ScaleHistoryLimit == nil {
+		probeStatusResource.ScaleHistoryLimit = new(int)
+		*probeStatusResource.ScaleHistoryLimit = DefaultProbeStatusResourceScaleHistoryLimit
+	}
 }
 
 func fillDefaultValuesForResourceInfos(resourceInfos []papi.DependentResourceInfo) {
@@ -131,5 +506,26 @@ func fillDefaultValuesForScaleInfo(scaleInfo *papi.ScaleInfo) {
 				Duration: DefaultScaleInitialDelay,
 			}
 		}
+		if scaleInfo.RetryPolicy == nil {
+			scaleInfo.RetryPolicy = new(papi.RetryPolicy)
+		}
+		fillDefaultValuesForRetryPolicy(scaleInfo.RetryPolicy)
+	}
+}
+
+func fillDefaultValuesForRetryPolicy(retryPolicy *papi.RetryPolicy) {
+	if retryPolicy.MaxAttempts == nil {
+		retryPolicy.MaxAttempts = new(int)
+		*retryPolicy.MaxAttempts = DefaultScaleMaxAttempts
+	}
+	if retryPolicy.InitialBackOff == nil {
+		retryPolicy.InitialBackOff = &metav1.Duration{
+			Duration: DefaultScaleInitialBackOff,
+		}
+	}
+	if retryPolicy.MaxBackOff == nil {
+		retryPolicy.MaxBackOff = &metav1.Duration{
+			Duration: DefaultScaleMaxBackOff,
+		}
 	}
 }
@@ -20,13 +20,21 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
+	aapi "github.com/gardener/dependency-watchdog/api/audit"
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
 	multierr "github.com/hashicorp/go-multierror"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 )
 
 const testdataPath = "testdata"
@@ -43,6 +51,13 @@ func TestProberConfigSuite(t *testing.T) {
 		{"config file not found", testConfigFileNotFound},
 		{"invalid configuration yaml", testErrorInUnMarshallingYaml},
 		{"valid configuration yaml", testValidConfigShouldPassAllValidations},
+		{"invalid retry policy should error out", testInvalidRetryPolicyShouldReturnError},
+		{"invalid scale condition should error out", testInvalidScaleConditionShouldReturnError},
+		{"invalid escalation stages should error out", testInvalidEscalationStagesShouldReturnError},
+		{"invalid decision matrix should error out", testInvalidDecisionMatrixShouldReturnError},
+		{"invalid external probe targets should error out", testInvalidExternalProbeTargetsShouldReturnError},
+		{"external kubeconfig secret is optional if all external probe targets have their own", testExternalKubeConfigSecretIsOptionalIfAllTargetsHaveTheirOwn},
+		{"invalid adaptive probe interval should error out", testInvalidAdaptiveProbeIntervalShouldReturnError},
 	}
 
 	scheme := runtime.NewScheme()
@@ -72,6 +87,37 @@ func testCheckIfDefaultValuesAreSetForAllOptionalMissingValues(t *testing.T, s *
 	g.Expect(*config.FailureThreshold).To(Equal(DefaultFailureThreshold), "LoadConfig should set failure threshold to DefaultFailureThreshold if not set in the config file")
 	g.Expect(config.InternalProbeFailureBackoffDuration.Milliseconds()).To(Equal(DefaultInternalProbeFailureBackoffDuration.Milliseconds()), "LoadConfig should set backOff duration to DefaultInternalProbeFailureBackoffDuration if not set in the config file")
 	g.Expect(*config.BackoffJitterFactor).To(Equal(DefaultBackoffJitterFactor), "LoadConfig should set jitter factor to DefaultJitterFactor if not set in the config file")
+	g.Expect(*config.ScaleDownFailurePolicy).To(Equal(DefaultScaleDownFailurePolicy), "LoadConfig should set scale down failure policy to DefaultScaleDownFailurePolicy if not set in the config file")
+	g.Expect(config.ResourceCheckTimeout.Milliseconds()).To(Equal(DefaultResourceCheckTimeout.Milliseconds()), "LoadConfig should set resource check timeout to DefaultResourceCheckTimeout if not set in the config file")
+	g.Expect(config.ResourceCheckInterval.Milliseconds()).To(Equal(DefaultResourceCheckInterval.Milliseconds()), "LoadConfig should set resource check interval to DefaultResourceCheckInterval if not set in the config file")
+	g.Expect(*config.ScaledDownResourceRestoration.Enabled).To(Equal(DefaultRestorationEnabled), "LoadConfig should enable restoration by default if not set in the config file")
+	g.Expect(config.ScaledDownResourceRestoration.GracePeriod.Milliseconds()).To(Equal(DefaultRestorationGracePeriod.Milliseconds()), "LoadConfig should set restoration grace period to DefaultRestorationGracePeriod if not set in the config file")
+	g.Expect(config.ScaledDownResourceRestoration.Interval.Milliseconds()).To(Equal(DefaultRestorationInterval.Milliseconds()), "LoadConfig should set restoration interval to DefaultRestorationInterval if not set in the config file")
+	g.Expect(config.MaxScaleDownDuration).To(BeNil(), "LoadConfig should not set a max scale down duration if not set in the config file")
+	g.Expect(config.EscalationStages).To(HaveLen(1), "LoadConfig should set a single escalation stage if not set in the config file")
+	g.Expect(config.EscalationStages[0].After.Duration).To(BeZero(), "LoadConfig should set a default escalation stage which is due immediately")
+	g.Expect(config.EscalationStages[0].ResourceNames).To(HaveLen(len(config.DependentResourceInfos)), "LoadConfig should set a default escalation stage comprising all dependent resources")
+	g.Expect(config.DecisionMatrix).To(ConsistOf(DefaultDecisionMatrix()), "LoadConfig should set the default decision matrix if not set in the config file")
+	g.Expect(*config.ExternalProbePolicy).To(Equal(DefaultExternalProbePolicy), "LoadConfig should set external probe policy to DefaultExternalProbePolicy if not set in the config file")
+	g.Expect(config.ExternalProbeTargets).To(BeEmpty(), "LoadConfig should not set any external probe targets if not set in the config file")
+	g.Expect(*config.AdaptiveProbeInterval.Enabled).To(Equal(DefaultAdaptiveProbeIntervalEnabled), "LoadConfig should disable the adaptive probe interval by default if not set in the config file")
+	g.Expect(config.AdaptiveProbeInterval.MinInterval.Milliseconds()).To(Equal(DefaultAdaptiveProbeMinInterval.Milliseconds()), "LoadConfig should set adaptive probe min interval to DefaultAdaptiveProbeMinInterval if not set in the config file")
+	g.Expect(config.AdaptiveProbeInterval.MaxBackoffDuration.Milliseconds()).To(Equal(DefaultAdaptiveProbeMaxBackoffDuration.Milliseconds()), "LoadConfig should set adaptive probe max back off duration to DefaultAdaptiveProbeMaxBackoffDuration if not set in the config file")
+	g.Expect(*config.VerdictAnnotation.Enabled).To(Equal(DefaultVerdictAnnotationEnabled), "LoadConfig should disable the verdict annotation by default if not set in the config file")
+	g.Expect(config.VerdictAnnotation.MinUpdateInterval.Duration).To(Equal(DefaultVerdictAnnotationMinUpdateInterval), "LoadConfig should set verdict annotation min update interval to DefaultVerdictAnnotationMinUpdateInterval if not set in the config file")
+	g.Expect(*config.ProbeStatusResource.Enabled).To(Equal(DefaultProbeStatusResourceEnabled), "LoadConfig should disable the probe status resource by default if not set in the config file")
+	g.Expect(config.ProbeStatusResource.MinUpdateInterval.Duration).To(Equal(DefaultProbeStatusResourceMinUpdateInterval), "LoadConfig should set probe status resource min update interval to DefaultProbeStatusResourceMinUpdateInterval if not set in the config file")
+	g.Expect(*config.ProbeStatusResource.ScaleHistoryLimit).To(Equal(DefaultProbeStatusResourceScaleHistoryLimit), "LoadConfig should set probe status resource scale history limit to DefaultProbeStatusResourceScaleHistoryLimit if not set in the config file")
+	g.Expect(config.Notifier).To(BeNil(), "LoadConfig should not configure a notifier if not set in the config file")
+	g.Expect(config.Audit).To(BeNil(), "LoadConfig should not configure an audit log if not set in the config file")
+	for _, resInfo := range config.DependentResourceInfos {
+		for _, scaleInfo := range []*papi.ScaleInfo{resInfo.ScaleUpInfo, resInfo.ScaleDownInfo} {
+			g.Expect(*scaleInfo.RetryPolicy.MaxAttempts).To(Equal(DefaultScaleMaxAttempts), fmt.Sprintf("LoadConfig should set retry max attempts for %v to DefaultScaleMaxAttempts if not set in the config file", resInfo.Ref.Name))
+			g.Expect(scaleInfo.RetryPolicy.InitialBackOff.Milliseconds()).To(Equal(DefaultScaleInitialBackOff.Milliseconds()), fmt.Sprintf("LoadConfig should set retry initial back off for %v to DefaultScaleInitialBackOff if not set in the config file", resInfo.Ref.Name))
+			g.Expect(scaleInfo.RetryPolicy.MaxBackOff.Milliseconds()).To(Equal(DefaultScaleMaxBackOff.Milliseconds()), fmt.Sprintf("LoadConfig should set retry max back off for %v to DefaultScaleMaxBackOff if not set in the config file", resInfo.Ref.Name))
+			g.Expect(scaleInfo.RetryPolicy.NonRetriableErrors).To(BeEmpty(), fmt.Sprintf("LoadConfig should not set any non retriable errors for %v if not set in the config file", resInfo.Ref.Name))
+		}
+	}
 	for _, resInfo := range config.DependentResourceInfos {
 		g.Expect(resInfo.ScaleUpInfo.InitialDelay.Milliseconds()).To(Equal(DefaultScaleInitialDelay.Milliseconds()), fmt.Sprintf("LoadConfig should set scale up initial delay for %v to DefaultInitialDelay if not set in the config file", resInfo.Ref.Name))
 		g.Expect(resInfo.ScaleUpInfo.Timeout.Milliseconds()).To(Equal(DefaultScaleUpdateTimeout.Milliseconds()), fmt.Sprintf("LoadConfig should set scale up timeout for %v to DefaultScaleUpTimeout if not set in the config file", resInfo.Ref.Name))
@@ -136,6 +182,146 @@ func testValidConfigShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
 	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
 	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
 	g.Expect(len(config.DependentResourceInfos)).To(Equal(3), "LoadConfig did not load all the dependent resources")
+	g.Expect(*config.ScaleDownFailurePolicy).To(Equal(papi.ScaleDownFailurePolicyRollback), "LoadConfig did not load the scale down failure policy")
+	g.Expect(config.ResourceCheckTimeout.Duration).To(Equal(10*time.Second), "LoadConfig did not load the resource check timeout")
+	g.Expect(config.ResourceCheckInterval.Duration).To(Equal(2*time.Second), "LoadConfig did not load the resource check interval")
+	g.Expect(*config.ScaledDownResourceRestoration.Enabled).To(BeFalse(), "LoadConfig did not load the restoration enabled flag")
+	g.Expect(config.ScaledDownResourceRestoration.GracePeriod.Duration).To(Equal(10*time.Minute), "LoadConfig did not load the restoration grace period")
+	g.Expect(config.MaxScaleDownDuration.Duration).To(Equal(2*time.Hour), "LoadConfig did not load the max scale down duration")
+	retryPolicy := config.DependentResourceInfos[0].ScaleDownInfo.RetryPolicy
+	g.Expect(*retryPolicy.MaxAttempts).To(Equal(5), "LoadConfig did not load the retry max attempts")
+	g.Expect(retryPolicy.InitialBackOff.Duration).To(Equal(200*time.Millisecond), "LoadConfig did not load the retry initial back off")
+	g.Expect(retryPolicy.MaxBackOff.Duration).To(Equal(2*time.Second), "LoadConfig did not load the retry max back off")
+	g.Expect(retryPolicy.NonRetriableErrors).To(Equal([]papi.ErrorClass{papi.ErrorClassNotFound, papi.ErrorClassForbidden}), "LoadConfig did not load the non retriable errors")
+	g.Expect(*config.DependentResourceInfos[1].ScaleDownInfo.Condition).To(Equal("size(shoot.spec.provider.workers) > 0"), "LoadConfig did not load the scale condition")
+	g.Expect(config.DependentResourceInfos[1].ScaleUpInfo.Condition).To(BeNil(), "LoadConfig should not set a scale condition if not set in the config file")
+	g.Expect(config.EscalationStages).To(Equal([]papi.EscalationStage{
+		{After: metav1.Duration{Duration: time.Minute}, ResourceNames: []string{"cluster-autoscaler"}},
+		{After: metav1.Duration{Duration: 5 * time.Minute}, ResourceNames: []string{"machine-controller-manager", "kube-controller-manager"}},
+	}), "LoadConfig did not load the escalation stages")
+	g.Expect(config.DecisionMatrix).To(HaveLen(len(DefaultDecisionMatrix())), "LoadConfig should complete the decision matrix with the default decisions")
+	g.Expect(config.DecisionMatrix[0]).To(Equal(papi.Decision{Internal: papi.ProbeHealthUnhealthy, External: papi.ProbeHealthUnknown, Action: papi.DecisionActionScaleDown, ResourceNames: []string{"machine-controller-manager"}}), "LoadConfig did not load the decision matrix")
+	g.Expect(*config.ExternalProbePolicy).To(Equal(papi.ExternalProbePolicyQuorum), "LoadConfig did not load the external probe policy")
+	g.Expect(config.ExternalProbeTargets).To(Equal([]papi.ExternalProbeTarget{
+		{Name: "dns"},
+		{Name: "load-balancer", ServerURL: pointer.String("https://10.0.0.1"), ProxyURL: pointer.String("socks5://vpn:1080")},
+		{Name: "node-network", KubeConfigSecretName: pointer.String("dwd-node-network-probe-secret")},
+	}), "LoadConfig did not load the external probe targets")
+	g.Expect(*config.ExternalProbeProxyURL).To(Equal("http://egress-proxy:3128"), "LoadConfig did not load the external probe proxy URL")
+	g.Expect(*config.AdaptiveProbeInterval.Enabled).To(BeTrue(), "LoadConfig did not load the adaptive probe interval")
+	g.Expect(config.AdaptiveProbeInterval.MinInterval.Duration).To(Equal(5*time.Second), "LoadConfig did not load the adaptive probe min interval")
+	g.Expect(config.AdaptiveProbeInterval.MaxBackoffDuration.Duration).To(Equal(DefaultAdaptiveProbeMaxBackoffDuration), "LoadConfig should set adaptive probe max back off duration to DefaultAdaptiveProbeMaxBackoffDuration if not set in the config file")
+	g.Expect(config.Notifier.Webhooks).To(Equal([]napi.Webhook{{
+		Name:           "on-call",
+		URL:            "https://hooks.example.com/dwd",
+		SigningKeyFile: pointer.String("/etc/dwd/webhook/signing-key"),
+		Events:         []napi.EventType{napi.EventTypeExternalProbeUnhealthy, napi.EventTypeScaleDownFailed},
+	}}), "LoadConfig did not load the notifier webhooks")
+	g.Expect(*config.Notifier.MaxAttempts).To(Equal(5), "LoadConfig did not load the notifier max attempts")
+	g.Expect(*config.Notifier.QueueSize).To(Equal(notifier.DefaultQueueSize), "LoadConfig should set the notifier queue size to notifier.DefaultQueueSize if not set in the config file")
+	g.Expect(config.Audit.Sink).To(Equal(aapi.SinkTypeConfigMap), "LoadConfig did not load the audit sink")
+	g.Expect(*config.Audit.ConfigMap.MaxRecords).To(Equal(50), "LoadConfig did not load the audit configmap max records")
+	g.Expect(*config.Audit.ConfigMap.Name).To(Equal(audit.DefaultConfigMapName), "LoadConfig should set the audit configmap name to audit.DefaultConfigMapName if not set in the config file")
+	g.Expect(*config.VerdictAnnotation.Enabled).To(BeTrue(), "LoadConfig did not load the verdict annotation enabled flag")
+	g.Expect(config.VerdictAnnotation.MinUpdateInterval.Duration).To(Equal(time.Minute), "LoadConfig did not load the verdict annotation min update interval")
+	g.Expect(*config.ProbeStatusResource.Enabled).To(BeTrue(), "LoadConfig did not load the probe status resource")
+	g.Expect(*config.ProbeStatusResource.ScaleHistoryLimit).To(Equal(5), "LoadConfig did not load the probe status resource scale history limit")
 
 	t.Log("Valid config is loaded correctly")
 }
+
+func testInvalidRetryPolicyShouldReturnError(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_invalid_retry_policy.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).To(HaveOccurred(), "LoadConfig should return error for a config with an invalid retry policy")
+	g.Expect(config).To(BeNil(), "LoadConfig should return a nil config for a file with an invalid retry policy")
+	if merr, ok := err.(*multierr.Error); ok {
+		g.Expect(len(merr.Errors)).To(Equal(4), "LoadConfig did not return all the errors for a faulty retry policy")
+	}
+}
+
+func testInvalidScaleConditionShouldReturnError(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_invalid_scale_condition.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).To(HaveOccurred(), "LoadConfig should return error for a config with an invalid scale condition")
+	g.Expect(config).To(BeNil(), "LoadConfig should return a nil config for a file with an invalid scale condition")
+	if merr, ok := err.(*multierr.Error); ok {
+		g.Expect(len(merr.Errors)).To(Equal(2), "LoadConfig did not return all the errors for faulty scale conditions")
+	}
+}
+
+func testInvalidEscalationStagesShouldReturnError(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_invalid_escalation_stages.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).To(HaveOccurred(), "LoadConfig should return error for a config with invalid escalation stages")
+	g.Expect(config).To(BeNil(), "LoadConfig should return a nil config for a file with invalid escalation stages")
+	if merr, ok := err.(*multierr.Error); ok {
+		g.Expect(len(merr.Errors)).To(Equal(4), "LoadConfig did not return all the errors for faulty escalation stages")
+	}
+}
+
+func testInvalidDecisionMatrixShouldReturnError(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_invalid_decision_matrix.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).To(HaveOccurred(), "LoadConfig should return error for a config with an invalid decision matrix")
+	g.Expect(config).To(BeNil(), "LoadConfig should return a nil config for a file with an invalid decision matrix")
+	if merr, ok := err.(*multierr.Error); ok {
+		g.Expect(len(merr.Errors)).To(Equal(4), "LoadConfig did not return all the errors for a faulty decision matrix")
+	}
+}
+
+func testInvalidExternalProbeTargetsShouldReturnError(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_invalid_external_probe_targets.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).To(HaveOccurred(), "LoadConfig should return error for a config with invalid external probe targets")
+	g.Expect(config).To(BeNil(), "LoadConfig should return a nil config for a file with invalid external probe targets")
+	if merr, ok := err.(*multierr.Error); ok {
+		g.Expect(len(merr.Errors)).To(Equal(7), "LoadConfig did not return all the errors for faulty external probe targets")
+	}
+}
+
+func testExternalKubeConfigSecretIsOptionalIfAllTargetsHaveTheirOwn(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_external_probe_targets_with_own_secrets.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not require externalKubeConfigSecretName if all external probe targets have their own kubeconfig secret")
+	g.Expect(config.ExternalKubeConfigSecretName).To(BeEmpty())
+	g.Expect(config.ExternalProbeTargets).To(HaveLen(2))
+}
+
+func testInvalidAdaptiveProbeIntervalShouldReturnError(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "config_invalid_adaptive_probe_interval.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).To(HaveOccurred(), "LoadConfig should return error for a config with an invalid adaptive probe interval")
+	g.Expect(config).To(BeNil(), "LoadConfig should return a nil config for a file with an invalid adaptive probe interval")
+	if merr, ok := err.(*multierr.Error); ok {
+		g.Expect(len(merr.Errors)).To(Equal(2), "LoadConfig did not return all the errors for a faulty adaptive probe interval")
+	}
+}
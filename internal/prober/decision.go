@@ -0,0 +1,116 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const eventReasonProbeAlert = "DWDProbeAlert"
+
+var allProbeHealths = []papi.ProbeHealth{papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy, papi.ProbeHealthUnknown}
+
+// decide looks up the decision for the given combination of internal and external probe health in the configured decision
+// matrix. If the combination is not configured, the decision is taken from the DefaultDecisionMatrix.
+func (p *Prober) decide(internal papi.ProbeHealth, external papi.ProbeHealth) papi.Decision {
+	if decision, ok := findDecision(p.config.DecisionMatrix, internal, external); ok {
+		return decision
+	}
+	decision, _ := findDecision(DefaultDecisionMatrix(), internal, external)
+	return decision
+}
+
+func findDecision(decisions []papi.Decision, internal papi.ProbeHealth, external papi.ProbeHealth) (papi.Decision, bool) {
+	for _, decision := range decisions {
+		if decision.Internal == internal && decision.External == external {
+			return decision, true
+		}
+	}
+	return papi.Decision{}, false
+}
+
+// isExternalProbeRequired checks if the decision for the given internal probe health depends on the health of the external probe.
+func (p *Prober) isExternalProbeRequired(internal papi.ProbeHealth) bool {
+	first := p.decide(internal, allProbeHealths[0])
+	for _, external := range allProbeHealths[1:] {
+		decision := p.decide(internal, external)
+		if decision.Action != first.Action || !reflect.DeepEqual(decision.ResourceNames, first.ResourceNames) {
+			return true
+		}
+	}
+	return false
+}
+
+// act carries out the action of the given decision.
+func (p *Prober) act(ctx context.Context, decision papi.Decision) {
+	switch decision.Action {
+	case papi.DecisionActionScaleDown:
+		p.scaleDown(ctx, decision)
+	case papi.DecisionActionScaleUp:
+		p.scaleUp(ctx, decision)
+	case papi.DecisionActionAlertOnly:
+		p.l.Info("Probe health requires an alert, no dependent resources will be scaled", "internal", decision.Internal, "external", decision.External)
+		decisionAlertsTotal.WithLabelValues(p.namespace, string(decision.Internal), string(decision.External)).Inc()
+		p.recordDependentResourcesEvent(ctx, corev1.EventTypeWarning, eventReasonProbeAlert,
+			fmt.Sprintf("internal probe is %s and external probe is %s", decision.Internal, decision.External))
+	default:
+		p.l.V(1).Info("No action required for probe health", "internal", decision.Internal, "external", decision.External)
+	}
+}
+
+func (p *Prober) scaleDown(ctx context.Context, decision papi.Decision) {
+	if p.failOpen {
+		p.l.Info("Probe health requires scale down but prober is fail-open, checking if scale up is already done or is still pending", "internal", decision.Internal, "external", decision.External)
+		p.reportScaleResult(ctx, p.scaler.ScaleUp(dwdScaler.ContextWithoutScaleConditions(p.createScaleContext(ctx))))
+		return
+	}
+	if p.unhealthySince.IsZero() {
+		p.unhealthySince = time.Now()
+		p.notifyScaleDownStarted(decision.ResourceNames)
+	}
+	unhealthyFor := time.Since(p.unhealthySince)
+	p.l.Info("Probe health requires scale down, checking if scale down is already done or is still pending", "internal", decision.Internal, "external", decision.External, "unhealthyFor", unhealthyFor)
+	var result dwdScaler.Result
+	if len(decision.ResourceNames) > 0 {
+		result = p.scaler.ScaleDownResources(p.createScaleContext(ctx), decision.ResourceNames)
+	} else {
+		result = p.scaler.ScaleDown(p.createScaleContext(ctx), unhealthyFor)
+	}
+	// resources which have only been skipped or failed to be scaled are not scaled down, so fail-open is not approached
+	if len(result.ScaledResources()) > 0 || len(result.AlreadyAtTargetResources()) > 0 {
+		p.markScaledDown()
+	}
+	p.reportScaleResult(ctx, result)
+}
+
+func (p *Prober) scaleUp(ctx context.Context, decision papi.Decision) {
+	p.unhealthySince = time.Time{}
+	if decision.External == papi.ProbeHealthHealthy {
+		p.closeFailOpen(ctx)
+	}
+	p.l.Info("Probe health requires scale up, checking if scale up is already done or is still pending", "internal", decision.Internal, "external", decision.External)
+	result := p.scaler.ScaleUp(p.createScaleContext(ctx))
+	p.reportScaleResult(ctx, result)
+	if result.Err == nil {
+		p.scaledDownSince = time.Time{}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDecideFallsBackToDefaultDecisionMatrix(t *testing.T) {
+	g := NewWithT(t)
+	p := &Prober{config: &papi.Config{DecisionMatrix: []papi.Decision{
+		{Internal: papi.ProbeHealthUnhealthy, External: papi.ProbeHealthUnknown, Action: papi.DecisionActionAlertOnly},
+	}}}
+
+	g.Expect(p.decide(papi.ProbeHealthUnhealthy, papi.ProbeHealthUnknown).Action).To(Equal(papi.DecisionActionAlertOnly))
+	g.Expect(p.decide(papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy).Action).To(Equal(papi.DecisionActionScaleDown))
+	g.Expect(p.decide(papi.ProbeHealthHealthy, papi.ProbeHealthHealthy).Action).To(Equal(papi.DecisionActionScaleUp))
+	g.Expect(p.decide(papi.ProbeHealthUnknown, papi.ProbeHealthUnhealthy).Action).To(Equal(papi.DecisionActionNone))
+}
+
+func TestIsExternalProbeRequired(t *testing.T) {
+	g := NewWithT(t)
+	p := &Prober{config: &papi.Config{DecisionMatrix: DefaultDecisionMatrix()}}
+	g.Expect(p.isExternalProbeRequired(papi.ProbeHealthHealthy)).To(BeTrue())
+	g.Expect(p.isExternalProbeRequired(papi.ProbeHealthUnhealthy)).To(BeFalse())
+	g.Expect(p.isExternalProbeRequired(papi.ProbeHealthUnknown)).To(BeFalse())
+
+	p.config.DecisionMatrix = createUniformDecisions(papi.ProbeHealthUnhealthy, papi.DecisionActionScaleDown, []string{"kube-controller-manager"})
+	g.Expect(p.isExternalProbeRequired(papi.ProbeHealthUnhealthy)).To(BeFalse())
+	p.config.DecisionMatrix[0].ResourceNames = []string{"machine-controller-manager"}
+	g.Expect(p.isExternalProbeRequired(papi.ProbeHealthUnhealthy)).To(BeTrue())
+}
+
+func TestInternalProbeFailingShouldScaleDownConfiguredResources(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.DecisionMatrix = createUniformDecisions(papi.ProbeHealthUnhealthy, papi.DecisionActionScaleDown, []string{"kube-controller-manager"})
+
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+	mki.EXPECT().Discovery().Return(mdi).AnyTimes()
+	mdi.EXPECT().ServerVersion().Return(nil, errNotIgnorable).AnyTimes()
+	mds.EXPECT().ScaleDownResources(gomock.Any(), []string{"kube-controller-manager"}).Return(scaler.Result{}).MinTimes(1)
+
+	p := runProberAndCheckStatus(t, 20*time.Millisecond, probeStatusEntry{expectedInternalProbeErrorCount: 1})
+	g.Expect(p.IsHealthy()).To(BeFalse())
+}
+
+func TestAlertOnlyDecisionShouldNotScale(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.DecisionMatrix = []papi.Decision{{Internal: papi.ProbeHealthHealthy, External: papi.ProbeHealthUnhealthy, Action: papi.DecisionActionAlertOnly}}
+	config.DependentResourceInfos = []papi.DependentResourceInfo{
+		{Ref: &autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}},
+	}
+	runCounter := 0
+
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+	mki.EXPECT().Discovery().Return(mdi).AnyTimes()
+	mdi.EXPECT().ServerVersion().DoAndReturn(func() (*version.Info, error) {
+		runCounter++
+		if runCounter%2 == 1 {
+			return nil, nil
+		}
+		return nil, errNotIgnorable
+	}).AnyTimes()
+
+	recorder := record.NewFakeRecorder(100)
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, recorder, nil, proberTestLogger)
+	runProber(p, 20*time.Millisecond)
+
+	g.Expect(p.IsHealthy()).To(BeFalse())
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	g.Expect(events).To(ContainElement(ContainSubstring(eventReasonProbeAlert)))
+}
+
+func TestScaleUpDecisionShouldCloseFailOpenOnlyIfExternalProbeIsHealthy(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.MaxScaleDownDuration = &metav1.Duration{Duration: time.Millisecond}
+	mds.EXPECT().ScaleUp(gomock.Any()).Return(scaler.Result{}).Times(2)
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, record.NewFakeRecorder(10), nil, proberTestLogger)
+	p.failOpen = true
+
+	p.act(context.Background(), papi.Decision{Internal: papi.ProbeHealthHealthy, External: papi.ProbeHealthUnknown, Action: papi.DecisionActionScaleUp})
+	g.Expect(p.failOpen).To(BeTrue())
+
+	p.act(context.Background(), papi.Decision{Internal: papi.ProbeHealthHealthy, External: papi.ProbeHealthHealthy, Action: papi.DecisionActionScaleUp})
+	g.Expect(p.failOpen).To(BeFalse())
+}
+
+func TestScaleDownDecisionShouldMarkScaledDownOnlyIfResourcesHaveBeenScaledDown(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.MaxScaleDownDuration = &metav1.Duration{Duration: time.Minute}
+	kcmRef := autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}
+	mcmRef := autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "machine-controller-manager", APIVersion: "apps/v1"}
+	gomock.InOrder(
+		mds.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(scaler.Result{ResourceResults: []scaler.ResourceResult{
+			{Ref: kcmRef, Outcome: scaler.ResourceSkippedIgnoreScaling},
+			{Ref: mcmRef, Outcome: scaler.ResourceFailed},
+		}}),
+		mds.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(scaler.Result{ResourceResults: []scaler.ResourceResult{
+			{Ref: kcmRef, Outcome: scaler.ResourceSkippedConditionNotMet},
+			{Ref: mcmRef, Outcome: scaler.ResourceSkippedOptionalNotFound},
+		}}),
+		mds.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(scaler.Result{ResourceResults: []scaler.ResourceResult{
+			{Ref: kcmRef, Outcome: scaler.ResourceSkippedAlreadyAtTarget},
+		}}),
+	)
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, record.NewFakeRecorder(10), nil, proberTestLogger)
+	decision := papi.Decision{Internal: papi.ProbeHealthHealthy, External: papi.ProbeHealthUnhealthy, Action: papi.DecisionActionScaleDown}
+
+	p.act(context.Background(), decision)
+	g.Expect(p.scaledDownSince.IsZero()).To(BeTrue(), "resources which have been skipped or failed are not scaled down")
+	p.act(context.Background(), decision)
+	g.Expect(p.scaledDownSince.IsZero()).To(BeTrue(), "resources which have been skipped are not scaled down")
+	p.act(context.Background(), decision)
+	g.Expect(p.scaledDownSince.IsZero()).To(BeFalse(), "resources which already have the target replicas are scaled down")
+}
+
+func createUniformDecisions(internal papi.ProbeHealth, action papi.DecisionAction, resourceNames []string) []papi.Decision {
+	var decisions []papi.Decision
+	for _, external := range allProbeHealths {
+		decisions = append(decisions, papi.Decision{Internal: internal, External: external, Action: action, ResourceNames: resourceNames})
+	}
+	return decisions
+}
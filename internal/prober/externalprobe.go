@@ -0,0 +1,158 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/util"
+)
+
+const (
+	// defaultExternalProbeTargetName is the name of the external probe target which is used if no targets are configured.
+	defaultExternalProbeTargetName = "external"
+	// externalProbeErrorClassProxy classifies failures of the external probe caused by the proxy via which the API server is reached.
+	externalProbeErrorClassProxy = "proxy"
+	// externalProbeErrorClassAPIServer classifies all other failures of the external probe.
+	externalProbeErrorClassAPIServer = "apiserver"
+)
+
+// externalProbe captures the status of the external probe of a single target.
+type externalProbe struct {
+	target papi.ExternalProbeTarget
+	status probeStatus
+}
+
+// getExternalProbeTargets returns the configured external probe targets. If none are configured, a single target using
+// the external kubeconfig secret is returned.
+func getExternalProbeTargets(config *papi.Config) []papi.ExternalProbeTarget {
+	if len(config.ExternalProbeTargets) > 0 {
+		return config.ExternalProbeTargets
+	}
+	return []papi.ExternalProbeTarget{{Name: defaultExternalProbeTargetName}}
+}
+
+func newExternalProbes(config *papi.Config) []*externalProbe {
+	targets := getExternalProbeTargets(config)
+	externalProbes := make([]*externalProbe, 0, len(targets))
+	for _, target := range targets {
+		externalProbes = append(externalProbes, &externalProbe{target: target})
+	}
+	return externalProbes
+}
+
+// probeExternalTargets runs the external probe against each target. A target for which no client can be created is
+// skipped and re-attempted with the next run. It returns false if none of the targets could be probed.
+func (p *Prober) probeExternalTargets(ctx context.Context) bool {
+	probed := false
+	for _, ep := range p.externalProbes {
+		secretName := p.config.ExternalKubeConfigSecretName
+		if ep.target.KubeConfigSecretName != nil {
+			secretName = *ep.target.KubeConfigSecretName
+		}
+		var overrides util.KubeConfigOverrides
+		if ep.target.ServerURL != nil {
+			overrides.ServerURL = *ep.target.ServerURL
+		}
+		if proxyURL := p.externalProbeProxyURL(ep.target); proxyURL != nil {
+			overrides.ProxyURL = *proxyURL
+		}
+		shootClient, err := p.setupProbeClient(ctx, p.namespace, secretName, overrides)
+		if err != nil {
+			p.l.Error(err, "Failed to create shoot client for external probe target, ignoring error, probe will be re-attempted", "target", ep.target.Name)
+			continue
+		}
+		p.probeExternal(ctx, shootClient, ep)
+		probed = true
+	}
+	return probed
+}
+
+func (p *Prober) externalProbeProxyURL(target papi.ExternalProbeTarget) *string {
+	if target.ProxyURL != nil {
+		return target.ProxyURL
+	}
+	return p.config.ExternalProbeProxyURL
+}
+
+// externalHealth computes the health of the external probe from the health of its targets as defined by the ExternalProbePolicy.
+func (p *Prober) externalHealth() (papi.ProbeHealth, map[string]papi.ProbeHealth) {
+	var healthyCount, unhealthyCount int
+	targetHealths := make(map[string]papi.ProbeHealth, len(p.externalProbes))
+	for _, ep := range p.externalProbes {
+		health := ep.status.health(*p.config.SuccessThreshold, *p.config.FailureThreshold)
+		targetHealths[ep.target.Name] = health
+		switch health {
+		case papi.ProbeHealthHealthy:
+			healthyCount++
+		case papi.ProbeHealthUnhealthy:
+			unhealthyCount++
+		}
+	}
+	required := requiredHealthyTargets(p.externalProbePolicy(), len(p.externalProbes))
+	switch {
+	case healthyCount >= required:
+		return papi.ProbeHealthHealthy, targetHealths
+	case unhealthyCount > len(p.externalProbes)-required:
+		return papi.ProbeHealthUnhealthy, targetHealths
+	default:
+		return papi.ProbeHealthUnknown, targetHealths
+	}
+}
+
+func (p *Prober) externalProbePolicy() papi.ExternalProbePolicy {
+	if p.config.ExternalProbePolicy == nil {
+		return DefaultExternalProbePolicy
+	}
+	return *p.config.ExternalProbePolicy
+}
+
+// requiredHealthyTargets returns the number of targets which have to be healthy for the external probe to be healthy.
+// The external probe is unhealthy once so many targets are unhealthy that this number can no longer be reached.
+func requiredHealthyTargets(policy papi.ExternalProbePolicy, targetCount int) int {
+	switch policy {
+	case papi.ExternalProbePolicyAll:
+		return targetCount
+	case papi.ExternalProbePolicyQuorum:
+		return targetCount/2 + 1
+	default:
+		return 1
+	}
+}
+
+// externalProbeResult returns the result of the external probe. SuccessCount and ErrorCount are summed over all targets.
+func (p *Prober) externalProbeResult() (dwdScaler.ProbeResult, map[string]dwdScaler.ProbeResult) {
+	health, _ := p.externalHealth()
+	result := dwdScaler.ProbeResult{Healthy: health == papi.ProbeHealthHealthy}
+	targetResults := make(map[string]dwdScaler.ProbeResult, len(p.externalProbes))
+	for _, ep := range p.externalProbes {
+		targetResult := ep.status.toProbeResult(*p.config.SuccessThreshold)
+		targetResults[ep.target.Name] = targetResult
+		result.SuccessCount += targetResult.SuccessCount
+		result.ErrorCount += targetResult.ErrorCount
+	}
+	return result, targetResults
+}
+
+// classifyExternalProbeError classifies a failure of the external probe as caused by the proxy, i.e. the proxy could not be
+// reached or has refused to connect to the API server, or as caused by the API server.
+func classifyExternalProbeError(err error) string {
+	if util.IsProxyError(err) {
+		return externalProbeErrorClassProxy
+	}
+	return externalProbeErrorClassAPIServer
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestRequiredHealthyTargets(t *testing.T) {
+	tests := []struct {
+		policy           papi.ExternalProbePolicy
+		targetCount      int
+		expectedRequired int
+	}{
+		{papi.ExternalProbePolicyAny, 3, 1},
+		{papi.ExternalProbePolicyAll, 3, 3},
+		{papi.ExternalProbePolicyQuorum, 1, 1},
+		{papi.ExternalProbePolicyQuorum, 2, 2},
+		{papi.ExternalProbePolicyQuorum, 3, 2},
+		{papi.ExternalProbePolicyQuorum, 4, 3},
+	}
+
+	g := NewWithT(t)
+	for _, entry := range tests {
+		g.Expect(requiredHealthyTargets(entry.policy, entry.targetCount)).To(Equal(entry.expectedRequired), "policy: %s, targetCount: %d", entry.policy, entry.targetCount)
+	}
+}
+
+func TestExternalHealthIsComputedByPolicy(t *testing.T) {
+	healthyStatus := probeStatus{successCount: 1}
+	unhealthyStatus := probeStatus{errorCount: 1}
+	unknownStatus := probeStatus{}
+	tests := []struct {
+		name           string
+		policy         papi.ExternalProbePolicy
+		statuses       []probeStatus
+		expectedHealth papi.ProbeHealth
+	}{
+		{"any is healthy if one target is healthy", papi.ExternalProbePolicyAny, []probeStatus{healthyStatus, unhealthyStatus, unhealthyStatus}, papi.ProbeHealthHealthy},
+		{"any is unhealthy if all targets are unhealthy", papi.ExternalProbePolicyAny, []probeStatus{unhealthyStatus, unhealthyStatus, unhealthyStatus}, papi.ProbeHealthUnhealthy},
+		{"any is unknown if no target is healthy and not all are unhealthy", papi.ExternalProbePolicyAny, []probeStatus{unknownStatus, unhealthyStatus, unhealthyStatus}, papi.ProbeHealthUnknown},
+		{"all is healthy if all targets are healthy", papi.ExternalProbePolicyAll, []probeStatus{healthyStatus, healthyStatus, healthyStatus}, papi.ProbeHealthHealthy},
+		{"all is unhealthy if one target is unhealthy", papi.ExternalProbePolicyAll, []probeStatus{healthyStatus, healthyStatus, unhealthyStatus}, papi.ProbeHealthUnhealthy},
+		{"quorum is healthy if a majority of targets is healthy", papi.ExternalProbePolicyQuorum, []probeStatus{healthyStatus, healthyStatus, unhealthyStatus}, papi.ProbeHealthHealthy},
+		{"quorum is unhealthy if a majority of targets is unhealthy", papi.ExternalProbePolicyQuorum, []probeStatus{healthyStatus, unhealthyStatus, unhealthyStatus}, papi.ProbeHealthUnhealthy},
+		{"quorum is unknown if a majority can still be reached", papi.ExternalProbePolicyQuorum, []probeStatus{healthyStatus, unknownStatus, unhealthyStatus}, papi.ProbeHealthUnknown},
+	}
+
+	for _, entry := range tests {
+		t.Run(entry.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config := createConfig(1, 1, metav1.Duration{Duration: time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+			config.ExternalProbePolicy = &entry.policy
+			p := &Prober{config: config}
+			for i, status := range entry.statuses {
+				p.externalProbes = append(p.externalProbes, &externalProbe{target: papi.ExternalProbeTarget{Name: string(rune('a' + i))}, status: status})
+			}
+
+			health, targetHealths := p.externalHealth()
+			g.Expect(health).To(Equal(entry.expectedHealth))
+			g.Expect(targetHealths).To(HaveLen(len(entry.statuses)))
+		})
+	}
+}
+
+func TestClassifyExternalProbeError(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(classifyExternalProbeError(&util.ProxyError{ProxyURL: "http://egress-proxy:3128", Err: errors.New("403 Forbidden")})).To(Equal(externalProbeErrorClassProxy))
+	g.Expect(classifyExternalProbeError(&url.Error{Op: "Get", URL: "https://api", Err: &net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}})).To(Equal(externalProbeErrorClassProxy))
+	g.Expect(classifyExternalProbeError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})).To(Equal(externalProbeErrorClassAPIServer))
+	g.Expect(classifyExternalProbeError(errNotIgnorable)).To(Equal(externalProbeErrorClassAPIServer))
+}
+
+func TestExternalProbeTargetsAreProbedWithTheirOwnSecretAndServerURL(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.InternalKubeConfigSecretName = "internal-secret"
+	config.ExternalKubeConfigSecretName = "external-secret"
+	config.ExternalProbeProxyURL = pointer.String("http://egress-proxy:3128")
+	config.ExternalProbeTargets = []papi.ExternalProbeTarget{
+		{Name: "dns"},
+		{Name: "load-balancer", ServerURL: pointer.String("https://10.0.0.1"), ProxyURL: pointer.String("socks5://vpn:1080")},
+		{Name: "node-network", KubeConfigSecretName: pointer.String("node-network-secret")},
+	}
+
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), "internal-secret", gomock.Any(), util.KubeConfigOverrides{}).Return(mki, nil).MinTimes(1)
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), "external-secret", gomock.Any(), util.KubeConfigOverrides{ProxyURL: "http://egress-proxy:3128"}).Return(mki, nil).MinTimes(1)
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), "external-secret", gomock.Any(), util.KubeConfigOverrides{ServerURL: "https://10.0.0.1", ProxyURL: "socks5://vpn:1080"}).Return(mki, nil).MinTimes(1)
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), "node-network-secret", gomock.Any(), util.KubeConfigOverrides{ProxyURL: "http://egress-proxy:3128"}).Return(nil, errNotIgnorable).MinTimes(1)
+	mki.EXPECT().Discovery().Return(mdi).AnyTimes()
+	mdi.EXPECT().ServerVersion().Return(nil, nil).AnyTimes()
+	mds.EXPECT().ScaleUp(gomock.Any()).AnyTimes()
+
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, &record.FakeRecorder{}, nil, proberTestLogger)
+	runProber(p, 20*time.Millisecond)
+
+	g.Expect(p.externalProbes).To(HaveLen(3))
+	checkProbeStatus(t, p.externalProbes[0].status, 1, 0)
+	checkProbeStatus(t, p.externalProbes[1].status, 1, 0)
+	checkProbeStatus(t, p.externalProbes[2].status, 0, 0)
+	g.Expect(p.IsHealthy()).To(BeTrue())
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	eventReasonFailOpen         = "DWDFailOpen"
+	eventReasonFailOpenResolved = "DWDFailOpenResolved"
+)
+
+// markScaledDown captures the time at which the prober has first decided to scale down the dependent resources.
+// It is used to determine if the dependent resources have been kept scaled down for longer than the configured MaxScaleDownDuration.
+func (p *Prober) markScaledDown() {
+	if p.scaledDownSince.IsZero() {
+		p.scaledDownSince = time.Now()
+	}
+}
+
+// maxScaleDownDurationExceeded checks if the dependent resources have been kept scaled down for longer than the configured
+// MaxScaleDownDuration. It always returns false if MaxScaleDownDuration is not configured or if the prober is already fail-open.
+func (p *Prober) maxScaleDownDurationExceeded() bool {
+	if p.config.MaxScaleDownDuration == nil || p.failOpen || p.scaledDownSince.IsZero() {
+		return false
+	}
+	return time.Since(p.scaledDownSince) >= p.config.MaxScaleDownDuration.Duration
+}
+
+// openFailOpen puts the prober into the fail-open state and scales up the dependent resources irrespective of the state
+// of the external probe. The prober stays fail-open, and will not scale down the dependent resources, till the external probe is healthy again.
+func (p *Prober) openFailOpen(ctx context.Context) {
+	p.l.Info("Dependent resources have been scaled down for longer than maxScaleDownDuration, prober is now fail-open and will scale up irrespective of the external probe", "scaledDownSince", p.scaledDownSince, "maxScaleDownDuration", p.config.MaxScaleDownDuration.Duration)
+	p.failOpen = true
+	p.scaledDownSince = time.Time{}
+	failOpenTotal.WithLabelValues(p.namespace).Inc()
+	failOpen.WithLabelValues(p.namespace).Set(1)
+	message := fmt.Sprintf("dependent resources have been scaled down for longer than %s, prober is fail-open and has scaled up irrespective of the external probe till it is healthy again", p.config.MaxScaleDownDuration.Duration)
+	p.recordDependentResourcesEvent(ctx, corev1.EventTypeWarning, eventReasonFailOpen, message)
+	p.reportScaleResult(ctx, p.scaler.ScaleUp(dwdScaler.ContextWithoutScaleConditions(p.createScaleContext(ctx))))
+}
+
+// closeFailOpen moves the prober out of the fail-open state once the external probe is healthy again.
+func (p *Prober) closeFailOpen(ctx context.Context) {
+	if !p.failOpen {
+		return
+	}
+	p.l.Info("External probe is healthy again, prober is no longer fail-open")
+	p.failOpen = false
+	failOpen.WithLabelValues(p.namespace).Set(0)
+	p.recordDependentResourcesEvent(ctx, corev1.EventTypeNormal, eventReasonFailOpenResolved, "external probe is healthy again, prober is no longer fail-open")
+}
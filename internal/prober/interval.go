@@ -0,0 +1,79 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
+)
+
+func (p *Prober) isAdaptiveProbeIntervalEnabled() bool {
+	return p.config.AdaptiveProbeInterval != nil && p.config.AdaptiveProbeInterval.Enabled != nil && *p.config.AdaptiveProbeInterval.Enabled
+}
+
+// adaptProbeInterval shortens the probe interval to MinInterval as soon as the external probe has failed for any of its
+// targets, so that the failure threshold is reached faster. Once the external probe is healthy again, the interval is relaxed.
+func (p *Prober) adaptProbeInterval(externalHealth papi.ProbeHealth) {
+	if !p.isAdaptiveProbeIntervalEnabled() {
+		return
+	}
+	switch {
+	case externalHealth == papi.ProbeHealthHealthy:
+		p.relaxProbeInterval()
+	case p.hasExternalProbeFailure():
+		p.setProbeInterval(p.config.AdaptiveProbeInterval.MinInterval.Duration)
+	}
+}
+
+// relaxProbeInterval doubles the probe interval till it is back at the configured ProbeInterval. It is also called if the
+// external probe is not run, as the shortened interval only serves to reach the failure threshold of the external probe faster.
+func (p *Prober) relaxProbeInterval() {
+	if !p.isAdaptiveProbeIntervalEnabled() {
+		return
+	}
+	probeInterval := 2 * p.probeInterval
+	if probeInterval > p.config.ProbeInterval.Duration {
+		probeInterval = p.config.ProbeInterval.Duration
+	}
+	p.setProbeInterval(probeInterval)
+}
+
+func (p *Prober) setProbeInterval(probeInterval time.Duration) {
+	if probeInterval != p.probeInterval {
+		p.l.Info("Adapting probe interval", "from", p.probeInterval, "to", probeInterval)
+		p.probeInterval = probeInterval
+	}
+}
+
+func (p *Prober) hasExternalProbeFailure() bool {
+	for _, ep := range p.externalProbes {
+		if ep.status.errorCount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// internalProbeFailureBackoff returns the duration to back off the internal probe once it has reached the failure threshold.
+// If the adaptive probe interval is enabled, the duration is doubled for every consecutive back off, never exceeding MaxBackoffDuration.
+func (p *Prober) internalProbeFailureBackoff() time.Duration {
+	if !p.isAdaptiveProbeIntervalEnabled() {
+		return p.config.InternalProbeFailureBackoffDuration.Duration
+	}
+	backOffFn := util.ExponentialBackOff(p.config.InternalProbeFailureBackoffDuration.Duration, p.config.AdaptiveProbeInterval.MaxBackoffDuration.Duration)
+	return backOffFn(p.internalProbeStatus.backOffCount + 1)
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestAdaptProbeIntervalShortensOnExternalFailureAndRelaxesOnceHealthy(t *testing.T) {
+	g := NewWithT(t)
+	p := createAdaptiveIntervalTestProber(true)
+	g.Expect(p.probeInterval).To(Equal(8 * time.Second))
+
+	p.adaptProbeInterval(papi.ProbeHealthUnknown)
+	g.Expect(p.probeInterval).To(Equal(8*time.Second), "probe interval should not be adapted as long as the external probe has not failed")
+
+	p.externalProbes[0].status.recordFailure(errNotIgnorable, 3, 0)
+	p.adaptProbeInterval(papi.ProbeHealthUnknown)
+	g.Expect(p.probeInterval).To(Equal(time.Second), "probe interval should be shortened to minInterval once the external probe has failed")
+
+	p.externalProbes[0].status.recordSuccess(1)
+	for _, expectedInterval := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		p.adaptProbeInterval(papi.ProbeHealthHealthy)
+		g.Expect(p.probeInterval).To(Equal(expectedInterval), "probe interval should be relaxed back to probeInterval once the external probe is healthy")
+	}
+}
+
+func TestRelaxProbeIntervalOnceExternalProbeIsNotRun(t *testing.T) {
+	g := NewWithT(t)
+	p := createAdaptiveIntervalTestProber(true)
+	p.externalProbes[0].status.recordFailure(errNotIgnorable, 3, 0)
+	p.adaptProbeInterval(papi.ProbeHealthUnknown)
+	g.Expect(p.probeInterval).To(Equal(time.Second))
+
+	// the external probe keeps its failure as it is not run while the internal probe is unhealthy
+	for _, expectedInterval := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		p.relaxProbeInterval()
+		g.Expect(p.probeInterval).To(Equal(expectedInterval), "probe interval should be relaxed back to probeInterval once the external probe is not run")
+	}
+}
+
+func TestAdaptProbeIntervalWhenDisabled(t *testing.T) {
+	g := NewWithT(t)
+	p := createAdaptiveIntervalTestProber(false)
+
+	p.externalProbes[0].status.recordFailure(errNotIgnorable, 3, 0)
+	p.adaptProbeInterval(papi.ProbeHealthUnknown)
+	g.Expect(p.probeInterval).To(Equal(8 * time.Second))
+}
+
+func TestInternalProbeFailureBackoff(t *testing.T) {
+	g := NewWithT(t)
+	p := createAdaptiveIntervalTestProber(false)
+	p.internalProbeStatus.backOffCount = 3
+	g.Expect(p.internalProbeFailureBackoff()).To(Equal(10*time.Second), "internal probe should be backed off for a fixed duration if the adaptive probe interval is disabled")
+
+	p = createAdaptiveIntervalTestProber(true)
+	for _, expectedBackoff := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute} {
+		g.Expect(p.internalProbeFailureBackoff()).To(Equal(expectedBackoff))
+		p.internalProbeStatus.recordFailure(errNotIgnorable, 1, p.internalProbeFailureBackoff())
+	}
+	p.internalProbeStatus.recordSuccess(1)
+	g.Expect(p.internalProbeFailureBackoff()).To(Equal(10*time.Second), "internal probe back off should be reset once the internal probe is successful")
+}
+
+func TestBackOffIfNeededReturnsOnceContextIsCancelled(t *testing.T) {
+	g := NewWithT(t)
+	ps := probeStatus{backOff: time.NewTimer(5 * time.Minute)}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+
+	g.Expect(backOffIfNeeded(ctx, &ps)).To(MatchError(context.Canceled))
+	g.Expect(ps.backOff).To(BeNil())
+}
+
+func createAdaptiveIntervalTestProber(enabled bool) *Prober {
+	config := createConfig(1, 3, metav1.Duration{Duration: 8 * time.Second}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.InternalProbeFailureBackoffDuration = &metav1.Duration{Duration: 10 * time.Second}
+	config.AdaptiveProbeInterval = &papi.AdaptiveProbeInterval{
+		Enabled:            pointer.Bool(enabled),
+		MinInterval:        &metav1.Duration{Duration: time.Second},
+		MaxBackoffDuration: &metav1.Duration{Duration: time.Minute},
+	}
+	return &Prober{
+		config:         config,
+		externalProbes: newExternalProbes(config),
+		probeInterval:  config.ProbeInterval.Duration,
+		l:              logr.Discard(),
+	}
+}
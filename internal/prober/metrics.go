@@ -0,0 +1,142 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"strconv"
+
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "dwd"
+	metricsSubsystem = "prober"
+)
+
+var (
+	// scaledResourcesTotal counts the dependent resources processed by scale flows, partitioned by their outcome.
+	// Resources which were already at their target replicas are not counted, as this is the outcome of almost every probe run.
+	scaledResourcesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "scaled_resources_total",
+			Help:      "Number of dependent resources processed by a scale flow, partitioned by outcome.",
+		},
+		[]string{"shoot_namespace", "operation", "kind", "outcome"},
+	)
+	// scaleFlowDurationSeconds observes the duration of scale flow runs which have scaled at least one resource or have failed.
+	scaleFlowDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "scale_flow_duration_seconds",
+			Help:      "Duration of scale flow runs which have scaled at least one resource or have failed.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+		},
+		[]string{"operation", "succeeded"},
+	)
+	// scaleLevelDurationSeconds observes the duration taken to scale all resources at a level of a scale flow.
+	scaleLevelDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "scale_level_duration_seconds",
+			Help:      "Duration taken to scale all resources at a level of a scale flow which has scaled at least one resource or has failed.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+		},
+		[]string{"operation", "level"},
+	)
+	// failOpen is set to 1 for as long as the prober of a shoot control namespace is fail-open, i.e. it has scaled up the
+	// dependent resources as they have been kept scaled down for longer than the configured maxScaleDownDuration.
+	failOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "fail_open",
+			Help:      "Set to 1 if the prober is fail-open as dependent resources have been kept scaled down for longer than maxScaleDownDuration, 0 otherwise.",
+		},
+		[]string{"shoot_namespace"},
+	)
+	// failOpenTotal counts the number of times the prober of a shoot control namespace has become fail-open.
+	failOpenTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "fail_open_total",
+			Help:      "Number of times the prober has become fail-open as dependent resources have been kept scaled down for longer than maxScaleDownDuration.",
+		},
+		[]string{"shoot_namespace"},
+	)
+	// decisionAlertsTotal counts the number of probe runs for which the decision matrix has decided to only alert.
+	decisionAlertsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "decision_alerts_total",
+			Help:      "Number of probe runs for which the decision matrix has decided to only alert, partitioned by the internal and external probe health.",
+		},
+		[]string{"shoot_namespace", "internal", "external"},
+	)
+	// externalProbeErrorsTotal counts the failures of the external probe per target, partitioned by whether they have been
+	// caused by the proxy via which the API server is reached or by the API server.
+	externalProbeErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "external_probe_errors_total",
+			Help:      "Number of failures of the external probe per target, partitioned by error class (proxy or apiserver).",
+		},
+		[]string{"shoot_namespace", "target", "error_class"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(scaledResourcesTotal, scaleFlowDurationSeconds, scaleLevelDurationSeconds, failOpen, failOpenTotal, decisionAlertsTotal, externalProbeErrorsTotal)
+}
+
+// recordScaleResultMetrics records the metrics for the given scale result of the shoot control namespace.
+func recordScaleResultMetrics(namespace string, result dwdScaler.Result) {
+	recordScaledResources(namespace, result.Operation, result.ResourceResults)
+	recordScaledResources(namespace, rollbackOperation, result.RolledBackResources)
+	if !hasEffect(result) {
+		return
+	}
+	scaleFlowDurationSeconds.WithLabelValues(result.Operation, strconv.FormatBool(result.Err == nil)).Observe(result.Duration.Seconds())
+	for _, levelResult := range result.LevelResults {
+		scaleLevelDurationSeconds.WithLabelValues(result.Operation, strconv.Itoa(levelResult.Level)).Observe(levelResult.Duration.Seconds())
+	}
+}
+
+func recordScaledResources(namespace string, operation string, resResults []dwdScaler.ResourceResult) {
+	for _, resResult := range resResults {
+		if resResult.Outcome == dwdScaler.ResourceSkippedAlreadyAtTarget {
+			continue
+		}
+		scaledResourcesTotal.WithLabelValues(namespace, operation, resResult.Ref.Kind, string(resResult.Outcome)).Inc()
+	}
+}
+
+// deleteMetrics deletes all series of the shoot control namespace so that they do not outlive its prober.
+func deleteMetrics(namespace string) {
+	namespaceLabels := prometheus.Labels{"shoot_namespace": namespace}
+	scaledResourcesTotal.DeletePartialMatch(namespaceLabels)
+	decisionAlertsTotal.DeletePartialMatch(namespaceLabels)
+	externalProbeErrorsTotal.DeletePartialMatch(namespaceLabels)
+	failOpen.DeletePartialMatch(namespaceLabels)
+	failOpenTotal.DeletePartialMatch(namespaceLabels)
+}
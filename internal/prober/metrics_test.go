@@ -0,0 +1,49 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDeleteMetricsDeletesOnlySeriesOfNamespace(t *testing.T) {
+	g := NewWithT(t)
+	const closedNamespace = "shoot--test--closed"
+	const otherNamespace = "shoot--test--other"
+	for _, namespace := range []string{closedNamespace, otherNamespace} {
+		scaledResourcesTotal.WithLabelValues(namespace, "scale-down", "Deployment", "succeeded").Inc()
+		decisionAlertsTotal.WithLabelValues(namespace, "Healthy", "Unhealthy").Inc()
+		externalProbeErrorsTotal.WithLabelValues(namespace, "default", "apiserver").Inc()
+		failOpen.WithLabelValues(namespace).Set(1)
+		failOpenTotal.WithLabelValues(namespace).Inc()
+	}
+
+	deleteMetrics(closedNamespace)
+
+	g.Expect(scaledResourcesTotal.DeleteLabelValues(closedNamespace, "scale-down", "Deployment", "succeeded")).To(BeFalse())
+	g.Expect(decisionAlertsTotal.DeleteLabelValues(closedNamespace, "Healthy", "Unhealthy")).To(BeFalse())
+	g.Expect(externalProbeErrorsTotal.DeleteLabelValues(closedNamespace, "default", "apiserver")).To(BeFalse())
+	g.Expect(failOpen.DeleteLabelValues(closedNamespace)).To(BeFalse())
+	g.Expect(failOpenTotal.DeleteLabelValues(closedNamespace)).To(BeFalse())
+	g.Expect(scaledResourcesTotal.DeleteLabelValues(otherNamespace, "scale-down", "Deployment", "succeeded")).To(BeTrue())
+	g.Expect(decisionAlertsTotal.DeleteLabelValues(otherNamespace, "Healthy", "Unhealthy")).To(BeTrue())
+	g.Expect(externalProbeErrorsTotal.DeleteLabelValues(otherNamespace, "default", "apiserver")).To(BeTrue())
+	g.Expect(failOpen.DeleteLabelValues(otherNamespace)).To(BeTrue())
+	g.Expect(failOpenTotal.DeleteLabelValues(otherNamespace)).To(BeTrue())
+}
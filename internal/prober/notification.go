@@ -0,0 +1,110 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"fmt"
+
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+)
+
+// notify sends a notification for the shoot namespace of the prober, if a notifier has been configured.
+func (p *Prober) notify(eventType napi.EventType, resources []string, errs []error, message string) {
+	if p.notifier == nil {
+		return
+	}
+	var errMessages []string
+	for _, err := range errs {
+		errMessages = append(errMessages, err.Error())
+	}
+	p.notifier.Notify(notifier.Notification{
+		Event:          eventType,
+		ShootNamespace: p.namespace,
+		Resources:      resources,
+		Errors:         errMessages,
+		Message:        message,
+	})
+}
+
+// notifyExternalHealthTransition notifies once the external probe has turned unhealthy. The errors of the external probe
+// targets which have caused the transition are part of the notification.
+func (p *Prober) notifyExternalHealthTransition(externalHealth papi.ProbeHealth) {
+	if externalHealth != papi.ProbeHealthUnhealthy || p.lastExternalHealth == papi.ProbeHealthUnhealthy {
+		return
+	}
+	var errs []error
+	for _, ep := range p.externalProbes {
+		if ep.status.lastErr != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", ep.target.Name, ep.status.lastErr))
+		}
+	}
+	p.notify(napi.EventTypeExternalProbeUnhealthy, nil, errs, fmt.Sprintf("external probe is unhealthy, policy: %s", p.externalProbePolicy()))
+}
+
+// notifyScaleDownStarted notifies that the prober has decided to scale down the dependent resources with the given names.
+// If no names are given, all dependent resources are considered.
+func (p *Prober) notifyScaleDownStarted(resourceNames []string) {
+	var resources []string
+	for _, resInfo := range p.config.DependentResourceInfos {
+		if len(resourceNames) == 0 || containsResourceName(resourceNames, resInfo.Ref.Name) {
+			resources = append(resources, formatResourceRef(*resInfo.Ref))
+		}
+	}
+	p.notify(napi.EventTypeScaleDownStarted, resources, nil, "probe health requires scale down")
+}
+
+// notifyScaleResult notifies about the outcome of a scale flow run which has scaled at least one resource or has failed.
+func (p *Prober) notifyScaleResult(result dwdScaler.Result) {
+	var eventType napi.EventType
+	switch {
+	case result.Operation == scaleDownOperation && result.Err != nil:
+		eventType = napi.EventTypeScaleDownFailed
+	case result.Operation == scaleDownOperation:
+		eventType = napi.EventTypeScaleDownCompleted
+	case result.Operation == scaleUpOperation && result.Err == nil:
+		eventType = napi.EventTypeScaleUpCompleted
+	default:
+		return
+	}
+	var resources []string
+	for _, resResult := range result.ScaledResources() {
+		resources = append(resources, formatResourceRef(resResult.Ref))
+	}
+	var errs []error
+	for _, resResult := range result.FailedResources() {
+		errs = append(errs, fmt.Errorf("%s: %w", formatResourceRef(resResult.Ref), resResult.Err))
+	}
+	if len(errs) == 0 && result.Err != nil {
+		errs = append(errs, result.Err)
+	}
+	p.notify(eventType, resources, errs, fmt.Sprintf("%s completed in %s", result.Operation, result.Duration))
+}
+
+func containsResourceName(resourceNames []string, name string) bool {
+	for _, resourceName := range resourceNames {
+		if resourceName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func formatResourceRef(ref autoscalingv1.CrossVersionObjectReference) string {
+	return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+}
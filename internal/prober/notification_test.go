@@ -0,0 +1,124 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/utils/pointer"
+)
+
+// recordingNotifier records all notifications instead of delivering them.
+type recordingNotifier struct {
+	notifications []notifier.Notification
+}
+
+func (r *recordingNotifier) Notify(notification notifier.Notification) {
+	r.notifications = append(r.notifications, notification)
+}
+
+var (
+	kcmRef = autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}
+	mcmRef = autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "machine-controller-manager", APIVersion: "apps/v1"}
+)
+
+func TestNotifyExternalHealthTransition(t *testing.T) {
+	g := NewWithT(t)
+	p, rn := createNotificationTestProber()
+	p.externalProbes[0].status.recordFailure(errNotIgnorable, 1, 0)
+
+	for _, externalHealth := range []papi.ProbeHealth{papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy, papi.ProbeHealthUnhealthy, papi.ProbeHealthUnknown, papi.ProbeHealthUnhealthy} {
+		p.notifyExternalHealthTransition(externalHealth)
+		p.lastExternalHealth = externalHealth
+	}
+	g.Expect(rn.notifications).To(HaveLen(2), "a notification should only be sent once the external probe turns unhealthy")
+	for _, notification := range rn.notifications {
+		g.Expect(notification.Event).To(Equal(napi.EventTypeExternalProbeUnhealthy))
+		g.Expect(notification.ShootNamespace).To(Equal("default"))
+		g.Expect(notification.Errors).To(ConsistOf("target external: " + errNotIgnorable.Error()))
+	}
+}
+
+func TestNotifyScaleDownStarted(t *testing.T) {
+	g := NewWithT(t)
+	p, rn := createNotificationTestProber()
+
+	p.notifyScaleDownStarted(nil)
+	p.notifyScaleDownStarted([]string{mcmRef.Name})
+	g.Expect(rn.notifications).To(HaveLen(2))
+	g.Expect(rn.notifications[0].Resources).To(ConsistOf("Deployment/kube-controller-manager", "Deployment/machine-controller-manager"))
+	g.Expect(rn.notifications[1].Resources).To(ConsistOf("Deployment/machine-controller-manager"))
+}
+
+func TestNotifyScaleResult(t *testing.T) {
+	scaleErr := errors.New("timed out")
+	table := []struct {
+		description       string
+		result            dwdScaler.Result
+		expectedEvent     napi.EventType
+		expectedResources []string
+		expectedErrors    []string
+	}{
+		{"successful scale-down", dwdScaler.Result{Operation: scaleDownOperation, ResourceResults: []dwdScaler.ResourceResult{{Ref: kcmRef, Outcome: dwdScaler.ResourceScaled}, {Ref: mcmRef, Outcome: dwdScaler.ResourceSkippedAlreadyAtTarget}}},
+			napi.EventTypeScaleDownCompleted, []string{"Deployment/kube-controller-manager"}, nil},
+		{"failed scale-down", dwdScaler.Result{Operation: scaleDownOperation, ResourceResults: []dwdScaler.ResourceResult{{Ref: kcmRef, Outcome: dwdScaler.ResourceScaled}, {Ref: mcmRef, Outcome: dwdScaler.ResourceFailed, Err: scaleErr}}, Err: scaleErr},
+			napi.EventTypeScaleDownFailed, []string{"Deployment/kube-controller-manager"}, []string{"Deployment/machine-controller-manager: timed out"}},
+		{"failed scale-down without resource results", dwdScaler.Result{Operation: scaleDownOperation, Err: scaleErr},
+			napi.EventTypeScaleDownFailed, nil, []string{"timed out"}},
+		{"successful scale-up", dwdScaler.Result{Operation: scaleUpOperation, ResourceResults: []dwdScaler.ResourceResult{{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled}}},
+			napi.EventTypeScaleUpCompleted, []string{"Deployment/machine-controller-manager"}, nil},
+		{"failed scale-up", dwdScaler.Result{Operation: scaleUpOperation, Err: scaleErr}, "", nil, nil},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			p, rn := createNotificationTestProber()
+			p.notifyScaleResult(entry.result)
+			if entry.expectedEvent == "" {
+				g.Expect(rn.notifications).To(BeEmpty())
+				return
+			}
+			g.Expect(rn.notifications).To(HaveLen(1))
+			g.Expect(rn.notifications[0].Event).To(Equal(entry.expectedEvent))
+			g.Expect(rn.notifications[0].Resources).To(Equal(entry.expectedResources))
+			g.Expect(rn.notifications[0].Errors).To(Equal(entry.expectedErrors))
+		})
+	}
+}
+
+func TestNotifyWithoutNotifier(t *testing.T) {
+	p := NewProber(context.Background(), "default", &papi.Config{}, nil, nil, nil, nil, nil, proberTestLogger)
+	p.notify(napi.EventTypeScaleDownStarted, nil, nil, "should not panic")
+}
+
+func createNotificationTestProber() (*Prober, *recordingNotifier) {
+	config := &papi.Config{
+		DependentResourceInfos: []papi.DependentResourceInfo{{Ref: &kcmRef}, {Ref: &mcmRef}},
+		ExternalProbePolicy:    (*papi.ExternalProbePolicy)(pointer.String(string(papi.ExternalProbePolicyAny))),
+	}
+	rn := &recordingNotifier{}
+	return NewProber(context.Background(), "default", config, nil, nil, nil, nil, rn, proberTestLogger), rn
+}
@@ -16,9 +16,11 @@ package prober
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
 	"github.com/gardener/dependency-watchdog/internal/util"
 
@@ -26,6 +28,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -42,15 +45,38 @@ type Prober struct {
 	client              client.Client
 	scaler              dwdScaler.Scaler
 	shootClientCreator  ShootClientCreator
+	eventRecorder       record.EventRecorder
+	notifier            notifier.Notifier
 	internalProbeStatus probeStatus
-	externalProbeStatus probeStatus
+	// externalProbes capture the status of the external probe for each of the external probe targets.
+	externalProbes []*externalProbe
+	// probeInterval is the interval with which the probe is currently run. It only deviates from the configured ProbeInterval
+	// if the adaptive probe interval is enabled.
+	probeInterval time.Duration
+	// lastExternalHealth is the health of the external probe as computed by the last run in which the external probe was required.
+	lastExternalHealth papi.ProbeHealth
+	// healthy is shared between copies of the Prober held by the Manager and is therefore a pointer.
+	healthy *atomic.Bool
+	// unhealthySince is the time at which the decision matrix has first decided to scale down. It is reset once the decision
+	// matrix decides to scale up and is used to determine the escalation stage.
+	unhealthySince time.Time
+	// scaledDownSince is the time at which the prober has first decided to scale down the dependent resources. It is reset
+	// once the dependent resources have been scaled up.
+	scaledDownSince time.Time
+	// failOpen is true if the dependent resources have been scaled up as they have been kept scaled down for longer than
+	// MaxScaleDownDuration, and the external probe has not been healthy since.
+	failOpen bool
+	// verdict captures the verdict of the prober which is published on the Cluster resource.
+	verdict verdictState
+	// probeStatusResource captures the state of the prober which is exposed via the ProbeStatus resource.
+	probeStatusResource probeStatusResourceState
 	ctx                 context.Context
 	cancelFn            context.CancelFunc
 	l                   logr.Logger
 }
 
 // NewProber creates a new Prober
-func NewProber(parentCtx context.Context, namespace string, config *papi.Config, ctrlClient client.Client, scaler dwdScaler.Scaler, shootClientCreator ShootClientCreator, logger logr.Logger) *Prober {
+func NewProber(parentCtx context.Context, namespace string, config *papi.Config, ctrlClient client.Client, scaler dwdScaler.Scaler, shootClientCreator ShootClientCreator, eventRecorder record.EventRecorder, notifier notifier.Notifier, logger logr.Logger) *Prober {
 	pLogger := logger.WithValues("shootNamespace", namespace)
 	ctx, cancelFn := context.WithCancel(parentCtx)
 	return &Prober{
@@ -59,6 +85,10 @@ func NewProber(parentCtx context.Context, namespace string, config *papi.Config,
 		client:             ctrlClient,
 		scaler:             scaler,
 		shootClientCreator: shootClientCreator,
+		eventRecorder:      eventRecorder,
+		notifier:           notifier,
+		externalProbes:     newExternalProbes(config),
+		healthy:            new(atomic.Bool),
 		ctx:                ctx,
 		cancelFn:           cancelFn,
 		l:                  pLogger,
@@ -68,6 +98,7 @@ func NewProber(parentCtx context.Context, namespace string, config *papi.Config,
 // Close closes a probe
 func (p *Prober) Close() {
 	p.cancelFn()
+	deleteMetrics(p.namespace)
 }
 
 // IsClosed checks if the context of the prober is cancelled or not.
@@ -80,59 +111,93 @@ func (p *Prober) IsClosed() bool {
 	}
 }
 
-// Run starts a probe which will run with a configured interval and jitter.
+// IsHealthy checks if both the internal and the external probe were healthy in the last run of the prober.
+func (p *Prober) IsHealthy() bool {
+	return p.healthy.Load()
+}
+
+// Run starts a probe which will run with a configured interval and jitter. If the adaptive probe interval is enabled, the
+// interval is adapted after every run of the probe.
 func (p *Prober) Run() {
 	_ = util.SleepWithContext(p.ctx, p.config.InitialDelay.Duration)
-	wait.JitterUntilWithContext(p.ctx, p.probe, p.config.ProbeInterval.Duration, *p.config.BackoffJitterFactor, true)
+	p.probeInterval = p.config.ProbeInterval.Duration
+	for p.ctx.Err() == nil {
+		p.probe(p.ctx)
+		_ = util.SleepWithContext(p.ctx, wait.Jitter(p.probeInterval, *p.config.BackoffJitterFactor))
+	}
 }
 
 func (p *Prober) probe(ctx context.Context) {
-	internalShootClient, err := p.setupProbeClient(ctx, p.namespace, p.config.InternalKubeConfigSecretName)
+	if p.maxScaleDownDurationExceeded() {
+		p.openFailOpen(ctx)
+	}
+	internalShootClient, err := p.setupProbeClient(ctx, p.namespace, p.config.InternalKubeConfigSecretName, util.KubeConfigOverrides{})
 	if err != nil {
 		p.l.Error(err, "Failed to create shoot client using internal secret, ignoring error, internal probe will be re-attempted")
 		return
 	}
-	p.probeInternal(internalShootClient)
-	if p.internalProbeStatus.isHealthy(*p.config.SuccessThreshold) {
-		externalShootClient, err := p.setupProbeClient(ctx, p.namespace, p.config.ExternalKubeConfigSecretName)
-		if err != nil {
-			p.l.Error(err, "Failed to create shoot client using external secret, ignoring error, probe will be re-attempted")
-			return
-		}
-		p.probeExternal(externalShootClient)
-		// based on the external probe result it will either scale up or scale down
-		if p.externalProbeStatus.isUnhealthy(*p.config.FailureThreshold) {
-			p.l.Info("External probe is un-healthy, checking if scale down is already done or is still pending")
-			err := p.scaler.ScaleDown(ctx)
-			if err != nil {
-				p.l.Error(err, "Failed to scale down resources")
-			}
+	p.probeInternal(ctx, internalShootClient)
+	if ctx.Err() != nil {
+		return
+	}
+	internalHealth := p.internalProbeStatus.health(*p.config.SuccessThreshold, *p.config.FailureThreshold)
+	externalHealth := papi.ProbeHealthUnknown
+	// the external probe is only run if its result can influence the decision
+	if p.isExternalProbeRequired(internalHealth) {
+		if !p.probeExternalTargets(ctx) || ctx.Err() != nil {
 			return
 		}
-		if p.externalProbeStatus.isHealthy(*p.config.SuccessThreshold) {
-			p.l.Info("External probe is healthy, checking if scale up is already done or is still pending")
-			err := p.scaler.ScaleUp(ctx)
-			if err != nil {
-				p.l.Error(err, "Failed to scale up resources")
-			}
+		var targetHealths map[string]papi.ProbeHealth
+		externalHealth, targetHealths = p.externalHealth()
+		if len(targetHealths) > 1 {
+			p.l.Info("External probe health computed from its targets", "policy", p.externalProbePolicy(), "health", externalHealth, "targets", targetHealths)
 		}
+		p.adaptProbeInterval(externalHealth)
+		p.notifyExternalHealthTransition(externalHealth)
+		p.lastExternalHealth = externalHealth
+	} else {
+		p.relaxProbeInterval()
+	}
+	switch {
+	case internalHealth == papi.ProbeHealthHealthy && externalHealth == papi.ProbeHealthHealthy:
+		p.healthy.Store(true)
+	case internalHealth != papi.ProbeHealthHealthy || externalHealth == papi.ProbeHealthUnhealthy:
+		p.healthy.Store(false)
 	}
+	p.act(ctx, p.decide(internalHealth, externalHealth))
+	p.updateVerdict(internalHealth, externalHealth)
+	p.publishVerdict(ctx)
+	p.publishProbeStatus(ctx)
 }
 
-func (p *Prober) setupProbeClient(ctx context.Context, namespace string, kubeConfigSecretName string) (kubernetes.Interface, error) {
-	shootClient, err := p.shootClientCreator.CreateClient(ctx, p.l, namespace, kubeConfigSecretName, p.config.ProbeTimeout.Duration)
+// createScaleContext returns a copy of the context which carries the current status of the internal and external probe.
+// Scale conditions of the dependent resources are evaluated against it.
+func (p *Prober) createScaleContext(ctx context.Context) context.Context {
+	externalResult, externalTargetResults := p.externalProbeResult()
+	return dwdScaler.ContextWithProbeStatus(ctx, dwdScaler.ProbeStatus{
+		Internal:        p.internalProbeStatus.toProbeResult(*p.config.SuccessThreshold),
+		External:        externalResult,
+		ExternalTargets: externalTargetResults,
+	})
+}
+
+func (p *Prober) setupProbeClient(ctx context.Context, namespace string, kubeConfigSecretName string, overrides util.KubeConfigOverrides) (kubernetes.Interface, error) {
+	shootClient, err := p.shootClientCreator.CreateClient(ctx, p.l, namespace, kubeConfigSecretName, p.config.ProbeTimeout.Duration, overrides)
 	if err != nil {
 		return nil, err
 	}
 	return shootClient, nil
 }
 
-func (p *Prober) probeInternal(shootClient kubernetes.Interface) {
-	backOffIfNeeded(&p.internalProbeStatus)
+func (p *Prober) probeInternal(ctx context.Context, shootClient kubernetes.Interface) {
+	if err := backOffIfNeeded(ctx, &p.internalProbeStatus); err != nil {
+		p.l.Info("Prober has been closed while backing off, skipping internal probe")
+		return
+	}
 	err := p.doProbe(shootClient)
 	if err != nil {
 		if !p.internalProbeStatus.canIgnoreProbeError(err) {
-			p.internalProbeStatus.recordFailure(err, *p.config.FailureThreshold, p.config.InternalProbeFailureBackoffDuration.Duration)
+			p.internalProbeStatus.recordFailure(err, *p.config.FailureThreshold, p.internalProbeFailureBackoff())
 			p.l.Info("Recording internal probe failure, Skipping external probe and scaling operation", "err", err.Error(), "failedAttempts", p.internalProbeStatus.errorCount, "failureThreshold", p.config.FailureThreshold)
 		} else {
 			p.internalProbeStatus.handleIgnorableError(err)
@@ -144,28 +209,43 @@ func (p *Prober) probeInternal(shootClient kubernetes.Interface) {
 	p.l.Info("Internal probe is successful", "successfulAttempts", p.internalProbeStatus.successCount, "successThreshold", p.config.SuccessThreshold)
 }
 
-func (p *Prober) probeExternal(shootClient kubernetes.Interface) {
-	backOffIfNeeded(&p.externalProbeStatus)
+func (p *Prober) probeExternal(ctx context.Context, shootClient kubernetes.Interface, ep *externalProbe) {
+	if err := backOffIfNeeded(ctx, &ep.status); err != nil {
+		p.l.Info("Prober has been closed while backing off, skipping external probe", "target", ep.target.Name)
+		return
+	}
 	err := p.doProbe(shootClient)
 	if err != nil {
-		if !p.externalProbeStatus.canIgnoreProbeError(err) {
-			p.externalProbeStatus.recordFailure(err, *p.config.FailureThreshold, 0)
-			p.l.Info("Recording external probe failure", "err", err.Error(), "failedAttempts", p.externalProbeStatus.errorCount, "failureThreshold", p.config.FailureThreshold)
+		if !ep.status.canIgnoreProbeError(err) {
+			errorClass := classifyExternalProbeError(err)
+			externalProbeErrorsTotal.WithLabelValues(p.namespace, ep.target.Name, errorClass).Inc()
+			ep.status.recordFailure(err, *p.config.FailureThreshold, 0)
+			p.l.Info("Recording external probe failure", "target", ep.target.Name, "errorClass", errorClass, "err", err.Error(), "failedAttempts", ep.status.errorCount, "failureThreshold", p.config.FailureThreshold)
 			return
 		}
-		p.externalProbeStatus.handleIgnorableError(err)
-		p.l.Info("External probe was not successful. ignoring this error", "err", err.Error())
+		ep.status.handleIgnorableError(err)
+		p.l.Info("External probe was not successful. ignoring this error", "target", ep.target.Name, "err", err.Error())
 		return
 	}
-	p.externalProbeStatus.recordSuccess(*p.config.SuccessThreshold)
-	p.l.Info("External probe is successful", "successfulAttempts", p.externalProbeStatus.successCount, "successThreshold", p.config.SuccessThreshold)
+	ep.status.recordSuccess(*p.config.SuccessThreshold)
+	p.l.Info("External probe is successful", "target", ep.target.Name, "successfulAttempts", ep.status.successCount, "successThreshold", p.config.SuccessThreshold)
 }
 
-func backOffIfNeeded(ps *probeStatus) {
-	if ps.backOff != nil {
-		<-ps.backOff.C
+// backOffIfNeeded waits till the back off of the probe, if any, has expired. It returns an error if the context is
+// cancelled before, so that a closed prober does not wait for the back off, which can be as long as a few minutes.
+func backOffIfNeeded(ctx context.Context, ps *probeStatus) error {
+	if ps.backOff == nil {
+		return nil
+	}
+	defer func() {
 		ps.backOff.Stop()
 		ps.backOff = nil
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ps.backOff.C:
+		return nil
 	}
 }
 
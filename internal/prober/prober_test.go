@@ -19,16 +19,20 @@ package prober
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	mockdiscovery "github.com/gardener/dependency-watchdog/internal/mock/client-go/discovery"
 	mockinterface "github.com/gardener/dependency-watchdog/internal/mock/client-go/kubernetes"
 	mockprober "github.com/gardener/dependency-watchdog/internal/mock/prober"
 	mockscaler "github.com/gardener/dependency-watchdog/internal/mock/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/util"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
@@ -38,6 +42,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -80,10 +85,10 @@ func TestInternalProbeErrorCount(t *testing.T) {
 			setupProberTest(t)
 			config = createConfig(1, 1, metav1.Duration{Duration: 4 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
 
-			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
 			mki.EXPECT().Discovery().Return(mdi).AnyTimes()
 			mdi.EXPECT().ServerVersion().Return(nil, entry.err).AnyTimes()
-			mds.EXPECT().ScaleUp(gomock.Any()).Return(nil).AnyTimes()
+			mds.EXPECT().ScaleUp(gomock.Any()).Return(scaler.Result{}).AnyTimes()
 
 			runProberAndCheckStatus(t, 12*time.Millisecond, entry)
 		})
@@ -101,12 +106,13 @@ func TestHealthyProbesShouldRunScaleUp(t *testing.T) {
 			setupProberTest(t)
 			config = createConfig(1, 1, metav1.Duration{Duration: 4 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
 
-			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
 			mki.EXPECT().Discovery().Return(mdi).AnyTimes().AnyTimes()
 			mdi.EXPECT().ServerVersion().Return(nil, nil).AnyTimes()
-			mds.EXPECT().ScaleUp(gomock.Any()).Return(probeStatusEntry.err).AnyTimes()
+			mds.EXPECT().ScaleUp(gomock.Any()).Return(scaler.Result{Err: probeStatusEntry.err}).AnyTimes()
 
-			runProberAndCheckStatus(t, 12*time.Millisecond, probeStatusEntry)
+			p := runProberAndCheckStatus(t, 12*time.Millisecond, probeStatusEntry)
+			NewWithT(t).Expect(p.IsHealthy()).To(BeTrue())
 		})
 	}
 }
@@ -123,7 +129,7 @@ func TestExternalProbeFailingShouldRunScaleDown(t *testing.T) {
 			config = createConfig(1, 2, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
 			runCounter := 0
 
-			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
 			mki.EXPECT().Discovery().Return(mdi).AnyTimes()
 			mdi.EXPECT().ServerVersion().DoAndReturn(func() (*version.Info, error) {
 				runCounter++
@@ -132,13 +138,74 @@ func TestExternalProbeFailingShouldRunScaleDown(t *testing.T) {
 				}
 				return nil, errNotIgnorable
 			}).AnyTimes()
-			mds.EXPECT().ScaleDown(gomock.Any()).Return(probeStatusEntry.err).AnyTimes()
+			mds.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(scaler.Result{Err: probeStatusEntry.err}).AnyTimes()
 
-			runProberAndCheckStatus(t, 20*time.Millisecond, probeStatusEntry)
+			p := runProberAndCheckStatus(t, 20*time.Millisecond, probeStatusEntry)
+			NewWithT(t).Expect(p.IsHealthy()).To(BeFalse())
 		})
 	}
 }
 
+func TestExternalProbeFailingBeyondMaxScaleDownDurationShouldFailOpen(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.MaxScaleDownDuration = &metav1.Duration{Duration: 10 * time.Millisecond}
+	config.DependentResourceInfos = []papi.DependentResourceInfo{
+		{Ref: &autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}},
+	}
+	runCounter := 0
+
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+	mki.EXPECT().Discovery().Return(mdi).AnyTimes()
+	mdi.EXPECT().ServerVersion().DoAndReturn(func() (*version.Info, error) {
+		runCounter++
+		if runCounter%2 == 1 {
+			return nil, nil
+		}
+		return nil, errNotIgnorable
+	}).AnyTimes()
+	mds.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(scaler.Result{ResourceResults: []scaler.ResourceResult{{Outcome: scaler.ResourceScaled}}}).MinTimes(1)
+	mds.EXPECT().ScaleUp(gomock.Any()).Return(scaler.Result{}).MinTimes(1)
+
+	recorder := record.NewFakeRecorder(100)
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, recorder, nil, proberTestLogger)
+	runProber(p, 50*time.Millisecond)
+
+	g.Expect(p.failOpen).To(BeTrue())
+	g.Expect(p.IsHealthy()).To(BeFalse())
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	var failOpenEvents []string
+	for _, event := range events {
+		if strings.Contains(event, eventReasonFailOpen) {
+			failOpenEvents = append(failOpenEvents, event)
+		}
+	}
+	g.Expect(failOpenEvents).To(HaveLen(1), "fail-open should only be alerted once on entering it")
+}
+
+func TestCloseFailOpen(t *testing.T) {
+	g := NewWithT(t)
+	setupProberTest(t)
+	config = createConfig(1, 1, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
+	config.MaxScaleDownDuration = &metav1.Duration{Duration: time.Millisecond}
+	recorder := record.NewFakeRecorder(10)
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, recorder, nil, proberTestLogger)
+
+	p.markScaledDown()
+	g.Expect(p.scaledDownSince.IsZero()).To(BeFalse())
+	time.Sleep(2 * time.Millisecond)
+	g.Expect(p.maxScaleDownDurationExceeded()).To(BeTrue())
+
+	p.failOpen = true
+	g.Expect(p.maxScaleDownDurationExceeded()).To(BeFalse())
+	p.closeFailOpen(context.Background())
+	g.Expect(p.failOpen).To(BeFalse())
+}
+
 func TestUnchangedExternalErrorCountForIgnorableErrors(t *testing.T) {
 	table := []probeStatusEntry{
 		{"Forbidden request error is returned by pingKubeApiServer", apierrors.NewForbidden(schema.GroupResource{}, "test", errors.New("forbidden")), 1, 0, 0, 0},
@@ -152,7 +219,7 @@ func TestUnchangedExternalErrorCountForIgnorableErrors(t *testing.T) {
 			config = createConfig(1, 2, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
 			runCounter := 0
 
-			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
+			msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mki, nil).AnyTimes()
 			mki.EXPECT().Discovery().Return(mdi).AnyTimes()
 			mdi.EXPECT().ServerVersion().DoAndReturn(func() (*version.Info, error) {
 				runCounter++
@@ -179,7 +246,7 @@ func TestInternalProbeShouldNotRunIfClientNotCreated(t *testing.T) {
 		expectedExternalProbeErrorCount:   0,
 	}
 	config = createConfig(1, 2, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
-	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, err).AnyTimes()
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, err).AnyTimes()
 	runProberAndCheckStatus(t, 12*time.Millisecond, entry)
 }
 
@@ -196,7 +263,7 @@ func TestExternalProbeShouldNotRunIfClientNotCreated(t *testing.T) {
 		expectedExternalProbeErrorCount:   0,
 	}
 	config = createConfig(1, 2, metav1.Duration{Duration: 5 * time.Millisecond}, metav1.Duration{Duration: time.Microsecond}, 0.2)
-	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, logr.Logger, string, string, time.Duration) (kubernetes.Interface, error) {
+	msc.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, logr.Logger, string, string, time.Duration, util.KubeConfigOverrides) (kubernetes.Interface, error) {
 		counter++
 		if counter%2 == 1 {
 			return mki, nil
@@ -209,16 +276,17 @@ func TestExternalProbeShouldNotRunIfClientNotCreated(t *testing.T) {
 	runProberAndCheckStatus(t, 12*time.Millisecond, entry)
 }
 
-func runProberAndCheckStatus(t *testing.T, duration time.Duration, probeStatusEntry probeStatusEntry) {
+func runProberAndCheckStatus(t *testing.T, duration time.Duration, probeStatusEntry probeStatusEntry) *Prober {
 	g := NewWithT(t)
-	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, proberTestLogger)
+	p := NewProber(context.Background(), "default", config, fakeClient, mds, msc, &record.FakeRecorder{}, nil, proberTestLogger)
 	g.Expect(p.IsClosed()).To(BeFalse())
 
 	runProber(p, duration)
 
 	g.Expect(p.IsClosed()).To(BeTrue())
 	checkProbeStatus(t, p.internalProbeStatus, probeStatusEntry.expectedInternalProbeSuccessCount, probeStatusEntry.expectedInternalProbeErrorCount)
-	checkProbeStatus(t, p.externalProbeStatus, probeStatusEntry.expectedExternalProbeSuccessCount, probeStatusEntry.expectedExternalProbeErrorCount)
+	checkProbeStatus(t, p.externalProbes[0].status, probeStatusEntry.expectedExternalProbeSuccessCount, probeStatusEntry.expectedExternalProbeErrorCount)
+	return p
 }
 
 func runProber(p *Prober, d time.Duration) {
@@ -17,6 +17,8 @@ package prober
 import (
 	"time"
 
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -24,7 +26,11 @@ type probeStatus struct {
 	successCount int
 	errorCount   int
 	lastErr      error
-	backOff      *time.Timer
+	// lastErrTime is the time at which lastErr has been recorded.
+	lastErrTime time.Time
+	backOff     *time.Timer
+	// backOffCount is the number of consecutive times the probe has been backed off as failureThreshold has been reached.
+	backOffCount int
 }
 
 func (ps *probeStatus) canIgnoreProbeError(err error) bool {
@@ -47,8 +53,10 @@ func (ps *probeStatus) recordFailure(err error, failureThreshold int, failureThr
 		ps.errorCount++
 	}
 	ps.lastErr = err
+	ps.lastErrTime = time.Now()
 	ps.successCount = 0
 	if ps.isUnhealthy(failureThreshold) {
+		ps.backOffCount++
 		ps.resetBackoff(failureThresholdBackoffDuration)
 	}
 }
@@ -56,6 +64,8 @@ func (ps *probeStatus) recordFailure(err error, failureThreshold int, failureThr
 func (ps *probeStatus) recordSuccess(successThreshold int) {
 	ps.errorCount = 0
 	ps.lastErr = nil
+	ps.lastErrTime = time.Time{}
+	ps.backOffCount = 0
 	if ps.successCount < successThreshold {
 		ps.successCount++
 	}
@@ -76,3 +86,22 @@ func (ps *probeStatus) isHealthy(successThreshold int) bool {
 func (ps *probeStatus) isUnhealthy(failureThreshold int) bool {
 	return ps.errorCount >= failureThreshold
 }
+
+// health determines the health of the probe based on the success and failure thresholds.
+func (ps *probeStatus) health(successThreshold int, failureThreshold int) papi.ProbeHealth {
+	if ps.isHealthy(successThreshold) {
+		return papi.ProbeHealthHealthy
+	}
+	if ps.isUnhealthy(failureThreshold) {
+		return papi.ProbeHealthUnhealthy
+	}
+	return papi.ProbeHealthUnknown
+}
+
+func (ps *probeStatus) toProbeResult(successThreshold int) dwdScaler.ProbeResult {
+	return dwdScaler.ProbeResult{
+		Healthy:      ps.isHealthy(successThreshold),
+		SuccessCount: ps.successCount,
+		ErrorCount:   ps.errorCount,
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdv1alpha1 "github.com/gardener/dependency-watchdog/api/v1alpha1"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolledBackOutcome is the outcome recorded in the scale history for resources which have been restored after a failed scale-down.
+const rolledBackOutcome = "RolledBack"
+
+// probeStatusResourceState captures the state of the prober which is only exposed via the ProbeStatus resource and when
+// the resource has last been updated.
+type probeStatusResourceState struct {
+	// scaleHistory are the most recent scale flows which have scaled at least one resource or have failed, latest first.
+	scaleHistory []dwdv1alpha1.ScaleFlowRecord
+	// published is the status as last written, without its LastUpdateTime.
+	published *dwdv1alpha1.ProbeStatusStatus
+	// publishedAt is the time at which the status has last been written.
+	publishedAt time.Time
+}
+
+func (p *Prober) isProbeStatusResourceEnabled() bool {
+	return p.config.ProbeStatusResource != nil && p.config.ProbeStatusResource.Enabled != nil && *p.config.ProbeStatusResource.Enabled
+}
+
+// recordScaleHistory adds the given result of a scale flow run to the scale history, dropping the oldest flows once
+// the configured ScaleHistoryLimit is exceeded.
+func (p *Prober) recordScaleHistory(result dwdScaler.Result) {
+	if !p.isProbeStatusResourceEnabled() {
+		return
+	}
+	record := dwdv1alpha1.ScaleFlowRecord{
+		Operation: result.Operation,
+		StartTime: metav1.NewTime(time.Now().Add(-result.Duration)),
+		Duration:  metav1.Duration{Duration: result.Duration},
+	}
+	if result.Err != nil {
+		record.Error = result.Err.Error()
+	}
+	for _, resResult := range result.ResourceResults {
+		record.Resources = append(record.Resources, toScaleResourceRecord(resResult, string(resResult.Outcome)))
+	}
+	for _, resResult := range result.RolledBackResources {
+		outcome := rolledBackOutcome
+		if resResult.Err != nil {
+			outcome = string(dwdScaler.ResourceFailed)
+		}
+		record.Resources = append(record.Resources, toScaleResourceRecord(resResult, outcome))
+	}
+	history := append([]dwdv1alpha1.ScaleFlowRecord{record}, p.probeStatusResource.scaleHistory...)
+	if limit := *p.config.ProbeStatusResource.ScaleHistoryLimit; len(history) > limit {
+		history = history[:limit]
+	}
+	p.probeStatusResource.scaleHistory = history
+}
+
+func toScaleResourceRecord(resResult dwdScaler.ResourceResult, outcome string) dwdv1alpha1.ScaleResourceRecord {
+	record := dwdv1alpha1.ScaleResourceRecord{
+		Resource:       formatResourceRef(resResult.Ref),
+		Outcome:        outcome,
+		ReplicasBefore: resResult.ReplicasBefore,
+		ReplicasAfter:  resResult.ReplicasAfter,
+	}
+	if resResult.Err != nil {
+		record.Error = resResult.Err.Error()
+	}
+	return record
+}
+
+// buildProbeStatus builds the status of the ProbeStatus resource from the current state of the prober.
+func (p *Prober) buildProbeStatus() dwdv1alpha1.ProbeStatusStatus {
+	successThreshold, failureThreshold := *p.config.SuccessThreshold, *p.config.FailureThreshold
+	status := dwdv1alpha1.ProbeStatusStatus{
+		Internal:            toProbeStatusResult(&p.internalProbeStatus, p.verdict.current.Internal),
+		External:            dwdv1alpha1.ProbeResult{Health: dwdv1alpha1.ProbeHealth(p.verdict.current.External.Health)},
+		ScaledDownResources: p.verdict.current.ScaledDownResources,
+		ScaledDownSince:     p.verdict.current.ScaledDownSince,
+		FailOpen:            p.failOpen,
+		ScaleHistory:        p.probeStatusResource.scaleHistory,
+		Configuration:       p.buildProbeConfiguration(),
+	}
+	if !p.verdict.current.External.LastTransitionTime.IsZero() {
+		status.External.LastTransitionTime = p.verdict.current.External.LastTransitionTime.DeepCopy()
+	}
+	for _, ep := range p.externalProbes {
+		targetVerdict := papi.ProbeVerdict{Health: ep.status.health(successThreshold, failureThreshold)}
+		targetResult := toProbeStatusResult(&ep.status, targetVerdict)
+		status.ExternalTargets = append(status.ExternalTargets, dwdv1alpha1.ExternalTargetResult{Name: ep.target.Name, ProbeResult: targetResult})
+		status.External.SuccessCount += targetResult.SuccessCount
+		status.External.ErrorCount += targetResult.ErrorCount
+		if targetResult.LastError != nil && (status.External.LastError == nil || targetResult.LastError.Time.After(status.External.LastError.Time.Time)) {
+			status.External.LastError = targetResult.LastError
+		}
+	}
+	return status
+}
+
+func toProbeStatusResult(ps *probeStatus, verdict papi.ProbeVerdict) dwdv1alpha1.ProbeResult {
+	result := dwdv1alpha1.ProbeResult{
+		Health:       dwdv1alpha1.ProbeHealth(verdict.Health),
+		SuccessCount: ps.successCount,
+		ErrorCount:   ps.errorCount,
+	}
+	if result.Health == "" {
+		result.Health = dwdv1alpha1.ProbeHealthUnknown
+	}
+	if !verdict.LastTransitionTime.IsZero() {
+		result.LastTransitionTime = verdict.LastTransitionTime.DeepCopy()
+	}
+	if ps.lastErr != nil {
+		result.LastError = &dwdv1alpha1.ProbeError{
+			Class:   classifyProbeError(ps.lastErr),
+			Message: ps.lastErr.Error(),
+			Time:    metav1.NewTime(ps.lastErrTime),
+		}
+	}
+	return result
+}
+
+func (p *Prober) buildProbeConfiguration() dwdv1alpha1.ProbeConfiguration {
+	configuration := dwdv1alpha1.ProbeConfiguration{
+		ProbeInterval:          metav1.Duration{Duration: p.probeInterval},
+		SuccessThreshold:       *p.config.SuccessThreshold,
+		FailureThreshold:       *p.config.FailureThreshold,
+		ExternalProbePolicy:    string(p.externalProbePolicy()),
+		ScaleDownFailurePolicy: string(*p.config.ScaleDownFailurePolicy),
+		MaxScaleDownDuration:   p.config.MaxScaleDownDuration,
+	}
+	for _, resInfo := range p.config.DependentResourceInfos {
+		configuration.DependentResources = append(configuration.DependentResources, formatResourceRef(*resInfo.Ref))
+	}
+	return configuration
+}
+
+// classifyProbeError classifies the error with which a probe has failed.
+func classifyProbeError(err error) dwdv1alpha1.ProbeErrorClass {
+	var netErr net.Error
+	switch {
+	case util.IsProxyError(err):
+		return dwdv1alpha1.ProbeErrorClassProxy
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return dwdv1alpha1.ProbeErrorClassTimeout
+	case apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err):
+		return dwdv1alpha1.ProbeErrorClassUnauthorized
+	case apierrors.IsTooManyRequests(err):
+		return dwdv1alpha1.ProbeErrorClassThrottled
+	}
+	var apiStatus apierrors.APIStatus
+	if errors.As(err, &apiStatus) {
+		return dwdv1alpha1.ProbeErrorClassAPIServer
+	}
+	return dwdv1alpha1.ProbeErrorClassNetwork
+}
+
+// publishProbeStatus writes the state of the prober to the ProbeStatus resource in the shoot control namespace, creating
+// it with the Cluster resource of the shoot as its owner if it does not exist yet. The resource is only updated if the
+// status has changed and at most once per configured MinUpdateInterval. Failures are logged and retried with the next probe run.
+func (p *Prober) publishProbeStatus(ctx context.Context) {
+	if !p.isProbeStatusResourceEnabled() {
+		return
+	}
+	status := p.buildProbeStatus()
+	if p.probeStatusResource.published != nil && equality.Semantic.DeepEqual(*p.probeStatusResource.published, status) {
+		return
+	}
+	if time.Since(p.probeStatusResource.publishedAt) < p.config.ProbeStatusResource.MinUpdateInterval.Duration {
+		return
+	}
+	probeStatus, err := p.getOrCreateProbeStatus(ctx)
+	if err != nil {
+		p.l.Error(err, "Failed to get or create probe status, will be re-attempted with the next probe run")
+		return
+	}
+	probeStatus.Status = *status.DeepCopy()
+	probeStatus.Status.LastUpdateTime = metav1.Now()
+	if err = p.client.Status().Update(ctx, probeStatus); err != nil {
+		p.l.Error(err, "Failed to update probe status, will be re-attempted with the next probe run")
+		return
+	}
+	p.probeStatusResource.published = &status
+	p.probeStatusResource.publishedAt = time.Now()
+}
+
+func (p *Prober) getOrCreateProbeStatus(ctx context.Context) (*dwdv1alpha1.ProbeStatus, error) {
+	probeStatus := &dwdv1alpha1.ProbeStatus{}
+	key := client.ObjectKey{Namespace: p.namespace, Name: dwdv1alpha1.ProbeStatusName}
+	err := p.client.Get(ctx, key, probeStatus)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return probeStatus, err
+	}
+	cluster := &extensionsv1alpha1.Cluster{}
+	if err = p.client.Get(ctx, client.ObjectKey{Name: p.namespace}, cluster); err != nil {
+		return nil, err
+	}
+	probeStatus = &dwdv1alpha1.ProbeStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       p.namespace,
+			Name:            dwdv1alpha1.ProbeStatusName,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cluster, extensionsv1alpha1.SchemeGroupVersion.WithKind("Cluster"))},
+		},
+	}
+	if err = p.client.Create(ctx, probeStatus); err != nil {
+		return nil, err
+	}
+	p.l.Info("Created probe status", "name", probeStatus.Name)
+	return probeStatus, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdv1alpha1 "github.com/gardener/dependency-watchdog/api/v1alpha1"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/test"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const probeStatusTestNamespace = "shoot--test--probestatus"
+
+func TestPublishProbeStatusCreatesProbeStatusOwnedByCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	p, c := createProbeStatusTestProber(g, time.Minute, 2)
+	p.internalProbeStatus.recordSuccess(1)
+	p.externalProbes[0].status.recordFailure(apierrors.NewTimeoutError("timed out", 1), 1, 0)
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy)
+
+	p.publishProbeStatus(ctx)
+	probeStatus := getProbeStatus(g, c)
+	g.Expect(probeStatus.OwnerReferences).To(HaveLen(1))
+	g.Expect(probeStatus.OwnerReferences[0].Kind).To(Equal("Cluster"))
+	g.Expect(probeStatus.OwnerReferences[0].Name).To(Equal(probeStatusTestNamespace))
+	g.Expect(probeStatus.Status.Internal.Health).To(Equal(dwdv1alpha1.ProbeHealthHealthy))
+	g.Expect(probeStatus.Status.Internal.SuccessCount).To(Equal(1))
+	g.Expect(probeStatus.Status.Internal.LastError).To(BeNil())
+	g.Expect(probeStatus.Status.External.Health).To(Equal(dwdv1alpha1.ProbeHealthUnhealthy))
+	g.Expect(probeStatus.Status.External.ErrorCount).To(Equal(1))
+	g.Expect(probeStatus.Status.External.LastError).ToNot(BeNil())
+	g.Expect(probeStatus.Status.External.LastError.Class).To(Equal(dwdv1alpha1.ProbeErrorClassTimeout))
+	g.Expect(probeStatus.Status.ExternalTargets).To(HaveLen(1))
+	g.Expect(probeStatus.Status.ExternalTargets[0].Name).To(Equal(defaultExternalProbeTargetName))
+	g.Expect(probeStatus.Status.Configuration.DependentResources).To(ConsistOf("Deployment/kube-controller-manager", "Deployment/machine-controller-manager"))
+	g.Expect(probeStatus.Status.Configuration.ExternalProbePolicy).To(Equal(string(papi.ExternalProbePolicyAny)))
+	g.Expect(probeStatus.Status.LastUpdateTime.IsZero()).To(BeFalse())
+}
+
+func TestPublishProbeStatusIsRateLimited(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	p, c := createProbeStatusTestProber(g, time.Hour, 2)
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthHealthy)
+	p.publishProbeStatus(ctx)
+	g.Expect(getProbeStatus(g, c).Status.External.Health).To(Equal(dwdv1alpha1.ProbeHealthHealthy))
+
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy)
+	p.publishProbeStatus(ctx)
+	g.Expect(getProbeStatus(g, c).Status.External.Health).To(Equal(dwdv1alpha1.ProbeHealthHealthy), "status should not be updated before the min update interval has elapsed")
+
+	p.probeStatusResource.publishedAt = time.Now().Add(-time.Hour)
+	p.publishProbeStatus(ctx)
+	g.Expect(getProbeStatus(g, c).Status.External.Health).To(Equal(dwdv1alpha1.ProbeHealthUnhealthy), "held back status should be written once the min update interval has elapsed")
+}
+
+func TestRecordScaleHistoryKeepsLatestFlows(t *testing.T) {
+	g := NewWithT(t)
+	p, _ := createProbeStatusTestProber(g, time.Minute, 2)
+	scaleErr := errors.New("timed out")
+
+	p.recordScaleHistory(dwdScaler.Result{Operation: scaleDownOperation, ResourceResults: []dwdScaler.ResourceResult{{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled, ReplicasBefore: 1}}})
+	p.recordScaleHistory(dwdScaler.Result{Operation: scaleUpOperation, Err: scaleErr,
+		ResourceResults: []dwdScaler.ResourceResult{{Ref: mcmRef, Outcome: dwdScaler.ResourceFailed, Err: scaleErr}}})
+	p.recordScaleHistory(dwdScaler.Result{Operation: scaleDownOperation, Err: scaleErr,
+		ResourceResults:     []dwdScaler.ResourceResult{{Ref: kcmRef, Outcome: dwdScaler.ResourceScaled, ReplicasBefore: 1}},
+		RolledBackResources: []dwdScaler.ResourceResult{{Ref: kcmRef, ReplicasAfter: 1}}})
+
+	history := p.probeStatusResource.scaleHistory
+	g.Expect(history).To(HaveLen(2), "scale history should be limited to scaleHistoryLimit")
+	g.Expect(history[0].Operation).To(Equal(scaleDownOperation))
+	g.Expect(history[0].Error).To(Equal(scaleErr.Error()))
+	g.Expect(history[0].Resources).To(Equal([]dwdv1alpha1.ScaleResourceRecord{
+		{Resource: "Deployment/kube-controller-manager", Outcome: string(dwdScaler.ResourceScaled), ReplicasBefore: 1},
+		{Resource: "Deployment/kube-controller-manager", Outcome: rolledBackOutcome, ReplicasAfter: 1},
+	}))
+	g.Expect(history[1].Operation).To(Equal(scaleUpOperation))
+	g.Expect(history[1].Resources[0].Error).To(Equal(scaleErr.Error()))
+}
+
+func TestClassifyProbeError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "namespaces"}
+	table := []struct {
+		err           error
+		expectedClass dwdv1alpha1.ProbeErrorClass
+	}{
+		{&util.ProxyError{Err: errors.New("connection refused")}, dwdv1alpha1.ProbeErrorClassProxy},
+		{fmt.Errorf("probe failed: %w", context.DeadlineExceeded), dwdv1alpha1.ProbeErrorClassTimeout},
+		{apierrors.NewTimeoutError("timed out", 1), dwdv1alpha1.ProbeErrorClassTimeout},
+		{apierrors.NewUnauthorized("unauthorized"), dwdv1alpha1.ProbeErrorClassUnauthorized},
+		{apierrors.NewForbidden(gr, "", errors.New("forbidden")), dwdv1alpha1.ProbeErrorClassUnauthorized},
+		{apierrors.NewTooManyRequests("throttled", 1), dwdv1alpha1.ProbeErrorClassThrottled},
+		{apierrors.NewInternalError(errors.New("etcd unavailable")), dwdv1alpha1.ProbeErrorClassAPIServer},
+		{errors.New("connection reset by peer"), dwdv1alpha1.ProbeErrorClassNetwork},
+	}
+	for _, entry := range table {
+		t.Run(entry.err.Error(), func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(classifyProbeError(entry.err)).To(Equal(entry.expectedClass))
+		})
+	}
+}
+
+func getProbeStatus(g *WithT, c client.Client) *dwdv1alpha1.ProbeStatus {
+	probeStatus := &dwdv1alpha1.ProbeStatus{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: probeStatusTestNamespace, Name: dwdv1alpha1.ProbeStatusName}, probeStatus)).To(Succeed())
+	return probeStatus
+}
+
+func createProbeStatusTestProber(g *WithT, minUpdateInterval time.Duration, scaleHistoryLimit int) (*Prober, client.Client) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(extensionsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(dwdv1alpha1.AddToScheme(scheme))
+	cluster, _, err := test.CreateClusterResource(1, true)
+	g.Expect(err).To(BeNil())
+	cluster.Name = probeStatusTestNamespace
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	config := &papi.Config{
+		SuccessThreshold:       pointer.Int(1),
+		FailureThreshold:       pointer.Int(1),
+		ExternalProbePolicy:    (*papi.ExternalProbePolicy)(pointer.String(string(papi.ExternalProbePolicyAny))),
+		ScaleDownFailurePolicy: (*papi.ScaleDownFailurePolicy)(pointer.String(string(papi.ScaleDownFailurePolicyRetry))),
+		DependentResourceInfos: []papi.DependentResourceInfo{{Ref: &kcmRef}, {Ref: &mcmRef}},
+		ProbeStatusResource: &papi.ProbeStatusResource{
+			Enabled:           pointer.Bool(true),
+			MinUpdateInterval: &metav1.Duration{Duration: minUpdateInterval},
+			ScaleHistoryLimit: pointer.Int(scaleHistoryLimit),
+		},
+	}
+	return NewProber(context.Background(), probeStatusTestNamespace, config, c, nil, nil, nil, nil, proberTestLogger), c
+}
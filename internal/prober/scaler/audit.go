@@ -0,0 +1,65 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gardener/dependency-watchdog/internal/audit"
+)
+
+// auditScaling appends a record of the scaling of the resource to the audit log, if one has been configured. A record which
+// cannot be written is logged but does not fail the scaling.
+func (r *resScaler) auditScaling(ctx context.Context, replicasBefore int32, replicasAfter int32, err error) {
+	if r.auditSink == nil {
+		return
+	}
+	record := audit.Record{
+		ShootNamespace: r.namespace,
+		Action:         audit.ActionScaleDown,
+		Resource:       fmt.Sprintf("%s/%s", r.resourceInfo.ref.Kind, r.resourceInfo.ref.Name),
+		ReplicasBefore: &replicasBefore,
+		ProbeState:     auditProbeState(ctx),
+		Result:         audit.ResultSucceeded,
+	}
+	if r.resourceInfo.operation == scaleUp {
+		record.Action = audit.ActionScaleUp
+	}
+	if err != nil {
+		record.Result = audit.ResultFailed
+		record.Error = err.Error()
+	} else {
+		record.ReplicasAfter = &replicasAfter
+	}
+	if auditErr := r.auditSink.Write(ctx, record); auditErr != nil {
+		r.logger.Error(auditErr, "Failed to write audit record", "action", record.Action, "result", record.Result)
+	}
+}
+
+// auditProbeState returns the state of the probe which has triggered the scaling. It returns nil if scaling has not been
+// triggered by a probe.
+func auditProbeState(ctx context.Context) *audit.ProbeState {
+	probeStatus, ok := ctx.Value(probeStatusContextKey{}).(ProbeStatus)
+	if !ok {
+		return nil
+	}
+	return &audit.ProbeState{
+		InternalHealthy:    probeStatus.Internal.Healthy,
+		InternalErrorCount: probeStatus.Internal.ErrorCount,
+		ExternalHealthy:    probeStatus.External.Healthy,
+		ExternalErrorCount: probeStatus.External.ErrorCount,
+	}
+}
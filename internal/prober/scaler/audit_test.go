@@ -0,0 +1,68 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+)
+
+// recordingSink records all audit records instead of writing them.
+type recordingSink struct {
+	records []audit.Record
+}
+
+func (r *recordingSink) Write(_ context.Context, record audit.Record) error {
+	r.records = append(r.records, record)
+	return nil
+}
+
+func TestAuditScaling(t *testing.T) {
+	g := NewWithT(t)
+	rs := &recordingSink{}
+	ref := &autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}
+	scaleDownScaler := newResourceScaler(nil, nil, rs, logr.Discard(), nil, "shoot--bingo", scalableResourceInfo{ref: ref, operation: scaleDown}).(*resScaler)
+	scaleUpScaler := newResourceScaler(nil, nil, rs, logr.Discard(), nil, "shoot--bingo", scalableResourceInfo{ref: ref, operation: scaleUp}).(*resScaler)
+
+	probeCtx := ContextWithProbeStatus(context.Background(), ProbeStatus{Internal: ProbeResult{Healthy: true}, External: ProbeResult{ErrorCount: 3}})
+	scaleDownScaler.auditScaling(probeCtx, 2, 0, nil)
+	scaleUpScaler.auditScaling(context.Background(), 0, 0, errors.New("conflict"))
+
+	g.Expect(rs.records).To(HaveLen(2))
+	g.Expect(rs.records[0]).To(Equal(audit.Record{
+		ShootNamespace: "shoot--bingo",
+		Action:         audit.ActionScaleDown,
+		Resource:       "Deployment/kube-controller-manager",
+		ReplicasBefore: rs.records[0].ReplicasBefore,
+		ReplicasAfter:  rs.records[0].ReplicasAfter,
+		ProbeState:     &audit.ProbeState{InternalHealthy: true, ExternalErrorCount: 3},
+		Result:         audit.ResultSucceeded,
+	}))
+	g.Expect(*rs.records[0].ReplicasBefore).To(Equal(int32(2)))
+	g.Expect(*rs.records[0].ReplicasAfter).To(Equal(int32(0)))
+	g.Expect(rs.records[1].Action).To(Equal(audit.ActionScaleUp))
+	g.Expect(rs.records[1].ProbeState).To(BeNil(), "probe state should not be set if scaling has not been triggered by a probe")
+	g.Expect(rs.records[1].ReplicasAfter).To(BeNil(), "replicas after should not be set if scaling has failed")
+	g.Expect(rs.records[1].Result).To(Equal(audit.ResultFailed))
+	g.Expect(rs.records[1].Error).To(Equal("conflict"))
+}
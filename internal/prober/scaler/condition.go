@@ -0,0 +1,243 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gardener/dependency-watchdog/internal/util"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// conditionVarShoot is the name of the variable holding the shoot in a scale condition.
+	conditionVarShoot = "shoot"
+	// conditionVarResource is the name of the variable holding the metadata and status of the dependent resource in a scale condition.
+	conditionVarResource = "resource"
+	// conditionVarProbe is the name of the variable holding the status of the internal and external probe in a scale condition.
+	conditionVarProbe = "probe"
+	// conditionCostLimit is the maximum cost of a scale condition, both as estimated when it is compiled and as tracked
+	// while it is evaluated. The cost corresponds to the number and expense of the operations of the condition.
+	conditionCostLimit uint64 = 1000000
+	// conditionMaxSize is the size which is assumed at most for lists, maps and strings of the variables when the cost of a
+	// scale condition is estimated, as their actual size is not known before the condition is evaluated.
+	conditionMaxSize uint64 = 1000
+	// conditionInterruptCheckFrequency is the number of iterations of a comprehension after which the evaluation of a scale
+	// condition checks if its context is done.
+	conditionInterruptCheckFrequency uint = 100
+)
+
+type probeStatusContextKey struct{}
+
+type skipScaleConditionsContextKey struct{}
+
+// ProbeStatus captures the status of the internal and external probe which has led to a scale flow run.
+// It is made available to the scale conditions of dependent resources.
+type ProbeStatus struct {
+	Internal ProbeResult
+	// External is the result of the external probe as computed from its targets by the external probe policy.
+	// SuccessCount and ErrorCount are summed over all targets.
+	External ProbeResult
+	// ExternalTargets are the results of the external probe for each target, keyed by the name of the target.
+	ExternalTargets map[string]ProbeResult
+}
+
+// ProbeResult captures the status of a single probe.
+type ProbeResult struct {
+	// Healthy is true if the probe has succeeded at least successThreshold times consecutively.
+	Healthy bool
+	// SuccessCount is the number of consecutive successful attempts of the probe.
+	SuccessCount int
+	// ErrorCount is the number of consecutive failed attempts of the probe.
+	ErrorCount int
+}
+
+// ContextWithProbeStatus returns a copy of the context which carries the given ProbeStatus. Scale flows which are run
+// with a context without a ProbeStatus evaluate scale conditions against a zero ProbeStatus.
+func ContextWithProbeStatus(ctx context.Context, probeStatus ProbeStatus) context.Context {
+	return context.WithValue(ctx, probeStatusContextKey{}, probeStatus)
+}
+
+func probeStatusFromContext(ctx context.Context) ProbeStatus {
+	probeStatus, _ := ctx.Value(probeStatusContextKey{}).(ProbeStatus)
+	return probeStatus
+}
+
+// ContextWithoutScaleConditions returns a copy of the context with which scale flows scale dependent resources irrespective
+// of their scale conditions. It is used where resources have to be scaled up independent of the probe status, i.e. when
+// resources left scaled down are restored and when the prober has become fail-open.
+func ContextWithoutScaleConditions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipScaleConditionsContextKey{}, true)
+}
+
+func scaleConditionsSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(skipScaleConditionsContextKey{}).(bool)
+	return skipped
+}
+
+// scaleCondition is a compiled CEL expression which has to evaluate to true for a dependent resource to be scaled.
+type scaleCondition struct {
+	expression string
+	program    cel.Program
+	// err captures the error if the expression could not be compiled.
+	err error
+}
+
+// ValidateScaleCondition checks that the given CEL expression compiles, evaluates to a bool and that its estimated cost
+// does not exceed the cost limit.
+func ValidateScaleCondition(expression string) error {
+	_, err := compileScaleCondition(expression)
+	return err
+}
+
+func newScaleCondition(expression *string) *scaleCondition {
+	if expression == nil {
+		return nil
+	}
+	program, err := compileScaleCondition(*expression)
+	return &scaleCondition{expression: *expression, program: program, err: err}
+}
+
+func compileScaleCondition(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(conditionVarShoot, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(conditionVarResource, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(conditionVarProbe, cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile condition %q: %w", expression, issues.Err())
+	}
+	if !ast.OutputType().IsAssignableType(cel.BoolType) {
+		return nil, fmt.Errorf("condition %q must evaluate to a bool but evaluates to %v", expression, ast.OutputType())
+	}
+	cost, err := env.EstimateCost(ast, conditionCostEstimator{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate cost of condition %q: %w", expression, err)
+	}
+	if cost.Max > conditionCostLimit {
+		return nil, fmt.Errorf("estimated cost %d of condition %q exceeds the cost limit %d", cost.Max, expression, conditionCostLimit)
+	}
+	return env.Program(ast, cel.CostLimit(conditionCostLimit), cel.InterruptCheckFrequency(conditionInterruptCheckFrequency))
+}
+
+// conditionCostEstimator bounds the size of the variables of a scale condition to conditionMaxSize, without which the
+// estimated cost of any condition iterating over them would be unbounded.
+type conditionCostEstimator struct{}
+
+func (conditionCostEstimator) EstimateSize(_ checker.AstNode) *checker.SizeEstimate {
+	return &checker.SizeEstimate{Min: 0, Max: conditionMaxSize}
+}
+
+func (conditionCostEstimator) EstimateCallCost(_, _ string, _ *checker.AstNode, _ []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
+// evaluate evaluates the condition against the given variables. The evaluation is aborted once the context is done.
+func (c *scaleCondition) evaluate(ctx context.Context, vars map[string]interface{}) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	out, _, err := c.program.ContextEval(ctx, vars)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate condition %q: %w", c.expression, err)
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition %q evaluated to %v which is not a bool", c.expression, out.Value())
+	}
+	return result, nil
+}
+
+// createConditionVars creates the variables against which the scale condition of the dependent resource is evaluated.
+func (r *resScaler) createConditionVars(ctx context.Context) (map[string]interface{}, error) {
+	cluster := &extensionsv1alpha1.Cluster{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: r.namespace}, cluster); err != nil {
+		return nil, fmt.Errorf("failed to get cluster to evaluate scale condition: %w", err)
+	}
+	shoot, err := extensionscontroller.ShootFromCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	shootVar, err := runtime.DefaultUnstructuredConverter.ToUnstructured(shoot)
+	if err != nil {
+		return nil, err
+	}
+	resObj, err := util.GetResource(ctx, r.client, r.namespace, r.resourceInfo.ref)
+	if err != nil {
+		return nil, err
+	}
+	resourceVar := map[string]interface{}{
+		"metadata": resObj.Object["metadata"],
+		"status":   resObj.Object["status"],
+	}
+	probeStatus := probeStatusFromContext(ctx)
+	externalTargetsVar := make(map[string]interface{}, len(probeStatus.ExternalTargets))
+	for name, probeResult := range probeStatus.ExternalTargets {
+		externalTargetsVar[name] = probeResultToMap(probeResult)
+	}
+	probeVar := map[string]interface{}{
+		"internal":        probeResultToMap(probeStatus.Internal),
+		"external":        probeResultToMap(probeStatus.External),
+		"externalTargets": externalTargetsVar,
+	}
+	return map[string]interface{}{
+		conditionVarShoot:    shootVar,
+		conditionVarResource: resourceVar,
+		conditionVarProbe:    probeVar,
+	}, nil
+}
+
+func probeResultToMap(probeResult ProbeResult) map[string]interface{} {
+	return map[string]interface{}{
+		"healthy":      probeResult.Healthy,
+		"successCount": int64(probeResult.SuccessCount),
+		"errorCount":   int64(probeResult.ErrorCount),
+	}
+}
+
+// conditionMet evaluates the scale condition of the dependent resource, if one has been configured and conditions are not
+// skipped for the context, and logs the result.
+func (r *resScaler) conditionMet(ctx context.Context) (bool, error) {
+	condition := r.resourceInfo.condition
+	if condition == nil {
+		return true, nil
+	}
+	if scaleConditionsSkipped(ctx) {
+		r.logger.Info("Skipped evaluation of scale condition as resource has to be scaled irrespective of it", "condition", condition.expression)
+		return true, nil
+	}
+	vars, err := r.createConditionVars(ctx)
+	if err != nil {
+		return false, err
+	}
+	evalCtx, cancelFn := context.WithTimeout(ctx, r.resourceInfo.timeout)
+	defer cancelFn()
+	met, err := condition.evaluate(evalCtx, vars)
+	if err != nil {
+		return false, err
+	}
+	r.logger.Info("Evaluated scale condition", "condition", condition.expression, "result", met)
+	return met, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gardener/dependency-watchdog/internal/test"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const conditionTestNamespace = "shoot--test"
+
+func TestValidateScaleCondition(t *testing.T) {
+	tests := []struct {
+		title      string
+		expression string
+		valid      bool
+	}{
+		{"test: condition evaluating to a bool is valid", "size(shoot.spec.provider.workers) > 0", true},
+		{"test: condition using all variables is valid", "probe.internal.healthy && resource.status.readyReplicas > 0 && shoot.metadata.name != ''", true},
+		{"test: condition with syntax error is invalid", "shoot.spec.provider.workers >", false},
+		{"test: condition using unknown variable is invalid", "cluster.spec != null", false},
+		{"test: condition not evaluating to a bool is invalid", "1 + 1", false},
+		{"test: condition iterating over a variable is valid", "shoot.spec.provider.workers.all(w, w.minimum > 0)", true},
+		{"test: condition exceeding the cost limit is invalid", "shoot.spec.provider.workers.all(w, shoot.spec.provider.workers.all(v, shoot.spec.provider.workers.all(u, u.name != '')))", false},
+	}
+
+	for _, entry := range tests {
+		t.Run(entry.title, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateScaleCondition(entry.expression)
+			if entry.valid {
+				g.Expect(err).To(BeNil())
+			} else {
+				g.Expect(err).ToNot(BeNil())
+			}
+		})
+	}
+}
+
+func TestConditionMet(t *testing.T) {
+	probeStatus := ProbeStatus{
+		Internal: ProbeResult{Healthy: true, SuccessCount: 1},
+		External: ProbeResult{ErrorCount: 3},
+		ExternalTargets: map[string]ProbeResult{
+			"dns":           {ErrorCount: 3},
+			"load-balancer": {Healthy: true, SuccessCount: 1},
+		},
+	}
+	tests := []struct {
+		title       string
+		condition   *string
+		expectedMet bool
+		expectedErr bool
+	}{
+		{"test: resource without condition is always scaled", nil, true, false},
+		{"test: condition on shoot evaluating to true", pointer.String("size(shoot.spec.provider.workers) > 0 && !shoot.status.hibernated"), true, false},
+		{"test: condition guarding optional field evaluating to true", pointer.String("!(has(shoot.metadata.annotations) && 'maintenance.gardener.cloud/operation' in shoot.metadata.annotations)"), true, false},
+		{"test: condition on resource evaluating to false", pointer.String("resource.status.readyReplicas > 1"), false, false},
+		{"test: condition on probe status evaluating to true", pointer.String("probe.internal.healthy && probe.external.errorCount >= 3"), true, false},
+		{"test: condition on external probe target evaluating to true", pointer.String("probe.externalTargets['load-balancer'].healthy && !probe.externalTargets.dns.healthy"), true, false},
+		{"test: condition referring to missing field fails", pointer.String("shoot.spec.maintenance.timeWindow.begin == '220000+0000'"), false, true},
+	}
+
+	for _, entry := range tests {
+		t.Run(entry.title, func(t *testing.T) {
+			g := NewWithT(t)
+			r := createConditionTestResScaler(g, entry.condition)
+			met, err := r.conditionMet(ContextWithProbeStatus(context.Background(), probeStatus))
+			g.Expect(err != nil).To(Equal(entry.expectedErr))
+			g.Expect(met).To(Equal(entry.expectedMet))
+		})
+	}
+}
+
+func TestConditionMetIsSkippedForContextWithoutScaleConditions(t *testing.T) {
+	g := NewWithT(t)
+	r := createConditionTestResScaler(g, pointer.String("probe.external.healthy"))
+
+	met, err := r.conditionMet(context.Background())
+	g.Expect(err).To(BeNil())
+	g.Expect(met).To(BeFalse())
+
+	met, err = r.conditionMet(ContextWithoutScaleConditions(context.Background()))
+	g.Expect(err).To(BeNil())
+	g.Expect(met).To(BeTrue())
+}
+
+func TestEvaluateConditionIsAbortedOnceContextIsDone(t *testing.T) {
+	g := NewWithT(t)
+	condition := newScaleCondition(pointer.String("resource.status.items.all(i, i >= 0)"))
+	g.Expect(condition.err).To(BeNil())
+	vars := createConditionTestVarsWithItems(1000, func(i int) interface{} { return int64(i) })
+
+	met, err := condition.evaluate(context.Background(), vars)
+	g.Expect(err).To(BeNil())
+	g.Expect(met).To(BeTrue())
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+	_, err = condition.evaluate(ctx, vars)
+	g.Expect(err).To(MatchError(ContainSubstring("interrupted")))
+}
+
+func TestEvaluateConditionIsAbortedOnceCostLimitIsExceeded(t *testing.T) {
+	g := NewWithT(t)
+	condition := newScaleCondition(pointer.String("resource.status.items.all(i, !i.contains('b'))"))
+	g.Expect(condition.err).To(BeNil())
+	vars := createConditionTestVarsWithItems(100, func(_ int) interface{} { return "a" })
+
+	met, err := condition.evaluate(context.Background(), vars)
+	g.Expect(err).To(BeNil())
+	g.Expect(met).To(BeTrue())
+
+	// items which are far larger than assumed when the cost has been estimated
+	longItem := strings.Repeat("a", int(conditionCostLimit))
+	_, err = condition.evaluate(context.Background(), createConditionTestVarsWithItems(100, func(_ int) interface{} { return longItem }))
+	g.Expect(err).To(MatchError(ContainSubstring("cost limit exceeded")))
+}
+
+func createConditionTestVarsWithItems(count int, itemFn func(int) interface{}) map[string]interface{} {
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i] = itemFn(i)
+	}
+	return map[string]interface{}{
+		conditionVarShoot:    map[string]interface{}{},
+		conditionVarResource: map[string]interface{}{"status": map[string]interface{}{"items": items}},
+		conditionVarProbe:    map[string]interface{}{},
+	}
+}
+
+func createConditionTestResScaler(g *WithT, condition *string) *resScaler {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(extensionsv1alpha1.AddToScheme(scheme))
+
+	cluster, _, err := test.CreateClusterResource(1, true)
+	g.Expect(err).To(BeNil())
+	cluster.Name = conditionTestNamespace
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: mcmObjectRef.Name, Namespace: conditionTestNamespace},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+	return &resScaler{
+		client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, deployment).Build(),
+		logger:    logr.Discard(),
+		namespace: conditionTestNamespace,
+		resourceInfo: scalableResourceInfo{
+			ref:       &autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: mcmObjectRef.Name, APIVersion: "apps/v1"},
+			operation: scaleDown,
+			timeout:   time.Second,
+			condition: newScaleCondition(condition),
+		},
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/go-logr/logr"
+)
+
+// getEscalationStages gets the configured escalation stages. If none have been configured, a single stage which scales down
+// all dependent resources as soon as the external probe is unhealthy is returned.
+func getEscalationStages(config *papi.Config) []papi.EscalationStage {
+	if len(config.EscalationStages) > 0 {
+		return config.EscalationStages
+	}
+	return []papi.EscalationStage{{ResourceNames: mapToDependentResourceNames(config.DependentResourceInfos)}}
+}
+
+// createEscalationFlows creates a scale-up and a scale-down flow for each escalation stage. As resources of previous stages
+// remain scaled down, the scale-down flow of a stage comprises the resources of the stage and all its previous stages.
+// The scale-up flow of a stage only comprises the resources of the stage.
+func createEscalationFlows(fc flowCreator, namespace string, dependentResourceInfos []papi.DependentResourceInfo, stages []papi.EscalationStage, logger logr.Logger) ([]*scaleFlow, []*scaleFlow) {
+	scaleUpFlows := make([]*scaleFlow, 0, len(stages))
+	scaleDownFlows := make([]*scaleFlow, 0, len(stages))
+	var escalatedResourceNames []string
+	for i, stage := range stages {
+		escalatedResourceNames = append(escalatedResourceNames, stage.ResourceNames...)
+		scaleUpFlow := fc.createFlow(fmt.Sprintf("scale-up-%s-stage-%d", namespace, i), namespace, scaleUp, filterDependentResourceInfos(dependentResourceInfos, stage.ResourceNames))
+		logger.V(1).Info("Created scaleUpFlow", "escalationStage", i, "flowStepInfos", scaleUpFlow.flowStepInfos)
+		scaleDownFlow := fc.createFlow(fmt.Sprintf("scale-down-%s-stage-%d", namespace, i), namespace, scaleDown, filterDependentResourceInfos(dependentResourceInfos, escalatedResourceNames))
+		logger.V(1).Info("Created scaleDownFlow", "escalationStage", i, "flowStepInfos", scaleDownFlow.flowStepInfos)
+		scaleUpFlows = append(scaleUpFlows, scaleUpFlow)
+		scaleDownFlows = append(scaleDownFlows, scaleDownFlow)
+	}
+	return scaleUpFlows, scaleDownFlows
+}
+
+// createResourceSetScaleDownFlows creates a scale-down flow for each distinct set of resources which is scaled down by a decision
+// of the decision matrix. Flows are keyed by resourceSetKey.
+func createResourceSetScaleDownFlows(fc flowCreator, namespace string, dependentResourceInfos []papi.DependentResourceInfo, decisions []papi.Decision, logger logr.Logger) map[string]*scaleFlow {
+	flows := make(map[string]*scaleFlow)
+	for _, decision := range decisions {
+		if decision.Action != papi.DecisionActionScaleDown || len(decision.ResourceNames) == 0 {
+			continue
+		}
+		key := resourceSetKey(decision.ResourceNames)
+		if _, ok := flows[key]; ok {
+			continue
+		}
+		sf := fc.createFlow(fmt.Sprintf("scale-down-%s-%s", namespace, key), namespace, scaleDown, filterDependentResourceInfos(dependentResourceInfos, decision.ResourceNames))
+		logger.V(1).Info("Created scaleDownFlow", "resourceNames", decision.ResourceNames, "flowStepInfos", sf.flowStepInfos)
+		flows[key] = sf
+	}
+	return flows
+}
+
+// resourceSetKey creates a key for a set of resource names which is independent of the order of the names.
+func resourceSetKey(resourceNames []string) string {
+	sortedNames := make([]string, len(resourceNames))
+	copy(sortedNames, resourceNames)
+	sort.Strings(sortedNames)
+	return strings.Join(sortedNames, ",")
+}
+
+// getDueEscalationStage gets the index of the last escalation stage which is due given the duration for which the external
+// probe has been unhealthy. It returns -1 if no stage is due yet.
+func getDueEscalationStage(stages []papi.EscalationStage, unhealthyFor time.Duration) int {
+	stage := -1
+	for i, escalationStage := range stages {
+		if unhealthyFor < escalationStage.After.Duration {
+			break
+		}
+		stage = i
+	}
+	return stage
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/mock/client-go/scale"
+	"github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetDueEscalationStage(t *testing.T) {
+	stages := []papi.EscalationStage{
+		{After: metav1.Duration{Duration: time.Minute}, ResourceNames: []string{caObjectRef.Name}},
+		{After: metav1.Duration{Duration: 5 * time.Minute}, ResourceNames: []string{mcmObjectRef.Name, kcmObjectRef.Name}},
+	}
+	tests := []struct {
+		unhealthyFor  time.Duration
+		expectedStage int
+	}{
+		{0, -1},
+		{30 * time.Second, -1},
+		{time.Minute, 0},
+		{4 * time.Minute, 0},
+		{5 * time.Minute, 1},
+		{time.Hour, 1},
+	}
+
+	g := NewWithT(t)
+	for _, entry := range tests {
+		g.Expect(getDueEscalationStage(stages, entry.unhealthyFor)).To(Equal(entry.expectedStage), "unhealthyFor: %s", entry.unhealthyFor)
+	}
+}
+
+func TestGetEscalationStagesDefaultsToSingleStage(t *testing.T) {
+	g := NewWithT(t)
+	config := &papi.Config{DependentResourceInfos: []papi.DependentResourceInfo{
+		createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(caObjectRef.Name, 1, 0, nil, nil, false),
+	}}
+
+	stages := getEscalationStages(config)
+	g.Expect(stages).To(HaveLen(1))
+	g.Expect(stages[0].After.Duration).To(BeZero())
+	g.Expect(stages[0].ResourceNames).To(Equal([]string{kcmObjectRef.Name, caObjectRef.Name}))
+}
+
+func TestCreateEscalationFlows(t *testing.T) {
+	g := NewWithT(t)
+	depResInfos := []papi.DependentResourceInfo{
+		createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(mcmObjectRef.Name, 1, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(caObjectRef.Name, 2, 0, nil, nil, false),
+	}
+	stages := []papi.EscalationStage{
+		{After: metav1.Duration{Duration: time.Minute}, ResourceNames: []string{caObjectRef.Name}},
+		{After: metav1.Duration{Duration: 5 * time.Minute}, ResourceNames: []string{mcmObjectRef.Name, kcmObjectRef.Name}},
+	}
+	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, nil, logr.Discard(), &papi.Config{DependentResourceInfos: depResInfos})
+
+	scaleUpFlows, scaleDownFlows := createEscalationFlows(fc, "test-escalation", depResInfos, stages, logr.Discard())
+	g.Expect(scaleUpFlows).To(HaveLen(2))
+	g.Expect(scaleDownFlows).To(HaveLen(2))
+	g.Expect(getFlowResourceNames(g, scaleDownFlows[0])).To(ConsistOf(caObjectRef.Name))
+	g.Expect(getFlowResourceNames(g, scaleDownFlows[1])).To(ConsistOf(caObjectRef.Name, mcmObjectRef.Name, kcmObjectRef.Name))
+	g.Expect(getFlowResourceNames(g, scaleUpFlows[0])).To(ConsistOf(caObjectRef.Name))
+	g.Expect(getFlowResourceNames(g, scaleUpFlows[1])).To(ConsistOf(mcmObjectRef.Name, kcmObjectRef.Name))
+}
+
+func TestCreateResourceSetScaleDownFlows(t *testing.T) {
+	g := NewWithT(t)
+	depResInfos := []papi.DependentResourceInfo{
+		createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(mcmObjectRef.Name, 1, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(caObjectRef.Name, 2, 0, nil, nil, false),
+	}
+	decisions := []papi.Decision{
+		{Internal: papi.ProbeHealthUnhealthy, External: papi.ProbeHealthUnknown, Action: papi.DecisionActionScaleDown, ResourceNames: []string{mcmObjectRef.Name, caObjectRef.Name}},
+		{Internal: papi.ProbeHealthUnhealthy, External: papi.ProbeHealthUnhealthy, Action: papi.DecisionActionScaleDown, ResourceNames: []string{caObjectRef.Name, mcmObjectRef.Name}},
+		{Internal: papi.ProbeHealthHealthy, External: papi.ProbeHealthUnhealthy, Action: papi.DecisionActionScaleDown},
+		{Internal: papi.ProbeHealthUnknown, External: papi.ProbeHealthUnhealthy, Action: papi.DecisionActionAlertOnly},
+	}
+	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, nil, logr.Discard(), &papi.Config{DependentResourceInfos: depResInfos})
+
+	flows := createResourceSetScaleDownFlows(fc, "test-decision", depResInfos, decisions, logr.Discard())
+	g.Expect(flows).To(HaveLen(1))
+	g.Expect(flows).To(HaveKey(resourceSetKey([]string{mcmObjectRef.Name, caObjectRef.Name})))
+	g.Expect(getFlowResourceNames(g, flows[resourceSetKey([]string{caObjectRef.Name, mcmObjectRef.Name})])).To(ConsistOf(mcmObjectRef.Name, caObjectRef.Name))
+}
+
+func getFlowResourceNames(g *WithT, sf *scaleFlow) []string {
+	var resourceNames []string
+	for _, stepInfo := range sf.flowStepInfos {
+		_, stepResourceNames, err := parseTaskID(string(stepInfo.taskID))
+		g.Expect(err).To(BeNil())
+		resourceNames = append(resourceNames, stepResourceNames...)
+	}
+	return resourceNames
+}
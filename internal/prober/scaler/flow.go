@@ -17,10 +17,13 @@ package scaler
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/audit"
 	"github.com/gardener/dependency-watchdog/internal/util"
 	"github.com/gardener/gardener/pkg/utils/flow"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
@@ -28,34 +31,30 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const (
-	defaultMaxResourceScalingAttempts = 3
-)
-
 type flowCreator interface {
-	createFlow(name string, namespace string, opType operation) *scaleFlow
+	createFlow(name string, namespace string, opType operation, dependentResourceInfos []papi.DependentResourceInfo) *scaleFlow
 }
 
 type creator struct {
-	client                 client.Client
-	scaler                 scalev1.ScaleInterface
-	logger                 logr.Logger
-	options                *scalerOptions
-	dependentResourceInfos []papi.DependentResourceInfo
+	client    client.Client
+	scaler    scalev1.ScaleInterface
+	auditSink audit.Sink
+	logger    logr.Logger
+	config    *papi.Config
 }
 
-func newFlowCreator(client client.Client, scaler scalev1.ScaleInterface, logger logr.Logger, options *scalerOptions, dependentResourceInfos []papi.DependentResourceInfo) flowCreator {
+func newFlowCreator(client client.Client, scaler scalev1.ScaleInterface, auditSink audit.Sink, logger logr.Logger, config *papi.Config) flowCreator {
 	return &creator{
-		client:                 client,
-		scaler:                 scaler,
-		logger:                 logger,
-		options:                options,
-		dependentResourceInfos: dependentResourceInfos,
+		client:    client,
+		scaler:    scaler,
+		auditSink: auditSink,
+		logger:    logger,
+		config:    config,
 	}
 }
 
-func (c *creator) createFlow(name string, namespace string, opType operation) *scaleFlow {
-	resourceInfos := createScalableResourceInfos(opType, c.dependentResourceInfos)
+func (c *creator) createFlow(name string, namespace string, opType operation, dependentResourceInfos []papi.DependentResourceInfo) *scaleFlow {
+	resourceInfos := createScalableResourceInfos(opType, dependentResourceInfos)
 	levels := sortAndGetUniqueLevels(resourceInfos)
 	orderedResourceInfos := collectResourceInfosByLevel(resourceInfos)
 	g := flow.NewGraph(name)
@@ -67,7 +66,7 @@ func (c *creator) createFlow(name string, namespace string, opType operation) *s
 			dependentTaskIDs := previousTaskIDs
 			taskID := g.Add(flow.Task{
 				Name:         createTaskName(resInfos, level),
-				Fn:           c.createScaleTaskFn(namespace, resInfos),
+				Fn:           c.createScaleTaskFn(namespace, level, resInfos, sf),
 				Dependencies: dependentTaskIDs,
 			})
 			sf.addScaleStepInfo(taskID, dependentTaskIDs, previousLevelResourceInfos)
@@ -87,19 +86,25 @@ func (c *creator) createFlow(name string, namespace string, opType operation) *s
 // DependentResourceInfo passed to this function, it indicates that they all are at the same level indicating that these functions
 // should be invoked concurrently. In this case it will construct a flow.Parallel. If there is only one DependentResourceInfo passed
 // then it indicates that at a specific level there is only one DependentResourceInfo that needs to be scaled.
-func (c *creator) createScaleTaskFn(namespace string, resourceInfos []scalableResourceInfo) flow.TaskFn {
+func (c *creator) createScaleTaskFn(namespace string, level int, resourceInfos []scalableResourceInfo, sf *scaleFlow) flow.TaskFn {
 	taskFns := make([]flow.TaskFn, 0, len(resourceInfos))
 	for _, resourceInfo := range resourceInfos {
-		taskFn := c.doCreateTaskFn(namespace, resourceInfo)
+		taskFn := c.doCreateTaskFn(namespace, resourceInfo, sf)
 		taskFns = append(taskFns, taskFn)
 	}
+	levelTaskFn := flow.Parallel(taskFns...)
 	if len(taskFns) == 1 {
-		return taskFns[0]
+		levelTaskFn = taskFns[0]
+	}
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := levelTaskFn(ctx)
+		sf.recordLevelResult(LevelResult{Level: level, Duration: time.Since(start)})
+		return err
 	}
-	return flow.Parallel(taskFns...)
 }
 
-func (c *creator) doCreateTaskFn(namespace string, resInfo scalableResourceInfo) flow.TaskFn {
+func (c *creator) doCreateTaskFn(namespace string, resInfo scalableResourceInfo, sf *scaleFlow) flow.TaskFn {
 	return func(ctx context.Context) error {
 		var operation string
 		if resInfo.operation == scaleUp {
@@ -107,16 +112,29 @@ func (c *creator) doCreateTaskFn(namespace string, resInfo scalableResourceInfo)
 		} else {
 			operation = fmt.Sprintf("scaleDown-resource-%s.%s", namespace, resInfo.ref.Name)
 		}
-		resScaler := newResourceScaler(c.client, c.scaler, c.logger, c.options, namespace, resInfo)
-		result := util.Retry(ctx, c.logger,
+		start := time.Now()
+		resResult := newResourceResult(resInfo)
+		resScaler := newResourceScaler(c.client, c.scaler, c.auditSink, c.logger, c.config, namespace, resInfo)
+		result := util.RetryWithBackOff(ctx, c.logger,
 			operation,
-			func() (interface{}, error) {
-				err := resScaler.scale(ctx)
-				return nil, err
+			func() (ResourceResult, error) {
+				attemptResult, err := resScaler.scale(ctx)
+				// once the replicas have been changed, subsequent attempts will only find the resource at its target replicas.
+				// The result of the attempt which has changed the replicas is therefore retained.
+				if resResult.Outcome != ResourceScaled {
+					resResult = attemptResult
+				}
+				return attemptResult, err
 			},
-			defaultMaxResourceScalingAttempts,
-			*c.options.scaleResourceBackOff,
-			util.AlwaysRetry)
+			*resInfo.retryPolicy.MaxAttempts,
+			createBackOffFn(resInfo.retryPolicy),
+			createCanRetryFn(resInfo.retryPolicy.NonRetriableErrors))
+		if result.Err != nil && resResult.Outcome != ResourceScaled {
+			resResult.Outcome = ResourceFailed
+		}
+		resResult.Err = result.Err
+		resResult.Duration = time.Since(start)
+		sf.recordResourceResult(resResult)
 		return result.Err
 	}
 }
@@ -124,6 +142,10 @@ func (c *creator) doCreateTaskFn(namespace string, resInfo scalableResourceInfo)
 type scaleFlow struct {
 	flow          *flow.Flow
 	flowStepInfos []scaleStepInfo
+	// mu guards resourceResults and levelResults which are recorded concurrently by the tasks of a flow run.
+	mu              sync.Mutex
+	resourceResults []ResourceResult
+	levelResults    []LevelResult
 }
 
 type scaleStepInfo struct {
@@ -150,6 +172,39 @@ func (sf *scaleFlow) setFlow(flow *flow.Flow) {
 	sf.flow = flow
 }
 
+// run runs the flow and returns a Result which captures the outcome for each resource and level processed as part of this run.
+func (sf *scaleFlow) run(ctx context.Context, opType operation) Result {
+	sf.mu.Lock()
+	sf.resourceResults = nil
+	sf.levelResults = nil
+	sf.mu.Unlock()
+
+	start := time.Now()
+	err := sf.flow.Run(ctx, flow.Opts{})
+
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	return Result{
+		Operation:       opType.String(),
+		ResourceResults: sf.resourceResults,
+		LevelResults:    sf.levelResults,
+		Duration:        time.Since(start),
+		Err:             err,
+	}
+}
+
+func (sf *scaleFlow) recordResourceResult(resResult ResourceResult) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	sf.resourceResults = append(sf.resourceResults, resResult)
+}
+
+func (sf *scaleFlow) recordLevelResult(levelResult LevelResult) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	sf.levelResults = append(sf.levelResults, levelResult)
+}
+
 func (s scaleStepInfo) String() string {
 	return fmt.Sprintf("{taskID: %s, dependentTaskIDs: %s, waitOnResources: %v}", s.taskID, s.dependentTaskIDs, s.waitOnResources)
 }
@@ -44,8 +44,8 @@ func TestCreateScaleUpSequentialFlow(t *testing.T) {
 	flowName := "testCreateSequentialFlow"
 	namespace := "test-sequential"
 
-	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, flowTestLogger, &scalerOptions{}, depResInfos)
-	f := fc.createFlow(flowName, namespace, scaleUp)
+	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, nil, flowTestLogger, &papi.Config{DependentResourceInfos: depResInfos})
+	f := fc.createFlow(flowName, namespace, scaleUp, depResInfos)
 	g.Expect(f.flowStepInfos).To(HaveLen(3))
 
 	previousDepTaskIDs := make([]flow.TaskID, 0, 3)
@@ -78,8 +78,8 @@ func TestCreateScaleDownSequentialAndConcurrentFlow(t *testing.T) {
 	flowName := "testCreateSequentialAndConcurrentFlow"
 	namespace := "test-sequential-and-concurrent"
 
-	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, flowTestLogger, &scalerOptions{}, depResInfos)
-	f := fc.createFlow(flowName, namespace, scaleDown)
+	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, nil, flowTestLogger, &papi.Config{DependentResourceInfos: depResInfos})
+	f := fc.createFlow(flowName, namespace, scaleDown, depResInfos)
 	g.Expect(f.flowStepInfos).To(HaveLen(2))
 
 	previousDepTaskIDs := make([]flow.TaskID, 0, 3)
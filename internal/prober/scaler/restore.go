@@ -0,0 +1,62 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"context"
+	"fmt"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	scalev1 "k8s.io/client-go/scale"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RemoveStaleReplicasAnnotations removes the replicas annotation from all dependent resources in the namespace which are
+// no longer scaled down. The replicas annotation is only required to restore a resource which is currently scaled down,
+// for any other resource it is stale. Resources which are not found or have scaling ignored via annotation are skipped.
+// It returns the names of the resources from which the annotation has been removed.
+func RemoveStaleReplicasAnnotations(ctx context.Context, namespace string, config *papi.Config, client client.Client, scalerGetter scalev1.ScalesGetter, logger logr.Logger) ([]string, error) {
+	scaler := scalerGetter.Scales(namespace)
+	var cleanedResNames []string
+	for _, resInfo := range createScalableResourceInfos(scaleUp, config.DependentResourceInfos) {
+		annotations, err := util.GetResourceAnnotations(ctx, client, namespace, resInfo.ref)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return cleanedResNames, err
+		}
+		if _, ok := annotations[ReplicasAnnotationKey]; !ok || ignoreScaling(annotations) {
+			continue
+		}
+		_, scaleSubRes, err := util.GetScaleResource(ctx, client, scaler, logger, resInfo.ref, resInfo.timeout)
+		if err != nil {
+			return cleanedResNames, err
+		}
+		if scaleSubRes.Spec.Replicas == 0 {
+			continue
+		}
+		patchBytes := []byte(fmt.Sprintf("{\"metadata\":{\"annotations\":{\"%s\":null}}}", ReplicasAnnotationKey))
+		if err = util.PatchResourceAnnotations(ctx, client, namespace, resInfo.ref, patchBytes); err != nil {
+			return cleanedResNames, err
+		}
+		logger.Info("Removed stale replicas annotation", "namespace", namespace, "name", resInfo.ref.Name, "replicas", scaleSubRes.Spec.Replicas)
+		cleanedResNames = append(cleanedResNames, resInfo.ref.Name)
+	}
+	return cleanedResNames, nil
+}
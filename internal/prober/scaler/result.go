@@ -0,0 +1,119 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"fmt"
+	"time"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+)
+
+// ResourceOutcome describes what happened to a dependent resource during a run of a scale flow.
+type ResourceOutcome string
+
+const (
+	// ResourceScaled indicates that the replicas of the resource have been changed.
+	ResourceScaled ResourceOutcome = "Scaled"
+	// ResourceSkippedIgnoreScaling indicates that scaling has been skipped as the resource has the ignore-scaling annotation set.
+	ResourceSkippedIgnoreScaling ResourceOutcome = "SkippedIgnoreScaling"
+	// ResourceSkippedOptionalNotFound indicates that scaling has been skipped as the resource is optional and has not been found.
+	ResourceSkippedOptionalNotFound ResourceOutcome = "SkippedOptionalNotFound"
+	// ResourceSkippedAlreadyAtTarget indicates that scaling has been skipped as the resource already has the target replicas.
+	ResourceSkippedAlreadyAtTarget ResourceOutcome = "SkippedAlreadyAtTarget"
+	// ResourceSkippedConditionNotMet indicates that scaling has been skipped as the configured scale condition has evaluated to false.
+	ResourceSkippedConditionNotMet ResourceOutcome = "SkippedConditionNotMet"
+	// ResourceFailed indicates that scaling of the resource has failed.
+	ResourceFailed ResourceOutcome = "Failed"
+)
+
+// ResourceResult captures the outcome of scaling a single dependent resource.
+type ResourceResult struct {
+	// Ref identifies the resource.
+	Ref autoscalingv1.CrossVersionObjectReference
+	// Level is the level at which the resource has been scaled.
+	Level int
+	// Outcome describes what happened to the resource.
+	Outcome ResourceOutcome
+	// ReplicasBefore are the spec replicas of the resource prior to scaling.
+	ReplicasBefore int32
+	// ReplicasAfter are the spec replicas of the resource after scaling.
+	ReplicasAfter int32
+	// Duration is the time taken to scale the resource, including all retries and the wait for the resource to reach its minimum target replicas.
+	Duration time.Duration
+	// Err is the error encountered while scaling the resource, if any.
+	Err error
+}
+
+func (r ResourceResult) String() string {
+	return fmt.Sprintf("{name: %s, level: %d, outcome: %s, replicasBefore: %d, replicasAfter: %d, duration: %s}", r.Ref.Name, r.Level, r.Outcome, r.ReplicasBefore, r.ReplicasAfter, r.Duration)
+}
+
+// LevelResult captures the time taken to scale all resources at a level.
+type LevelResult struct {
+	// Level is the level of the resources.
+	Level int
+	// Duration is the time taken to scale all resources at this level.
+	Duration time.Duration
+}
+
+// Result captures the outcome of a single run of a scale-up or scale-down flow.
+// Resources at levels which have not been run, due to a failure at a previous level, are not part of the result.
+type Result struct {
+	// Operation is either scale-up or scale-down.
+	Operation string
+	// ResourceResults captures the outcome for each resource that has been processed by the flow.
+	ResourceResults []ResourceResult
+	// LevelResults captures the time taken for each level that has been run by the flow.
+	LevelResults []LevelResult
+	// RolledBackResources captures the outcome of restoring resources after a failed scale-down flow. See papi.ScaleDownFailurePolicyRollback.
+	RolledBackResources []ResourceResult
+	// Duration is the time taken by the flow run.
+	Duration time.Duration
+	// Err is the error returned by the flow run, if any.
+	Err error
+}
+
+// ScaledResources returns the results for resources whose replicas have been changed by the flow run.
+func (r Result) ScaledResources() []ResourceResult {
+	return filterResourceResults(r.ResourceResults, ResourceScaled)
+}
+
+// AlreadyAtTargetResources returns the results for resources which already had the target replicas.
+func (r Result) AlreadyAtTargetResources() []ResourceResult {
+	return filterResourceResults(r.ResourceResults, ResourceSkippedAlreadyAtTarget)
+}
+
+// FailedResources returns the results for resources which could not be scaled by the flow run.
+func (r Result) FailedResources() []ResourceResult {
+	return filterResourceResults(r.ResourceResults, ResourceFailed)
+}
+
+func filterResourceResults(resResults []ResourceResult, outcome ResourceOutcome) []ResourceResult {
+	var filtered []ResourceResult
+	for _, resResult := range resResults {
+		if resResult.Outcome == outcome {
+			filtered = append(filtered, resResult)
+		}
+	}
+	return filtered
+}
+
+func newResourceResult(resInfo scalableResourceInfo) ResourceResult {
+	return ResourceResult{
+		Ref:   *resInfo.ref,
+		Level: resInfo.level,
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gardener/dependency-watchdog/internal/util"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+)
+
+// rollback restores the resources which have been scaled down by a failed scale-down flow to the replicas that they had
+// prior to the scale-down. Replicas are restored from the replicas annotation set during the scale-down. Resources are
+// rolled back in the reverse order in which they have been scaled down. It returns the results of rolling back each of the
+// resources along with an error, if rollback of any of the resources failed.
+func (ds *scaleFlowRunner) rollback(ctx context.Context, scaledDownResults []ResourceResult) ([]ResourceResult, error) {
+	rollbackResults := make([]ResourceResult, 0, len(scaledDownResults))
+	var failedResNames []string
+	for i := len(scaledDownResults) - 1; i >= 0; i-- {
+		resInfo, ok := ds.findScaleUpResourceInfo(scaledDownResults[i].Ref)
+		if !ok {
+			continue
+		}
+		// a rollback should restore the resource immediately, therefore any configured initial delay for scale up is ignored.
+		resInfo.initialDelay = 0
+		start := time.Now()
+		resScaler := newResourceScaler(ds.client, ds.scaler, ds.auditSink, ds.logger, ds.config, ds.namespace, resInfo)
+		operation := fmt.Sprintf("rollback-resource-%s.%s", ds.namespace, resInfo.ref.Name)
+		result := util.RetryWithBackOff(ctx, ds.logger,
+			operation,
+			func() (ResourceResult, error) {
+				return resScaler.scale(ctx)
+			},
+			*resInfo.retryPolicy.MaxAttempts,
+			createBackOffFn(resInfo.retryPolicy),
+			createCanRetryFn(resInfo.retryPolicy.NonRetriableErrors))
+		rollbackResult := result.Value
+		rollbackResult.Ref = *resInfo.ref
+		rollbackResult.Level = resInfo.level
+		rollbackResult.Duration = time.Since(start)
+		rollbackResult.Err = result.Err
+		if result.Err != nil {
+			ds.logger.Error(result.Err, "Failed to rollback resource", "name", resInfo.ref.Name)
+			rollbackResult.Outcome = ResourceFailed
+			failedResNames = append(failedResNames, resInfo.ref.Name)
+		}
+		rollbackResults = append(rollbackResults, rollbackResult)
+	}
+	if len(failedResNames) > 0 {
+		return rollbackResults, fmt.Errorf("failed to rollback resources %v", failedResNames)
+	}
+	return rollbackResults, nil
+}
+
+// findScaleUpResourceInfo finds the scale-up configuration for the resource identified by the given reference.
+func (ds *scaleFlowRunner) findScaleUpResourceInfo(ref autoscalingv1.CrossVersionObjectReference) (scalableResourceInfo, bool) {
+	for _, resInfo := range ds.scaleUpResourceInfos {
+		if *resInfo.ref == ref {
+			return resInfo, true
+		}
+	}
+	return scalableResourceInfo{}, false
+}
@@ -21,6 +21,8 @@ import (
 
 	"github.com/go-logr/logr"
 
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/audit"
 	"github.com/gardener/dependency-watchdog/internal/util"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,9 +34,9 @@ import (
 const (
 	// ignoreScalingAnnotationKey is the key for an annotation if present on a resource will suspend any scaling action for that resource.
 	ignoreScalingAnnotationKey = "dependency-watchdog.gardener.cloud/ignore-scaling"
-	// replicasAnnotationKey is the key for an annotation whose value captures the current spec.replicas prior to scale down for that resource.
+	// ReplicasAnnotationKey is the key for an annotation whose value captures the current spec.replicas prior to scale down for that resource.
 	// This is used when DWD attempts to restore the state of the resource it scale down.
-	replicasAnnotationKey = "dependency-watchdog.gardener.cloud/replicas"
+	ReplicasAnnotationKey = "dependency-watchdog.gardener.cloud/replicas"
 	// defaultScaleUpReplicas is the default value of number of replicas for a scale-up operation by a probe when the external probe transitions from failed to success.
 	defaultScaleUpReplicas int32 = 1
 	// defaultScaleDownReplicas is the default value of number of replicas for a scale-down operation by a probe when the external probe transitions from success to failed.
@@ -42,7 +44,9 @@ const (
 )
 
 type resourceScaler interface {
-	scale(ctx context.Context) error
+	// scale scales the resource and waits till it has reached its minimum target replicas. It returns a ResourceResult
+	// which captures the outcome of this scaling attempt.
+	scale(ctx context.Context) (ResourceResult, error)
 }
 
 type resScaler struct {
@@ -51,10 +55,11 @@ type resScaler struct {
 	logger       logr.Logger
 	namespace    string
 	resourceInfo scalableResourceInfo
-	opts         *scalerOptions
+	config       *papi.Config
+	auditSink    audit.Sink
 }
 
-func newResourceScaler(client client.Client, scaler scalev1.ScaleInterface, logger logr.Logger, opts *scalerOptions, namespace string, resourceInfo scalableResourceInfo) resourceScaler {
+func newResourceScaler(client client.Client, scaler scalev1.ScaleInterface, auditSink audit.Sink, logger logr.Logger, config *papi.Config, namespace string, resourceInfo scalableResourceInfo) resourceScaler {
 	resLogger := logger.WithValues("resNamespace", namespace, "kind", resourceInfo.ref.Kind, "apiVersion", resourceInfo.ref.APIVersion, "name", resourceInfo.ref.Name, "level", resourceInfo.level)
 	return &resScaler{
 		client:       client,
@@ -62,33 +67,39 @@ func newResourceScaler(client client.Client, scaler scalev1.ScaleInterface, logg
 		logger:       resLogger,
 		namespace:    namespace,
 		resourceInfo: resourceInfo,
-		opts:         opts,
+		config:       config,
+		auditSink:    auditSink,
 	}
 }
 
-func (r *resScaler) scale(ctx context.Context) error {
+func (r *resScaler) scale(ctx context.Context) (ResourceResult, error) {
 	var (
 		err           error
 		resourceAnnot map[string]string
 	)
+	result := newResourceResult(r.resourceInfo)
 	// sleep for initial delay
 	if err = util.SleepWithContext(ctx, r.resourceInfo.initialDelay); err != nil {
 		r.logger.Error(err, "Looks like the context has been cancelled. exiting scaling operation")
-		return err
+		result.Outcome = ResourceFailed
+		return result, err
 	}
 
 	if resourceAnnot, err = util.GetResourceAnnotations(ctx, r.client, r.namespace, r.resourceInfo.ref); err != nil {
 		if apierrors.IsNotFound(err) && r.resourceInfo.optional {
 			r.logger.Info("Resource not found. Ignoring this resource as its existence is marked as optional")
-			return nil
+			result.Outcome = ResourceSkippedOptionalNotFound
+			return result, nil
 		}
 		r.logger.Error(err, "Error trying to get annotations for resource")
-		return err
+		result.Outcome = ResourceFailed
+		return result, err
 	}
 
 	if ignoreScaling(resourceAnnot) {
 		r.logger.Info("Scaling ignored due to explicit instruction via annotation", "annotation", ignoreScalingAnnotationKey)
-		return nil
+		result.Outcome = ResourceSkippedIgnoreScaling
+		return result, nil
 	}
 
 	_, scaleSubRes, err := util.GetScaleResource(ctx, r.client, r.scaler, r.logger, r.resourceInfo.ref, r.resourceInfo.timeout)
@@ -96,22 +107,43 @@ func (r *resScaler) scale(ctx context.Context) error {
 		if apierrors.IsNotFound(err) {
 			r.logger.Error(err, "Resource does not have a scale subresource. Skipping scaling of dependent resources. Invalid config file")
 		}
-		return err
+		result.Outcome = ResourceFailed
+		return result, err
 	}
 
+	result.ReplicasBefore = scaleSubRes.Spec.Replicas
+	result.ReplicasAfter = scaleSubRes.Spec.Replicas
 	if r.resourceInfo.operation.shouldScaleReplicas(scaleSubRes.Spec.Replicas) {
-		if err := r.updateResourceAndScale(ctx, scaleSubRes, resourceAnnot); err != nil {
-			return err
+		// the scale condition is only evaluated if the resource actually has to be scaled, as evaluating it requires
+		// fetching the cluster and the resource.
+		conditionMet, err := r.conditionMet(ctx)
+		if err != nil {
+			r.logger.Error(err, "Error trying to evaluate scale condition for resource")
+			result.Outcome = ResourceFailed
+			return result, err
+		}
+		if !conditionMet {
+			r.logger.Info("Scaling skipped as scale condition is not met")
+			result.Outcome = ResourceSkippedConditionNotMet
+			return result, nil
+		}
+		targetReplicas, err := r.updateResourceAndScale(ctx, scaleSubRes, resourceAnnot)
+		if err != nil {
+			result.Outcome = ResourceFailed
+			return result, err
 		}
+		result.Outcome = ResourceScaled
+		result.ReplicasAfter = targetReplicas
 	} else {
 		if r.resourceInfo.operation == scaleUp {
 			r.logger.Info("Skipping scale-up for resource as current spec replicas > 0")
 		} else {
 			r.logger.Info("Skipping scale-down for resource as current spec replicas == 0")
 		}
+		result.Outcome = ResourceSkippedAlreadyAtTarget
 	}
 
-	return r.waitTillMinTargetReplicasReached(ctx)
+	return result, r.waitTillMinTargetReplicasReached(ctx)
 }
 
 func (r *resScaler) waitTillMinTargetReplicasReached(ctx context.Context) error {
@@ -130,37 +162,46 @@ func (r *resScaler) waitTillMinTargetReplicasReached(ctx context.Context) error
 			return true
 		}
 		return false
-	}, *r.opts.resourceCheckTimeout, *r.opts.resourceCheckInterval)
+	}, r.config.ResourceCheckTimeout.Duration, r.config.ResourceCheckInterval.Duration)
 	if !resMinTargetReached {
 		return fmt.Errorf("timed out waiting for {namespace: %s, resource: %s} to reach minTargetReplicas %d", r.namespace, r.resourceInfo.ref.Name, minTargetReplicas)
 	}
 	return nil
 }
 
-func (r *resScaler) updateResourceAndScale(ctx context.Context, scaleSubRes *autoscalingv1.Scale, annot map[string]string) error {
+// updateResourceAndScale updates the replicas of the resource and returns the target replicas that have been set. Each
+// update, successful or not, is appended to the audit log.
+func (r *resScaler) updateResourceAndScale(ctx context.Context, scaleSubRes *autoscalingv1.Scale, annot map[string]string) (int32, error) {
+	replicasBefore := scaleSubRes.Spec.Replicas
+	targetReplicas, err := r.doUpdateResourceAndScale(ctx, scaleSubRes, annot)
+	r.auditScaling(ctx, replicasBefore, targetReplicas, err)
+	return targetReplicas, err
+}
+
+func (r *resScaler) doUpdateResourceAndScale(ctx context.Context, scaleSubRes *autoscalingv1.Scale, annot map[string]string) (int32, error) {
 	childCtx, cancelFn := context.WithTimeout(ctx, r.resourceInfo.timeout)
 	defer cancelFn()
 
 	// update the annotation capturing the current spec.replicas as the annotation value if the operation is scale down.
 	// This allows restoration of the resource to the same replica count when a subsequent scale up operation is triggered.
 	if r.resourceInfo.operation == scaleDown {
-		patchBytes := []byte(fmt.Sprintf("{\"metadata\":{\"annotations\":{\"%s\":\"%s\"}}}", replicasAnnotationKey, strconv.Itoa(int(scaleSubRes.Spec.Replicas))))
+		patchBytes := []byte(fmt.Sprintf("{\"metadata\":{\"annotations\":{\"%s\":\"%s\"}}}", ReplicasAnnotationKey, strconv.Itoa(int(scaleSubRes.Spec.Replicas))))
 		err := util.PatchResourceAnnotations(ctx, r.client, r.namespace, r.resourceInfo.ref, patchBytes)
 		if err != nil {
 			r.logger.Error(err, "Failed to update annotation to capture the current replicas before scaling it down")
-			return err
+			return 0, err
 		}
 	}
 
 	targetReplicas, err := r.determineTargetReplicas(annot)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// need the updated scale subresource
 	gr, scaleSubRes, err := util.GetScaleResource(ctx, r.client, r.scaler, r.logger, r.resourceInfo.ref, r.resourceInfo.timeout)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	scaleSubRes.Spec.Replicas = targetReplicas
@@ -170,24 +211,24 @@ func (r *resScaler) updateResourceAndScale(ctx context.Context, scaleSubRes *aut
 		r.logger.Info("Scaling down kubernetes resource", "targetReplicas", targetReplicas)
 	}
 	if _, err = r.scaler.Update(childCtx, *gr, scaleSubRes, metav1.UpdateOptions{}); err != nil {
-		return err
+		return 0, err
 	}
 	r.logger.Info("Waiting for resource readiness")
-	return nil
+	return targetReplicas, nil
 }
 
 func (r *resScaler) determineTargetReplicas(annotations map[string]string) (int32, error) {
 	if r.resourceInfo.operation == scaleDown {
 		return defaultScaleDownReplicas, nil
 	}
-	if replicasStr, ok := annotations[replicasAnnotationKey]; ok {
+	if replicasStr, ok := annotations[ReplicasAnnotationKey]; ok {
 		replicas, err := strconv.Atoi(replicasStr)
 		if err != nil {
-			return 0, fmt.Errorf("unexpected and invalid replicasStr set as value for annotation: %s for resource, Err: %w", replicasAnnotationKey, err)
+			return 0, fmt.Errorf("unexpected and invalid replicasStr set as value for annotation: %s for resource, Err: %w", ReplicasAnnotationKey, err)
 		}
 		return int32(replicas), nil
 	}
-	r.logger.Info("Replicas annotation not found, falling back to default scale-up replicas", "operation", r.resourceInfo.operation, "annotationKey", replicasAnnotationKey, "default-replicas", defaultScaleUpReplicas)
+	r.logger.Info("Replicas annotation not found, falling back to default scale-up replicas", "operation", r.resourceInfo.operation, "annotationKey", ReplicasAnnotationKey, "default-replicas", defaultScaleUpReplicas)
 	return defaultScaleUpReplicas, nil
 }
 
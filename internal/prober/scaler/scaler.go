@@ -20,8 +20,9 @@ import (
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
-	"github.com/gardener/gardener/pkg/utils/flow"
+	"github.com/gardener/dependency-watchdog/internal/audit"
 	"github.com/go-logr/logr"
+	multierr "github.com/hashicorp/go-multierror"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	scalev1 "k8s.io/client-go/scale"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,44 +42,117 @@ const (
 
 // Scaler is a facade to provide scaling operations for kubernetes scalable resources.
 type Scaler interface {
-	// ScaleUp restores the replicas of a kubernetes resource prior to scale down.
-	ScaleUp(ctx context.Context) error
-	// ScaleDown scales down a kubernetes scalable resource to 0.
-	ScaleDown(ctx context.Context) error
+	// ScaleUp restores the replicas of a kubernetes resource prior to scale down. Escalation stages are de-escalated in reverse
+	// order, i.e. resources of the last stage are scaled up first. It returns a Result capturing the outcome for each of the resources.
+	ScaleUp(ctx context.Context) Result
+	// ScaleDown scales down to 0 the kubernetes scalable resources of all escalation stages which are due given the duration
+	// for which the external probe has been unhealthy. It returns a Result capturing the outcome for each of the resources.
+	ScaleDown(ctx context.Context, unhealthyFor time.Duration) Result
+	// ScaleDownResources scales down to 0 the kubernetes scalable resources with the given names, irrespective of escalation
+	// stages. Only resource sets which are part of a decision of the decision matrix can be scaled down. It returns a
+	// Result capturing the outcome for each of the resources.
+	ScaleDownResources(ctx context.Context, resourceNames []string) Result
 }
 
-// NewScaler creates an instance of Scaler.
-func NewScaler(namespace string, config *papi.Config, client client.Client, scalerGetter scalev1.ScalesGetter, logger logr.Logger, options ...scalerOption) Scaler {
+// NewScaler creates an instance of Scaler. Every scaling of a dependent resource is appended to auditSink, unless it is nil.
+func NewScaler(namespace string, config *papi.Config, client client.Client, scalerGetter scalev1.ScalesGetter, auditSink audit.Sink, logger logr.Logger) Scaler {
 	//logger = logger.WithName("scaleFlowRunner")
-	opts := buildScalerOptions(options...)
 
-	fc := newFlowCreator(client, scalerGetter.Scales(namespace), logger, opts, config.DependentResourceInfos)
-	scaleUpFlow := fc.createFlow(fmt.Sprintf("scale-up-%s", namespace), namespace, scaleUp)
-	logger.V(1).Info("Created scaleUpFlow", "flowStepInfos", scaleUpFlow.flowStepInfos)
-	scaleDownFlow := fc.createFlow(fmt.Sprintf("scale-down-%s", namespace), namespace, scaleDown)
-	logger.V(1).Info("Created scaleDownFlow", "flowStepInfos", scaleDownFlow.flowStepInfos)
+	scaler := scalerGetter.Scales(namespace)
+	fc := newFlowCreator(client, scaler, auditSink, logger, config)
+	stages := getEscalationStages(config)
+	scaleUpFlows, scaleDownFlows := createEscalationFlows(fc, namespace, config.DependentResourceInfos, stages, logger)
+	resourceSetScaleDownFlows := createResourceSetScaleDownFlows(fc, namespace, config.DependentResourceInfos, config.DecisionMatrix, logger)
+
+	scaleDownFailurePolicy := papi.ScaleDownFailurePolicyRetry
+	if config.ScaleDownFailurePolicy != nil {
+		scaleDownFailurePolicy = *config.ScaleDownFailurePolicy
+	}
 
 	return &scaleFlowRunner{
-		namespace:     namespace,
-		options:       opts,
-		scaleUpFlow:   scaleUpFlow.flow,
-		scaleDownFlow: scaleDownFlow.flow,
+		namespace:                 namespace,
+		client:                    client,
+		scaler:                    scaler,
+		auditSink:                 auditSink,
+		logger:                    logger,
+		config:                    config,
+		escalationStages:          stages,
+		scaleUpFlows:              scaleUpFlows,
+		scaleDownFlows:            scaleDownFlows,
+		resourceSetScaleDownFlows: resourceSetScaleDownFlows,
+		scaleUpResourceInfos:      createScalableResourceInfos(scaleUp, config.DependentResourceInfos),
+		scaleDownFailurePolicy:    scaleDownFailurePolicy,
 	}
 }
 
 type scaleFlowRunner struct {
-	namespace     string
-	scaleDownFlow *flow.Flow
-	scaleUpFlow   *flow.Flow
-	options       *scalerOptions
+	namespace        string
+	client           client.Client
+	scaler           scalev1.ScaleInterface
+	auditSink        audit.Sink
+	logger           logr.Logger
+	escalationStages []papi.EscalationStage
+	// scaleDownFlows has one flow per escalation stage, comprising the resources of the stage and all its previous stages.
+	scaleDownFlows []*scaleFlow
+	// scaleUpFlows has one flow per escalation stage, comprising only the resources of the stage.
+	scaleUpFlows []*scaleFlow
+	// resourceSetScaleDownFlows has one scale-down flow per resource set of the decision matrix, keyed by resourceSetKey.
+	resourceSetScaleDownFlows map[string]*scaleFlow
+	config                    *papi.Config
+	scaleUpResourceInfos      []scalableResourceInfo
+	scaleDownFailurePolicy    papi.ScaleDownFailurePolicy
+}
+
+func (ds *scaleFlowRunner) ScaleDown(ctx context.Context, unhealthyFor time.Duration) Result {
+	stage := getDueEscalationStage(ds.escalationStages, unhealthyFor)
+	if stage < 0 {
+		ds.logger.V(1).Info("No escalation stage is due yet, skipping scale-down", "unhealthyFor", unhealthyFor, "firstStageAfter", ds.escalationStages[0].After.Duration)
+		return Result{Operation: scaleDown.String()}
+	}
+	return ds.runScaleDownFlow(ctx, ds.scaleDownFlows[stage], "escalationStage", stage)
 }
 
-func (ds *scaleFlowRunner) ScaleDown(ctx context.Context) error {
-	return ds.scaleDownFlow.Run(ctx, flow.Opts{})
+func (ds *scaleFlowRunner) ScaleDownResources(ctx context.Context, resourceNames []string) Result {
+	sf, ok := ds.resourceSetScaleDownFlows[resourceSetKey(resourceNames)]
+	if !ok {
+		return Result{Operation: scaleDown.String(), Err: fmt.Errorf("no scale-down flow exists for resources %v as they are not part of the decision matrix", resourceNames)}
+	}
+	return ds.runScaleDownFlow(ctx, sf, "resourceNames", resourceNames)
 }
 
-func (ds *scaleFlowRunner) ScaleUp(ctx context.Context) error {
-	return ds.scaleUpFlow.Run(ctx, flow.Opts{})
+// runScaleDownFlow runs the given scale-down flow. If the flow fails, resources which have been scaled down are rolled back
+// if so required by the configured scale-down failure policy. keysAndValues identify the flow in the logs.
+func (ds *scaleFlowRunner) runScaleDownFlow(ctx context.Context, sf *scaleFlow, keysAndValues ...interface{}) Result {
+	result := sf.run(ctx, scaleDown)
+	if result.Err == nil {
+		return result
+	}
+	scaledResources := result.ScaledResources()
+	if ds.scaleDownFailurePolicy == papi.ScaleDownFailurePolicyRollback && len(scaledResources) > 0 {
+		var rollbackErr error
+		result.RolledBackResources, rollbackErr = ds.rollback(ctx, scaledResources)
+		if rollbackErr != nil {
+			result.Err = multierr.Append(result.Err, rollbackErr)
+		}
+	}
+	ds.logger.Info("Scale-down flow failed", append(keysAndValues, "scaleDownFailurePolicy", ds.scaleDownFailurePolicy, "scaledResources", scaledResources, "rolledBackResources", result.RolledBackResources)...)
+	result.Err = fmt.Errorf("scale-down flow failed: %w", result.Err)
+	return result
+}
+
+func (ds *scaleFlowRunner) ScaleUp(ctx context.Context) Result {
+	result := Result{Operation: scaleUp.String()}
+	for stage := len(ds.scaleUpFlows) - 1; stage >= 0; stage-- {
+		stageResult := ds.scaleUpFlows[stage].run(ctx, scaleUp)
+		result.ResourceResults = append(result.ResourceResults, stageResult.ResourceResults...)
+		result.LevelResults = append(result.LevelResults, stageResult.LevelResults...)
+		result.Duration += stageResult.Duration
+		if stageResult.Err != nil {
+			result.Err = fmt.Errorf("scale-up flow of escalation stage %d failed: %w", stage, stageResult.Err)
+			return result
+		}
+	}
+	return result
 }
 
 // getMinTargetReplicas gets the minimum target replicas based on the operation.
@@ -122,7 +196,10 @@ type scalableResourceInfo struct {
 	level        int
 	initialDelay time.Duration
 	timeout      time.Duration
+	retryPolicy  papi.RetryPolicy
 	operation    operation
+	// condition is evaluated before scaling the resource. It is nil if no condition has been configured.
+	condition *scaleCondition
 }
 
 func (r scalableResourceInfo) String() string {
@@ -31,6 +31,7 @@ import (
 
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -67,6 +68,7 @@ func TestScalerSuite(t *testing.T) {
 		{"test scale down then scale up when ignore scaling annotation is present", testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsPresent},
 		{"test scale up should not happen if current replica count is positive", testResourceShouldNotScaleUpIfCurrentReplicaCountIsPositive},
 		{"test scale up when replica annotation has invalid value", testScaleUpShouldReturnErrorWhenReplicasAnnotationsHasInvalidValue},
+		{"test failed scale down is rolled back when scale down failure policy is rollback", testFailedScaleDownIsRolledBackWhenPolicyIsRollback},
 	}
 	for _, test := range tests {
 		test := test
@@ -102,13 +104,13 @@ func testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsNotPresent(t *testing.
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, nil)
 
-		err := ds.ScaleDown(context.Background())
+		err := ds.ScaleDown(context.Background(), 0).Err
 		g.Expect(err).To(BeNil())
 		checkScaleSuccess(g, scaleDown, namespace, caObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		checkScaleSuccess(g, scaleDown, namespace, mcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		checkScaleSuccess(g, scaleDown, namespace, kcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 
-		err = ds.ScaleUp(context.Background())
+		err = ds.ScaleUp(context.Background()).Err
 		g.Expect(err).To(BeNil())
 		checkScaleSuccess(g, scaleUp, namespace, mcmObjectRef.Name, entry.expectedScaledUpMCMReplicas)
 		checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, entry.expectedScaledUpCAReplicas)
@@ -148,7 +150,7 @@ func testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsPresent(t *testing.T)
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, entry.annotationsOnKCM)
 
-		err := ds.ScaleDown(context.Background())
+		err := ds.ScaleDown(context.Background(), 0).Err
 		g.Expect(err).To(BeNil())
 		checkScaleSuccess(g, scaleDown, namespace, caObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		checkScaleSuccess(g, scaleDown, namespace, mcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
@@ -158,7 +160,7 @@ func testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsPresent(t *testing.T)
 			checkScaleSuccess(g, scaleDown, namespace, kcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		}
 
-		err = ds.ScaleUp(context.Background())
+		err = ds.ScaleUp(context.Background()).Err
 		g.Expect(err).To(BeNil())
 		checkScaleSuccess(g, scaleUp, namespace, mcmObjectRef.Name, entry.expectedScaledUpMCMReplicas)
 		checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, entry.expectedScaledUpCAReplicas)
@@ -181,7 +183,7 @@ func testScalingWhenMandatoryResourceNotFound(t *testing.T) {
 	table := []struct {
 		mcmReplicas                          int32
 		caReplicas                           int32
-		scalingFn                            func(ctx context.Context) error
+		scalingFn                            func(ctx context.Context) Result
 		op                                   operation
 		unscaledResourceName                 string
 		scaledResourceName                   string
@@ -189,13 +191,13 @@ func testScalingWhenMandatoryResourceNotFound(t *testing.T) {
 		expectedScaledResourceSpecReplicas   int32
 	}{
 		{0, 0, ds.ScaleUp, scaleUp, mcmObjectRef.Name, caObjectRef.Name, 0, 1},
-		{2, 2, ds.ScaleDown, scaleDown, caObjectRef.Name, mcmObjectRef.Name, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest},
+		{2, 2, scaleDownWithoutEscalation(ds), scaleDown, caObjectRef.Name, mcmObjectRef.Name, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest},
 	}
 	for _, entry := range table {
 		createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, entry.mcmReplicas, nil)
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 
-		err := entry.scalingFn(context.Background())
+		err := entry.scalingFn(context.Background()).Err
 		g.Expect(err).ToNot(BeNil())
 		g.Expect(err.Error()).To(ContainSubstring("\"" + kcmObjectRef.Name + "\" not found"))
 		matchSpecReplicas(g, namespace, entry.unscaledResourceName, entry.expectedUnscaledResourceSpecReplicas)
@@ -216,22 +218,30 @@ func testScalingWhenOptionalResourceNotFound(t *testing.T) {
 		kcmReplicas               int32
 		expectedScaledMCMReplicas int32
 		expectedScaledCAReplicas  int32
-		scalingFn                 func(context.Context) error
+		scalingFn                 func(context.Context) Result
 		op                        operation
 	}{
 		{0, 0, 1, 1, ds.ScaleUp, scaleUp},
-		{2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, ds.ScaleDown, scaleDown},
+		{2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, scaleDownWithoutEscalation(ds), scaleDown},
 	}
 	for _, entry := range table {
 		createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, entry.mcmReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, nil)
 
-		err := entry.scalingFn(context.Background())
-		g.Expect(err).To(BeNil())
+		result := entry.scalingFn(context.Background())
+		g.Expect(result.Err).To(BeNil())
+		g.Expect(result.Operation).To(Equal(entry.op.String()))
+		g.Expect(result.ResourceResults).To(HaveLen(3))
+		g.Expect(result.ScaledResources()).To(HaveLen(2))
+		for _, resResult := range result.ResourceResults {
+			if resResult.Ref.Name == caObjectRef.Name {
+				g.Expect(resResult.Outcome).To(Equal(ResourceSkippedOptionalNotFound))
+			}
+		}
 		checkScaleSuccess(g, entry.op, namespace, mcmObjectRef.Name, entry.expectedScaledMCMReplicas)
 		checkScaleSuccess(g, entry.op, namespace, kcmObjectRef.Name, entry.expectedScaledCAReplicas)
 
-		err = kindTestEnv.DeleteAllDeployments(namespace)
+		err := kindTestEnv.DeleteAllDeployments(namespace)
 		g.Expect(err).To(BeNil())
 	}
 	t.Log("scaling when optional resource not found test finished")
@@ -250,11 +260,11 @@ func testGettingScaleSubResourceTimesOut(t *testing.T) {
 		expectedScaledMCMReplicas int32
 		expectedScaledKCMReplicas int32
 		expectedScaledCAReplicas  int32
-		scalingFn                 func(context.Context) error
+		scalingFn                 func(context.Context) Result
 		errorString               string
 	}{
 		{0, 0, 0, 0, 0, 0, ds.ScaleUp, "context deadline exceeded"},
-		{1, 1, 1, 1, 1, 1, ds.ScaleDown, "context deadline exceeded"},
+		{1, 1, 1, 1, 1, 1, scaleDownWithoutEscalation(ds), "context deadline exceeded"},
 	}
 
 	for _, entry := range table {
@@ -262,7 +272,7 @@ func testGettingScaleSubResourceTimesOut(t *testing.T) {
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, nil)
 
-		err := entry.scalingFn(context.Background())
+		err := entry.scalingFn(context.Background()).Err
 		g.Expect(err).ToNot(BeNil())
 		g.Expect(err.Error()).To(ContainSubstring(entry.errorString))
 		matchSpecReplicas(g, namespace, caObjectRef.Name, entry.expectedScaledCAReplicas)
@@ -285,7 +295,7 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 		mcmReplicas                          int32
 		kcmReplicas                          int32
 		caReplicas                           int32
-		scalingFn                            func(context.Context) error
+		scalingFn                            func(context.Context) Result
 		op                                   operation
 		errorString                          string
 		scaledResourceName                   string
@@ -294,7 +304,7 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 		expectedUnscaledResourceSpecReplicas []int32
 	}{
 		{0, 0, 0, ds.ScaleUp, scaleUp, "no matches for kind \"Depoyment\" in version \"apps/v1\"", caObjectRef.Name, []string{mcmObjectRef.Name, kcmObjectRef.Name}, 1, []int32{0, 0}},
-		{2, 2, 2, ds.ScaleDown, scaleDown, "no matches for kind \"Depoyment\" in version \"apps/v1\"", mcmObjectRef.Name, []string{caObjectRef.Name, kcmObjectRef.Name}, expectedSpecReplicasAfterSuccessfulScaleDownTest, []int32{2, 2}},
+		{2, 2, 2, scaleDownWithoutEscalation(ds), scaleDown, "no matches for kind \"Depoyment\" in version \"apps/v1\"", mcmObjectRef.Name, []string{caObjectRef.Name, kcmObjectRef.Name}, expectedSpecReplicasAfterSuccessfulScaleDownTest, []int32{2, 2}},
 	}
 
 	for _, entry := range table {
@@ -302,7 +312,7 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, nil)
 
-		err := entry.scalingFn(context.Background())
+		err := entry.scalingFn(context.Background()).Err
 		g.Expect(err).ToNot(BeNil())
 		g.Expect(err.Error()).To(ContainSubstring(entry.errorString))
 		checkScaleSuccess(g, entry.op, namespace, entry.scaledResourceName, entry.expectedScaledResourceSpecReplicas)
@@ -329,12 +339,12 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 //		expectedScaledMCMReplicas int32
 //		expectedScaledKCMReplicas int32
 //		expectedScaledCAReplicas  int32
-//		scalingFn                 func(context.Context) error
+//		scalingFn                 func(context.Context) Result
 //		op                        operation
 //		errorString               string
 //	}{
 //		{0, 0, 0, 0, 0, 1, ds.ScaleUp, scaleUp, fmt.Sprintf("timed out waiting for {namespace: %s, resource: %s} to reach minTargetReplicas", namespace, caObjectRef.Name)},
-//		{2, 2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, 2, ds.ScaleDown, scaleDown, "timed out waiting"}, // mcm or kcm can return error hence short string is used
+//		{2, 2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, 2, scaleDownWithoutEscalation(ds), scaleDown, "timed out waiting"}, // mcm or kcm can return error hence short string is used
 //	}
 //
 //	for _, entry := range table {
@@ -342,7 +352,7 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 //		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 //		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, nil)
 //
-//		err := entry.scalingFn(context.Background())
+//		err := entry.scalingFn(context.Background()).Err
 //		g.Expect(err).ToNot(BeNil())
 //		g.Expect(err.Error()).To(ContainSubstring(entry.errorString))
 //		matchSpecReplicas(g, namespace, caObjectRef.Name, entry.expectedScaledCAReplicas)
@@ -361,9 +371,9 @@ func testResourceShouldNotScaleUpIfCurrentReplicaCountIsPositive(t *testing.T) {
 	ds := createDefaultScaler(g, probeCfg)
 	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 0, nil)
 	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 0, nil)
-	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 1, map[string]string{replicasAnnotationKey: "2"})
+	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 1, map[string]string{ReplicasAnnotationKey: "2"})
 
-	err := ds.ScaleUp(context.Background())
+	err := ds.ScaleUp(context.Background()).Err
 	g.Expect(err).To(BeNil())
 	checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, 1)
 	checkScaleSuccess(g, scaleUp, namespace, kcmObjectRef.Name, 1)
@@ -380,9 +390,9 @@ func testScaleUpShouldReturnErrorWhenReplicasAnnotationsHasInvalidValue(t *testi
 	ds := createDefaultScaler(g, probeCfg)
 	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 0, nil)
 	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 0, nil)
-	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 0, map[string]string{replicasAnnotationKey: "foo"})
+	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 0, map[string]string{ReplicasAnnotationKey: "foo"})
 
-	err := ds.ScaleUp(context.Background())
+	err := ds.ScaleUp(context.Background()).Err
 	g.Expect(err).ToNot(BeNil())
 	checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, 1)
 	matchSpecReplicas(g, namespace, kcmObjectRef.Name, 0)
@@ -393,6 +403,31 @@ func testScaleUpShouldReturnErrorWhenReplicasAnnotationsHasInvalidValue(t *testi
 	t.Log("Res should not scale up if replica annotation is incorrect test finished")
 }
 
+func testFailedScaleDownIsRolledBackWhenPolicyIsRollback(t *testing.T) {
+	g := NewWithT(t)
+	probeCfg := createProbeConfig(nil)
+	rollbackPolicy := papi.ScaleDownFailurePolicyRollback
+	probeCfg.ScaleDownFailurePolicy = &rollbackPolicy
+	ds := createDefaultScaler(g, probeCfg)
+	// kube-controller-manager is a mandatory resource which is not created, causing the scale-down flow to fail after
+	// machine-controller-manager has been scaled down at the same level.
+	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 2, nil)
+	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 2, nil)
+
+	result := ds.ScaleDown(context.Background(), 0)
+	g.Expect(result.Err).ToNot(BeNil())
+	g.Expect(result.Err.Error()).To(ContainSubstring("\"" + kcmObjectRef.Name + "\" not found"))
+	g.Expect(result.RolledBackResources).To(HaveLen(1))
+	g.Expect(result.RolledBackResources[0].Ref.Name).To(Equal(mcmObjectRef.Name))
+	g.Expect(result.RolledBackResources[0].Outcome).To(Equal(ResourceScaled))
+	checkScaleSuccess(g, scaleUp, namespace, mcmObjectRef.Name, 2)
+	matchSpecReplicas(g, namespace, caObjectRef.Name, 2)
+
+	err := kindTestEnv.DeleteAllDeployments(namespace)
+	g.Expect(err).To(BeNil())
+	t.Log("failed scale down is rolled back test finished")
+}
+
 // utility methods to be used by tests
 // ------------------------------------------------------------------------------------------------------------------
 
@@ -414,11 +449,25 @@ func createScaler(g *WithT, probeCfg *papi.Config, resCheckTimeout time.Duration
 	cfg := kindTestEnv.GetRestConfig()
 	scalesGetter, err := util.CreateScalesGetter(cfg)
 	g.Expect(err).To(BeNil())
-	ds := NewScaler(namespace, probeCfg, kindTestEnv.GetClient(), scalesGetter, scalerTestLogger,
-		withResourceCheckTimeout(resCheckTimeout), withResourceCheckInterval(resCheckInterval), withScaleResourceBackOff(scaleResBackoff))
+	probeCfg.ResourceCheckTimeout = &metav1.Duration{Duration: resCheckTimeout}
+	probeCfg.ResourceCheckInterval = &metav1.Duration{Duration: resCheckInterval}
+	for _, resInfo := range probeCfg.DependentResourceInfos {
+		for _, scaleInfo := range []*papi.ScaleInfo{resInfo.ScaleUpInfo, resInfo.ScaleDownInfo} {
+			scaleInfo.RetryPolicy.InitialBackOff = &metav1.Duration{Duration: scaleResBackoff}
+			scaleInfo.RetryPolicy.MaxBackOff = &metav1.Duration{Duration: scaleResBackoff}
+		}
+	}
+	ds := NewScaler(namespace, probeCfg, kindTestEnv.GetClient(), scalesGetter, nil, scalerTestLogger)
 	return ds
 }
 
+// scaleDownWithoutEscalation returns a function which scales down the resources of all escalation stages which are due as soon as the external probe is unhealthy.
+func scaleDownWithoutEscalation(ds Scaler) func(context.Context) Result {
+	return func(ctx context.Context) Result {
+		return ds.ScaleDown(ctx, 0)
+	}
+}
+
 func createDeployment(g *WithT, namespace, name, deploymentImageName string, replicas int32, annotations map[string]string) {
 	err := kindTestEnv.CreateDeployment(name, namespace, deploymentImageName, replicas, annotations)
 	g.Expect(err).To(BeNil())
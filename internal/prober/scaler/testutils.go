@@ -29,6 +29,8 @@ import (
 const (
 	defaultTimeout       = 10 * time.Second
 	defaultInitialDelay  = 10 * time.Millisecond
+	defaultMaxAttempts   = 3
+	defaultBackOff       = 100 * time.Millisecond
 	deploymentKind       = "Deployment"
 	deploymentAPIVersion = "apps/v1"
 )
@@ -53,15 +55,25 @@ func createTestDeploymentDependentResourceInfo(name string, scaleUpLevel, scaleD
 			Level:        scaleUpLevel,
 			InitialDelay: &metav1.Duration{Duration: *initialDelay},
 			Timeout:      &metav1.Duration{Duration: *timeout},
+			RetryPolicy:  createTestRetryPolicy(),
 		},
 		ScaleDownInfo: &papi.ScaleInfo{
 			Level:        scaleDownLevel,
 			InitialDelay: &metav1.Duration{Duration: *initialDelay},
 			Timeout:      &metav1.Duration{Duration: *timeout},
+			RetryPolicy:  createTestRetryPolicy(),
 		},
 	}
 }
 
+func createTestRetryPolicy() *papi.RetryPolicy {
+	return &papi.RetryPolicy{
+		MaxAttempts:    pointer.Int(defaultMaxAttempts),
+		InitialBackOff: &metav1.Duration{Duration: defaultBackOff},
+		MaxBackOff:     &metav1.Duration{Duration: defaultBackOff},
+	}
+}
+
 func createTestScalableResourceInfos(numResInfosByLevel map[int]int) []scalableResourceInfo {
 	var resInfos []scalableResourceInfo
 	for k, v := range numResInfosByLevel {
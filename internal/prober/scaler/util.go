@@ -18,42 +18,59 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // createScalableResourceInfos creates slice of scalableResourceInfo from an operation and slice of papi.DependentResourceInfo.
 func createScalableResourceInfos(op operation, dependentResourceInfos []papi.DependentResourceInfo) []scalableResourceInfo {
 	resourceInfos := make([]scalableResourceInfo, 0, len(dependentResourceInfos))
 	for _, depResInfo := range dependentResourceInfos {
-		var (
-			level                 int
-			initialDelay, timeout time.Duration
-		)
+		scaleInfo := depResInfo.ScaleDownInfo
 		if op == scaleUp {
-			level = depResInfo.ScaleUpInfo.Level
-			initialDelay = depResInfo.ScaleUpInfo.InitialDelay.Duration
-			timeout = depResInfo.ScaleUpInfo.Timeout.Duration
-		} else {
-			level = depResInfo.ScaleDownInfo.Level
-			initialDelay = depResInfo.ScaleDownInfo.InitialDelay.Duration
-			timeout = depResInfo.ScaleDownInfo.Timeout.Duration
+			scaleInfo = depResInfo.ScaleUpInfo
 		}
 		resInfo := scalableResourceInfo{
 			ref:          depResInfo.Ref,
 			optional:     depResInfo.Optional,
-			level:        level,
-			initialDelay: initialDelay,
-			timeout:      timeout,
+			level:        scaleInfo.Level,
+			initialDelay: scaleInfo.InitialDelay.Duration,
+			timeout:      scaleInfo.Timeout.Duration,
+			retryPolicy:  *scaleInfo.RetryPolicy,
 			operation:    op,
+			condition:    newScaleCondition(scaleInfo.Condition),
 		}
 		resourceInfos = append(resourceInfos, resInfo)
 	}
 	return resourceInfos
 }
 
+// filterDependentResourceInfos filters the papi.DependentResourceInfo whose resource name is one of the given resource names.
+func filterDependentResourceInfos(dependentResourceInfos []papi.DependentResourceInfo, resourceNames []string) []papi.DependentResourceInfo {
+	filtered := make([]papi.DependentResourceInfo, 0, len(resourceNames))
+	for _, depResInfo := range dependentResourceInfos {
+		for _, resourceName := range resourceNames {
+			if depResInfo.Ref.Name == resourceName {
+				filtered = append(filtered, depResInfo)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// mapToDependentResourceNames maps a slice of papi.DependentResourceInfo to the names of the resources.
+func mapToDependentResourceNames(dependentResourceInfos []papi.DependentResourceInfo) []string {
+	resourceNames := make([]string, 0, len(dependentResourceInfos))
+	for _, depResInfo := range dependentResourceInfos {
+		resourceNames = append(resourceNames, depResInfo.Ref.Name)
+	}
+	return resourceNames
+}
+
 func sortAndGetUniqueLevels(resourceInfos []scalableResourceInfo) []int {
 	var levels []int
 	keys := make(map[int]bool)
@@ -91,9 +108,52 @@ func mapToCrossVersionObjectRef(resourceInfos []scalableResourceInfo) []autoscal
 }
 
 func createTaskName(resInfos []scalableResourceInfo, level int) string {
-	resNames := make([]string, 0, len(resInfos))
-	for _, resInfo := range resInfos {
+	return fmt.Sprintf("scale:level-%d:%s", level, strings.Join(mapToResourceNames(resInfos), "#"))
+}
+
+func mapToResourceNames(resourceInfos []scalableResourceInfo) []string {
+	resNames := make([]string, 0, len(resourceInfos))
+	for _, resInfo := range resourceInfos {
 		resNames = append(resNames, resInfo.ref.Name)
 	}
-	return fmt.Sprintf("scale:level-%d:%s", level, strings.Join(resNames, "#"))
+	return resNames
+}
+
+// createBackOffFn creates a util.BackOffFn which backs off exponentially as configured by the retry policy.
+func createBackOffFn(retryPolicy papi.RetryPolicy) util.BackOffFn {
+	return util.ExponentialBackOff(retryPolicy.InitialBackOff.Duration, retryPolicy.MaxBackOff.Duration)
+}
+
+// createCanRetryFn creates a function which returns false for errors which belong to any of the non-retriable error classes.
+func createCanRetryFn(nonRetriableErrors []papi.ErrorClass) func(error) bool {
+	if len(nonRetriableErrors) == 0 {
+		return util.AlwaysRetry
+	}
+	return func(err error) bool {
+		for _, errorClass := range nonRetriableErrors {
+			if isErrorOfClass(err, errorClass) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func isErrorOfClass(err error, errorClass papi.ErrorClass) bool {
+	switch errorClass {
+	case papi.ErrorClassNotFound:
+		return apierrors.IsNotFound(err)
+	case papi.ErrorClassInvalid:
+		return apierrors.IsInvalid(err)
+	case papi.ErrorClassForbidden:
+		return apierrors.IsForbidden(err)
+	case papi.ErrorClassUnauthorized:
+		return apierrors.IsUnauthorized(err)
+	case papi.ErrorClassBadRequest:
+		return apierrors.IsBadRequest(err)
+	case papi.ErrorClassConflict:
+		return apierrors.IsConflict(err)
+	default:
+		return false
+	}
 }
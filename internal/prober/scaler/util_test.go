@@ -17,12 +17,15 @@
 package scaler
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
@@ -43,6 +46,7 @@ func TestCreateScaleUpResourceInfos(t *testing.T) {
 		g.Expect(depResInfos[i].ScaleUpInfo.Level).To(Equal(resInfo.level))
 		g.Expect(resInfo.initialDelay).To(Equal(defaultInitialDelay))
 		g.Expect(resInfo.timeout).To(Equal(defaultTimeout))
+		g.Expect(resInfo.retryPolicy).To(Equal(*depResInfos[i].ScaleUpInfo.RetryPolicy))
 	}
 }
 
@@ -154,6 +158,14 @@ func TestMapToCrossVersionObjectRefForEmptyResInfos(t *testing.T) {
 	g.Expect(objRefs).To(HaveLen(0))
 }
 
+func TestMapToResourceNames(t *testing.T) {
+	g := NewWithT(t)
+	resInfos := createTestScalableResourceInfos(map[int]int{0: 2})
+	resNames := mapToResourceNames(resInfos)
+	g.Expect(resNames).To(ConsistOf("resource-00", "resource-01"))
+	g.Expect(mapToResourceNames(nil)).To(BeEmpty())
+}
+
 func TestCreateTaskName(t *testing.T) {
 	g := NewWithT(t)
 	level := 1
@@ -162,3 +174,25 @@ func TestCreateTaskName(t *testing.T) {
 	taskName := createTaskName(resInfos, level)
 	g.Expect(taskName).To(Equal(expectedTaskName))
 }
+
+func TestCreateCanRetryFn(t *testing.T) {
+	g := NewWithT(t)
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	nonRetriableErrors := []papi.ErrorClass{papi.ErrorClassNotFound, papi.ErrorClassForbidden}
+	table := []struct {
+		nonRetriableErrors []papi.ErrorClass
+		err                error
+		expectedCanRetry   bool
+	}{
+		{nil, apierrors.NewNotFound(gr, "kcm"), true},
+		{nonRetriableErrors, apierrors.NewNotFound(gr, "kcm"), false},
+		{nonRetriableErrors, apierrors.NewForbidden(gr, "kcm", errors.New("forbidden")), false},
+		{nonRetriableErrors, apierrors.NewConflict(gr, "kcm", errors.New("conflict")), true},
+		{nonRetriableErrors, errors.New("connection refused"), true},
+		{[]papi.ErrorClass{papi.ErrorClassConflict}, apierrors.NewConflict(gr, "kcm", errors.New("conflict")), false},
+	}
+	for _, entry := range table {
+		canRetry := createCanRetryFn(entry.nonRetriableErrors)
+		g.Expect(canRetry(entry.err)).To(Equal(entry.expectedCanRetry), fmt.Sprintf("unexpected result for error %v with non retriable errors %v", entry.err, entry.nonRetriableErrors))
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"fmt"
+
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// scaleUpOperation is the operation of a Result of a scale-up flow run.
+	scaleUpOperation = "scale-up"
+	// scaleDownOperation is the operation of a Result of a scale-down flow run.
+	scaleDownOperation = "scale-down"
+	// rollbackOperation is used to report resources which have been restored after a failed scale-down flow.
+	rollbackOperation = "rollback"
+
+	eventReasonScaled         = "DWDScaled"
+	eventReasonScalingFailed  = "DWDScalingFailed"
+	eventReasonRolledBack     = "DWDRolledBack"
+	eventReasonRollbackFailed = "DWDRollbackFailed"
+)
+
+// reportScaleResult logs, records events and metrics for the given result of a scale flow run. Flow runs which neither
+// scaled a resource nor failed are the norm for a prober and are therefore only logged at a higher verbosity.
+func (p *Prober) reportScaleResult(ctx context.Context, result dwdScaler.Result) {
+	recordScaleResultMetrics(p.namespace, result)
+	p.updateScaledDownResources(result)
+	if !hasEffect(result) {
+		p.l.V(1).Info("Scale flow completed without changes", "operation", result.Operation, "duration", result.Duration, "resourceResults", result.ResourceResults)
+		return
+	}
+	p.recordScaleHistory(result)
+	if result.Err != nil {
+		p.l.Error(result.Err, "Scale flow failed", "operation", result.Operation, "duration", result.Duration, "resourceResults", result.ResourceResults, "levelResults", result.LevelResults, "rolledBackResources", result.RolledBackResources)
+	} else {
+		p.l.Info("Scale flow completed", "operation", result.Operation, "duration", result.Duration, "resourceResults", result.ResourceResults, "levelResults", result.LevelResults)
+	}
+	p.notifyScaleResult(result)
+	for _, resResult := range result.ResourceResults {
+		switch resResult.Outcome {
+		case dwdScaler.ResourceScaled:
+			p.recordResourceEvent(ctx, resResult.Ref, corev1.EventTypeNormal, eventReasonScaled,
+				fmt.Sprintf("%s: replicas changed from %d to %d", result.Operation, resResult.ReplicasBefore, resResult.ReplicasAfter))
+		case dwdScaler.ResourceFailed:
+			p.recordResourceEvent(ctx, resResult.Ref, corev1.EventTypeWarning, eventReasonScalingFailed,
+				fmt.Sprintf("%s failed: %v", result.Operation, resResult.Err))
+		}
+	}
+	for _, resResult := range result.RolledBackResources {
+		if resResult.Err != nil {
+			p.recordResourceEvent(ctx, resResult.Ref, corev1.EventTypeWarning, eventReasonRollbackFailed,
+				fmt.Sprintf("rollback of failed %s failed: %v", result.Operation, resResult.Err))
+			continue
+		}
+		p.recordResourceEvent(ctx, resResult.Ref, corev1.EventTypeNormal, eventReasonRolledBack,
+			fmt.Sprintf("rolled back failed %s: replicas changed from %d to %d", result.Operation, resResult.ReplicasBefore, resResult.ReplicasAfter))
+	}
+}
+
+// recordResourceEvent records an event on the dependent resource identified by the given reference. The resource is fetched to populate
+// its UID which is used to associate events with the resource. If the resource cannot be fetched, the event is still recorded.
+func (p *Prober) recordResourceEvent(ctx context.Context, ref autoscalingv1.CrossVersionObjectReference, eventType, reason, message string) {
+	obj := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: p.namespace,
+			Name:      ref.Name,
+		},
+	}
+	if err := p.client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		p.l.V(4).Info("Failed to get resource to record event, recording event without UID", "name", ref.Name, "err", err.Error())
+	}
+	p.eventRecorder.Event(obj, eventType, reason, message)
+}
+
+// recordDependentResourcesEvent records an event on each dependent resource.
+func (p *Prober) recordDependentResourcesEvent(ctx context.Context, eventType, reason, message string) {
+	for _, resInfo := range p.config.DependentResourceInfos {
+		p.recordResourceEvent(ctx, *resInfo.Ref, eventType, reason, message)
+	}
+}
+
+// hasEffect checks if the scale flow run has scaled at least one resource or has failed.
+func hasEffect(result dwdScaler.Result) bool {
+	return result.Err != nil || len(result.ScaledResources()) > 0
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const scaleResultTestNamespace = "shoot--test"
+
+func TestReportScaleResultRecordsEventsForScaledFailedAndRolledBackResources(t *testing.T) {
+	g := NewWithT(t)
+	mcmRef := autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "machine-controller-manager", APIVersion: "apps/v1"}
+	kcmRef := autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}
+	caRef := autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "cluster-autoscaler", APIVersion: "apps/v1"}
+	mcm := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: mcmRef.Name, Namespace: scaleResultTestNamespace}}
+	recorder := record.NewFakeRecorder(10)
+	p := NewProber(context.Background(), scaleResultTestNamespace, &papi.Config{}, fake.NewClientBuilder().WithObjects(mcm).Build(), nil, nil, recorder, nil, proberTestLogger)
+
+	p.reportScaleResult(context.Background(), dwdScaler.Result{
+		Operation: "scale-down",
+		ResourceResults: []dwdScaler.ResourceResult{
+			{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled, ReplicasBefore: 2, ReplicasAfter: 0},
+			{Ref: caRef, Outcome: dwdScaler.ResourceSkippedIgnoreScaling},
+			{Ref: kcmRef, Outcome: dwdScaler.ResourceFailed, Err: errors.New("not found")},
+		},
+		RolledBackResources: []dwdScaler.ResourceResult{
+			{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled, ReplicasBefore: 0, ReplicasAfter: 2},
+		},
+		Err: errors.New("scale-down flow failed"),
+	})
+
+	g.Expect(recorder.Events).To(HaveLen(3))
+	g.Expect(<-recorder.Events).To(Equal("Normal DWDScaled scale-down: replicas changed from 2 to 0"))
+	g.Expect(<-recorder.Events).To(Equal("Warning DWDScalingFailed scale-down failed: not found"))
+	g.Expect(<-recorder.Events).To(Equal("Normal DWDRolledBack rolled back failed scale-down: replicas changed from 0 to 2"))
+}
+
+func TestReportScaleResultRecordsNoEventsWhenNothingChanged(t *testing.T) {
+	g := NewWithT(t)
+	recorder := record.NewFakeRecorder(10)
+	p := NewProber(context.Background(), scaleResultTestNamespace, &papi.Config{}, fake.NewClientBuilder().Build(), nil, nil, recorder, nil, proberTestLogger)
+
+	p.reportScaleResult(context.Background(), dwdScaler.Result{
+		Operation: "scale-up",
+		ResourceResults: []dwdScaler.ResourceResult{
+			{Ref: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "kube-controller-manager", APIVersion: "apps/v1"}, Outcome: dwdScaler.ResourceSkippedAlreadyAtTarget, ReplicasBefore: 1, ReplicasAfter: 1},
+		},
+	})
+
+	g.Expect(recorder.Events).To(BeEmpty())
+}
@@ -30,7 +30,8 @@ import (
 // ShootClientCreator provides a facade to create kubernetes client targeting a shoot.
 type ShootClientCreator interface {
 	// CreateClient creates a new clientSet to connect to the Kube ApiServer running in the passed-in shoot control namespace.
-	CreateClient(ctx context.Context, logger logr.Logger, namespace string, secretName string, connectionTimeout time.Duration) (kubernetes.Interface, error)
+	// The passed-in overrides are applied to the kubeconfig found in the secret.
+	CreateClient(ctx context.Context, logger logr.Logger, namespace string, secretName string, connectionTimeout time.Duration, overrides util.KubeConfigOverrides) (kubernetes.Interface, error)
 }
 
 // NewShootClientCreator creates an instance of ShootClientCreator.
@@ -42,7 +43,7 @@ type shootclientCreator struct {
 	client.Client
 }
 
-func (s *shootclientCreator) CreateClient(ctx context.Context, logger logr.Logger, namespace string, secretName string, connectionTimeout time.Duration) (kubernetes.Interface, error) {
+func (s *shootclientCreator) CreateClient(ctx context.Context, logger logr.Logger, namespace string, secretName string, connectionTimeout time.Duration, overrides util.KubeConfigOverrides) (kubernetes.Interface, error) {
 	operation := fmt.Sprintf("get-secret-%s-for-namespace-%s", secretName, namespace)
 	retryResult := util.Retry(ctx, logger,
 		operation,
@@ -55,7 +56,7 @@ func (s *shootclientCreator) CreateClient(ctx context.Context, logger logr.Logge
 	if retryResult.Err != nil {
 		return nil, retryResult.Err
 	}
-	return util.CreateClientFromKubeConfigBytes(retryResult.Value, connectionTimeout)
+	return util.CreateClientFromKubeConfigBytes(retryResult.Value, connectionTimeout, overrides)
 }
 
 func canRetrySecretGet(err error) bool {
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	testenv "github.com/gardener/dependency-watchdog/internal/test"
+	"github.com/gardener/dependency-watchdog/internal/util"
 	"k8s.io/client-go/kubernetes/scheme"
 
 	"github.com/go-logr/logr"
@@ -72,7 +73,7 @@ func TestSuite(t *testing.T) {
 func testSecretNotFound(t *testing.T, namespace string) {
 	g := NewWithT(t)
 	setupShootClientTest(t, namespace)
-	k8sInterface, err := clientCreator.CreateClient(sctx, shootClientTestLogger, secret.ObjectMeta.Namespace, secret.ObjectMeta.Name, time.Second)
+	k8sInterface, err := clientCreator.CreateClient(sctx, shootClientTestLogger, secret.ObjectMeta.Namespace, secret.ObjectMeta.Name, time.Second, util.KubeConfigOverrides{})
 	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
 	g.Expect(k8sInterface).To(BeNil())
 }
@@ -83,7 +84,7 @@ func testConfigNotFound(t *testing.T, namespace string) {
 	defer teardown()
 	err := sk8sClient.Create(sctx, secret)
 	g.Expect(err).To(BeNil())
-	shootClient, err := clientCreator.CreateClient(sctx, shootClientTestLogger, secret.ObjectMeta.Namespace, secret.ObjectMeta.Name, time.Second)
+	shootClient, err := clientCreator.CreateClient(sctx, shootClientTestLogger, secret.ObjectMeta.Namespace, secret.ObjectMeta.Name, time.Second, util.KubeConfigOverrides{})
 	g.Expect(err).ToNot(BeNil())
 	g.Expect(apierrors.IsNotFound(err)).To(BeFalse())
 	g.Expect(shootClient).To(BeNil())
@@ -103,7 +104,7 @@ func testCreateShootClient(t *testing.T, namespace string) {
 	err = sk8sClient.Create(sctx, secret)
 	g.Expect(err).To(BeNil())
 
-	shootClient, err := clientCreator.CreateClient(sctx, shootClientTestLogger, secret.ObjectMeta.Namespace, secret.ObjectMeta.Name, time.Second)
+	shootClient, err := clientCreator.CreateClient(sctx, shootClientTestLogger, secret.ObjectMeta.Namespace, secret.ObjectMeta.Name, time.Second, util.KubeConfigOverrides{})
 	g.Expect(err).To(BeNil())
 	g.Expect(shootClient).ToNot(BeNil())
 }
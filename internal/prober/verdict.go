@@ -0,0 +1,135 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// verdictState captures the verdict of the prober and when it has last been published on the Cluster resource.
+type verdictState struct {
+	// current is the verdict as computed by the last probe run.
+	current papi.Verdict
+	// published is the verdict as last published, without its UpdateTime.
+	published *papi.Verdict
+	// publishedAt is the time at which the verdict has last been published.
+	publishedAt time.Time
+}
+
+func (p *Prober) isVerdictAnnotationEnabled() bool {
+	return p.config.VerdictAnnotation != nil && p.config.VerdictAnnotation.Enabled != nil && *p.config.VerdictAnnotation.Enabled
+}
+
+// updateVerdict updates the verdict with the health of the internal and external probe as computed by the current probe run.
+func (p *Prober) updateVerdict(internalHealth, externalHealth papi.ProbeHealth) {
+	now := metav1.Now()
+	updateProbeVerdict(&p.verdict.current.Internal, internalHealth, now)
+	updateProbeVerdict(&p.verdict.current.External, externalHealth, now)
+	p.verdict.current.FailOpen = p.failOpen
+	p.verdict.current.ScaledDownSince = nil
+	if !p.scaledDownSince.IsZero() {
+		p.verdict.current.ScaledDownSince = &metav1.Time{Time: p.scaledDownSince}
+	}
+}
+
+func updateProbeVerdict(probeVerdict *papi.ProbeVerdict, health papi.ProbeHealth, now metav1.Time) {
+	if probeVerdict.Health != health || probeVerdict.LastTransitionTime.IsZero() {
+		probeVerdict.Health = health
+		probeVerdict.LastTransitionTime = now
+	}
+}
+
+// updateScaledDownResources tracks the dependent resources which are scaled down as per the given result of a scale flow run.
+// Resources which have been rolled back after a failed scale-down are no longer considered to be scaled down.
+func (p *Prober) updateScaledDownResources(result dwdScaler.Result) {
+	scaledDown := make(map[string]bool, len(p.verdict.current.ScaledDownResources))
+	for _, resource := range p.verdict.current.ScaledDownResources {
+		scaledDown[resource] = true
+	}
+	for _, resResult := range result.ResourceResults {
+		resource := formatResourceRef(resResult.Ref)
+		switch {
+		case result.Operation == scaleDownOperation && (resResult.Outcome == dwdScaler.ResourceScaled || resResult.Outcome == dwdScaler.ResourceSkippedAlreadyAtTarget):
+			scaledDown[resource] = true
+		case result.Operation == scaleUpOperation && resResult.Outcome != dwdScaler.ResourceFailed:
+			delete(scaledDown, resource)
+		}
+	}
+	for _, resResult := range result.RolledBackResources {
+		if resResult.Err == nil {
+			delete(scaledDown, formatResourceRef(resResult.Ref))
+		}
+	}
+	resources := make([]string, 0, len(scaledDown))
+	for resource := range scaledDown {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	if len(resources) == 0 {
+		resources = nil
+	}
+	p.verdict.current.ScaledDownResources = resources
+}
+
+// publishVerdict publishes the verdict as JSON in the papi.VerdictAnnotationKey annotation of the Cluster resource of the shoot.
+// The annotation is only updated if the verdict has changed and at most once per configured MinUpdateInterval, a change which
+// is held back is published by a subsequent probe run. Failures to publish are logged and retried with the next probe run.
+func (p *Prober) publishVerdict(ctx context.Context) {
+	if !p.isVerdictAnnotationEnabled() {
+		return
+	}
+	if p.verdict.published != nil && equality.Semantic.DeepEqual(*p.verdict.published, p.verdict.current) {
+		return
+	}
+	if time.Since(p.verdict.publishedAt) < p.config.VerdictAnnotation.MinUpdateInterval.Duration {
+		return
+	}
+	verdict := p.verdict.current
+	verdict.UpdateTime = metav1.Now()
+	verdictBytes, err := json.Marshal(verdict)
+	if err != nil {
+		p.l.Error(err, "Failed to marshal prober verdict")
+		return
+	}
+	patchBytes, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{papi.VerdictAnnotationKey: string(verdictBytes)},
+		},
+	})
+	if err != nil {
+		p.l.Error(err, "Failed to create patch for prober verdict")
+		return
+	}
+	cluster := &extensionsv1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: p.namespace}}
+	if err = p.client.Patch(ctx, cluster, client.RawPatch(types.MergePatchType, patchBytes)); err != nil {
+		p.l.Error(err, "Failed to publish prober verdict on cluster, will be re-attempted with the next probe run")
+		return
+	}
+	verdict.UpdateTime = metav1.Time{}
+	p.verdict.published = &verdict
+	p.verdict.publishedAt = time.Now()
+	p.l.V(1).Info("Published prober verdict on cluster", "verdict", string(verdictBytes))
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/gardener/dependency-watchdog/internal/test"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const verdictTestNamespace = "shoot--test--verdict"
+
+func TestUpdateVerdictOnlyChangesLastTransitionTimeOnHealthChange(t *testing.T) {
+	g := NewWithT(t)
+	p, _ := createVerdictTestProber(g, time.Minute)
+
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthUnknown)
+	internalTransition := p.verdict.current.Internal.LastTransitionTime
+	externalTransition := p.verdict.current.External.LastTransitionTime
+	g.Expect(internalTransition.IsZero()).To(BeFalse())
+
+	p.verdict.current.Internal.LastTransitionTime.Time = internalTransition.Add(-time.Minute)
+	p.verdict.current.External.LastTransitionTime.Time = externalTransition.Add(-time.Minute)
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy)
+	g.Expect(p.verdict.current.Internal.Health).To(Equal(papi.ProbeHealthHealthy))
+	g.Expect(p.verdict.current.Internal.LastTransitionTime.Time).To(Equal(internalTransition.Add(-time.Minute)))
+	g.Expect(p.verdict.current.External.Health).To(Equal(papi.ProbeHealthUnhealthy))
+	g.Expect(p.verdict.current.External.LastTransitionTime.After(externalTransition.Add(-time.Minute))).To(BeTrue())
+}
+
+func TestUpdateScaledDownResources(t *testing.T) {
+	g := NewWithT(t)
+	p, _ := createVerdictTestProber(g, time.Minute)
+
+	p.updateScaledDownResources(dwdScaler.Result{Operation: scaleDownOperation, ResourceResults: []dwdScaler.ResourceResult{
+		{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled}, {Ref: kcmRef, Outcome: dwdScaler.ResourceSkippedAlreadyAtTarget}}})
+	g.Expect(p.verdict.current.ScaledDownResources).To(Equal([]string{"Deployment/kube-controller-manager", "Deployment/machine-controller-manager"}))
+
+	p.updateScaledDownResources(dwdScaler.Result{Operation: scaleUpOperation, ResourceResults: []dwdScaler.ResourceResult{
+		{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled}, {Ref: kcmRef, Outcome: dwdScaler.ResourceFailed, Err: errors.New("timed out")}}})
+	g.Expect(p.verdict.current.ScaledDownResources).To(Equal([]string{"Deployment/kube-controller-manager"}))
+
+	p.updateScaledDownResources(dwdScaler.Result{Operation: scaleDownOperation, Err: errors.New("timed out"),
+		ResourceResults:     []dwdScaler.ResourceResult{{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled}},
+		RolledBackResources: []dwdScaler.ResourceResult{{Ref: mcmRef}, {Ref: kcmRef}}})
+	g.Expect(p.verdict.current.ScaledDownResources).To(BeNil())
+}
+
+func TestPublishVerdictIsRateLimited(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	p, c := createVerdictTestProber(g, time.Hour)
+
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthUnhealthy)
+	p.updateScaledDownResources(dwdScaler.Result{Operation: scaleDownOperation, ResourceResults: []dwdScaler.ResourceResult{{Ref: mcmRef, Outcome: dwdScaler.ResourceScaled}}})
+	p.publishVerdict(ctx)
+	verdict := getPublishedVerdict(g, c)
+	g.Expect(verdict.Internal.Health).To(Equal(papi.ProbeHealthHealthy))
+	g.Expect(verdict.External.Health).To(Equal(papi.ProbeHealthUnhealthy))
+	g.Expect(verdict.ScaledDownResources).To(ConsistOf("Deployment/machine-controller-manager"))
+	g.Expect(verdict.UpdateTime.IsZero()).To(BeFalse())
+
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthHealthy)
+	p.publishVerdict(ctx)
+	g.Expect(getPublishedVerdict(g, c).External.Health).To(Equal(papi.ProbeHealthUnhealthy), "verdict should not be published before the min update interval has elapsed")
+
+	p.verdict.publishedAt = time.Now().Add(-time.Hour)
+	p.publishVerdict(ctx)
+	g.Expect(getPublishedVerdict(g, c).External.Health).To(Equal(papi.ProbeHealthHealthy), "held back verdict should be published once the min update interval has elapsed")
+
+	publishedAt := time.Now().Add(-time.Hour)
+	p.verdict.publishedAt = publishedAt
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthHealthy)
+	p.publishVerdict(ctx)
+	g.Expect(p.verdict.publishedAt).To(Equal(publishedAt), "unchanged verdict should not be published")
+}
+
+func TestPublishVerdictWhenDisabled(t *testing.T) {
+	g := NewWithT(t)
+	p, c := createVerdictTestProber(g, time.Minute)
+	p.config.VerdictAnnotation.Enabled = pointer.Bool(false)
+
+	p.updateVerdict(papi.ProbeHealthHealthy, papi.ProbeHealthHealthy)
+	p.publishVerdict(context.Background())
+	cluster := &extensionsv1alpha1.Cluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: verdictTestNamespace}, cluster)).To(Succeed())
+	g.Expect(cluster.Annotations).ToNot(HaveKey(papi.VerdictAnnotationKey))
+}
+
+func getPublishedVerdict(g *WithT, c client.Client) papi.Verdict {
+	cluster := &extensionsv1alpha1.Cluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: verdictTestNamespace}, cluster)).To(Succeed())
+	g.Expect(cluster.Annotations).To(HaveKey(papi.VerdictAnnotationKey))
+	var verdict papi.Verdict
+	g.Expect(json.Unmarshal([]byte(cluster.Annotations[papi.VerdictAnnotationKey]), &verdict)).To(Succeed())
+	return verdict
+}
+
+func createVerdictTestProber(g *WithT, minUpdateInterval time.Duration) (*Prober, client.Client) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(extensionsv1alpha1.AddToScheme(scheme))
+	cluster, _, err := test.CreateClusterResource(1, true)
+	g.Expect(err).To(BeNil())
+	cluster.Name = verdictTestNamespace
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	config := &papi.Config{
+		VerdictAnnotation: &papi.VerdictAnnotation{
+			Enabled:           pointer.Bool(true),
+			MinUpdateInterval: &metav1.Duration{Duration: minUpdateInterval},
+		},
+	}
+	return NewProber(context.Background(), verdictTestNamespace, config, c, nil, nil, nil, nil, proberTestLogger), c
+}
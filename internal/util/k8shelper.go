@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -63,9 +64,18 @@ func GetKubeConfigFromSecret(ctx context.Context, namespace, secretName string,
 	return kubeConfig, nil
 }
 
+// KubeConfigOverrides captures values which override those of a KubeConfig when creating a client from it.
+type KubeConfigOverrides struct {
+	// ServerURL overrides the URL of the Kube ApiServer. The TLS server name is retained so that the serving certificate
+	// of the Kube ApiServer is still verified against the host of the KubeConfig.
+	ServerURL string
+	// ProxyURL is the URL of an HTTP CONNECT (http, https) or SOCKS (socks5, socks5h) proxy via which the Kube ApiServer is reached.
+	ProxyURL string
+}
+
 // CreateClientFromKubeConfigBytes creates a client to connect to the Kube ApiServer using the kubeConfigBytes passed as a parameter
-// It will also set a connection timeout and will disable KeepAlive.
-func CreateClientFromKubeConfigBytes(kubeConfigBytes []byte, connectionTimeout time.Duration) (kubernetes.Interface, error) {
+// It will also set a connection timeout, apply the overrides and will disable KeepAlive.
+func CreateClientFromKubeConfigBytes(kubeConfigBytes []byte, connectionTimeout time.Duration, overrides KubeConfigOverrides) (kubernetes.Interface, error) {
 	clientConfig, err := clientcmd.NewClientConfigFromBytes(kubeConfigBytes)
 	if err != nil {
 		return nil, err
@@ -75,6 +85,9 @@ func CreateClientFromKubeConfigBytes(kubeConfigBytes []byte, connectionTimeout t
 		return nil, err
 	}
 	config.Timeout = connectionTimeout
+	if err = applyKubeConfigOverrides(config, overrides); err != nil {
+		return nil, err
+	}
 	transport, err := createTransportWithDisabledKeepAlive(config)
 	if err != nil {
 		return nil, err
@@ -85,6 +98,28 @@ func CreateClientFromKubeConfigBytes(kubeConfigBytes []byte, connectionTimeout t
 	return kubernetes.NewForConfig(config)
 }
 
+func applyKubeConfigOverrides(config *rest.Config, overrides KubeConfigOverrides) error {
+	if overrides.ProxyURL != "" {
+		proxyURL, err := url.Parse(overrides.ProxyURL)
+		if err != nil {
+			return err
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
+	}
+	if overrides.ServerURL == "" {
+		return nil
+	}
+	if config.TLSClientConfig.ServerName == "" {
+		hostURL, err := url.Parse(config.Host)
+		if err != nil {
+			return err
+		}
+		config.TLSClientConfig.ServerName = hostURL.Hostname()
+	}
+	config.Host = overrides.ServerURL
+	return nil
+}
+
 // Client created for probing the Kube ApiServer needs to have 'KeepAlive` disabled to ensure
 // that the broken TCP connections are not kept alive for longer duration resulting in unwanted
 // scale down of critical control plane components.
@@ -101,6 +136,10 @@ func createTransportWithDisabledKeepAlive(config *rest.Config) (*http.Transport,
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.DisableKeepAlives = true
 	transport.TLSClientConfig = tlsConfig
+	if config.Proxy != nil {
+		transport.Proxy = config.Proxy
+		transport.OnProxyConnectResponse = checkProxyConnectResponse
+	}
 	return transport, nil
 }
 
@@ -175,21 +214,29 @@ func PatchResourceAnnotations(ctx context.Context, cl client.Client, namespace s
 	return cl.Patch(ctx, partialObjMeta, client.RawPatch(types.MergePatchType, patchBytes))
 }
 
-// GetResourceReadyReplicas gets spec.replicas for any resource identified via resourceRef withing the given namespace.
-// It is an error if there is no spec.replicas or if there is an error fetching the resource.
-func GetResourceReadyReplicas(ctx context.Context, cli client.Client, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (int32, error) {
-	resObj := unstructured.Unstructured{}
+// GetResource gets any resource identified via resourceRef within the given namespace as unstructured.Unstructured.
+func GetResource(ctx context.Context, cli client.Client, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (*unstructured.Unstructured, error) {
+	resObj := &unstructured.Unstructured{}
 
 	groupVersion, err := schema.ParseGroupVersion(resourceRef.APIVersion)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	resObj.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   groupVersion.Group,
 		Version: groupVersion.Version,
 		Kind:    resourceRef.Kind,
 	})
-	err = cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: resourceRef.Name}, &resObj)
+	if err = cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: resourceRef.Name}, resObj); err != nil {
+		return nil, err
+	}
+	return resObj, nil
+}
+
+// GetResourceReadyReplicas gets spec.replicas for any resource identified via resourceRef withing the given namespace.
+// It is an error if there is no spec.replicas or if there is an error fetching the resource.
+func GetResourceReadyReplicas(ctx context.Context, cli client.Client, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (int32, error) {
+	resObj, err := GetResource(ctx, cli, namespace, resourceRef)
 	if err != nil {
 		return 0, err
 	}
@@ -85,6 +85,7 @@ func TestSuitForK8sHelper(t *testing.T) {
 		{"extract KubeConfig from secret", testExtractKubeConfigFromSecret},
 		{"secret with no KubeConfig", testExtractKubeConfigFromSecretWithNoKubeConfig},
 		{"create client from KubeConfig", testCreateClientFromKubeConfigBytes},
+		{"apply KubeConfig overrides", testApplyKubeConfigOverrides},
 		{"create transport with keep-alive disabled", testCreateTransportWithDisabledKeepAlive},
 		{"create scales getter", testCreateScalesGetter},
 		{"get scale resource", testGetScaleResource},
@@ -145,9 +146,26 @@ func testCreateClientFromKubeConfigBytes(t *testing.T) {
 	g := NewWithT(t)
 	kubeConfigBytes := getKubeConfigBytes(g, kubeConfigPath)
 
-	cfg, err := CreateClientFromKubeConfigBytes(kubeConfigBytes, time.Second)
+	cfg, err := CreateClientFromKubeConfigBytes(kubeConfigBytes, time.Second, KubeConfigOverrides{})
 	g.Expect(err).Should(BeNil())
 	g.Expect(cfg).ShouldNot(BeNil())
+
+	cfg, err = CreateClientFromKubeConfigBytes(kubeConfigBytes, time.Second, KubeConfigOverrides{ServerURL: "https://10.0.0.1"})
+	g.Expect(err).Should(BeNil())
+	g.Expect(cfg).ShouldNot(BeNil())
+}
+
+func testApplyKubeConfigOverrides(t *testing.T) {
+	g := NewWithT(t)
+	config := &rest.Config{Host: "https://api.shoot.example.com:443"}
+
+	g.Expect(applyKubeConfigOverrides(config, KubeConfigOverrides{})).To(Succeed())
+	g.Expect(config.Host).To(Equal("https://api.shoot.example.com:443"))
+	g.Expect(config.TLSClientConfig.ServerName).To(BeEmpty())
+
+	g.Expect(applyKubeConfigOverrides(config, KubeConfigOverrides{ServerURL: "https://10.0.0.1"})).To(Succeed())
+	g.Expect(config.Host).To(Equal("https://10.0.0.1"))
+	g.Expect(config.TLSClientConfig.ServerName).To(Equal("api.shoot.example.com"))
 }
 
 func testCreateTransportWithDisabledKeepAlive(t *testing.T) {
@@ -0,0 +1,64 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyError is returned if a request could not be sent as the proxy via which it is sent has refused to connect to the target.
+type ProxyError struct {
+	// ProxyURL is the URL of the proxy.
+	ProxyURL string
+	// Err is the error returned by the proxy.
+	Err error
+}
+
+func (e *ProxyError) Error() string {
+	return fmt.Sprintf("proxy %s: %v", e.ProxyURL, e.Err)
+}
+
+func (e *ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// IsProxyError checks if the error has been caused by the proxy via which a request is sent rather than by the target
+// of the request, i.e. if the proxy could not be reached or has refused to connect to the target.
+func IsProxyError(err error) bool {
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		// net/http reports failures to connect to an HTTP CONNECT proxy as proxyconnect and failures of a SOCKS proxy as socks <command>
+		return opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks")
+	}
+	return false
+}
+
+// checkProxyConnectResponse turns a response other than 200 of an HTTP CONNECT proxy into a ProxyError.
+func checkProxyConnectResponse(_ context.Context, proxyURL *url.URL, _ *http.Request, resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	return &ProxyError{ProxyURL: proxyURL.Redacted(), Err: errors.New(resp.Status)}
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package util
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+func TestRequestIsSentViaProxy(t *testing.T) {
+	g := NewWithT(t)
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	proxy, connectCount := newConnectProxy(t, true)
+	defer proxy.Close()
+
+	client := createProxyTestClient(g, target.URL, proxy.URL)
+	resp, err := client.Get(target.URL)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	g.Expect(*connectCount).To(Equal(1))
+}
+
+func TestProxyErrorsAreClassifiedSeparately(t *testing.T) {
+	g := NewWithT(t)
+	refusingProxy, _ := newConnectProxy(t, false)
+	defer refusingProxy.Close()
+	unreachableProxy := httptest.NewServer(http.NotFoundHandler())
+	unreachableProxy.Close()
+
+	for _, proxyURL := range []string{refusingProxy.URL, unreachableProxy.URL, "socks5://" + unreachableProxy.Listener.Addr().String()} {
+		client := createProxyTestClient(g, "https://api.shoot.example.com", proxyURL)
+		_, err := client.Get("https://api.shoot.example.com")
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(IsProxyError(err)).To(BeTrue(), "proxy: %s, err: %v", proxyURL, err)
+	}
+
+	target := httptest.NewServer(http.NotFoundHandler())
+	target.Close()
+	client := createProxyTestClient(g, target.URL, "")
+	_, err := client.Get(target.URL)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(IsProxyError(err)).To(BeFalse())
+}
+
+func createProxyTestClient(g *WithT, host string, proxyURL string) *http.Client {
+	config := &rest.Config{Host: host, TLSClientConfig: rest.TLSClientConfig{Insecure: true}}
+	g.Expect(applyKubeConfigOverrides(config, KubeConfigOverrides{ProxyURL: proxyURL})).To(Succeed())
+	transport, err := createTransportWithDisabledKeepAlive(config)
+	g.Expect(err).ToNot(HaveOccurred())
+	return &http.Client{Transport: transport}
+}
+
+// newConnectProxy creates a stand-in for an HTTP CONNECT proxy which either tunnels connections to the requested target or refuses them.
+func newConnectProxy(t *testing.T, allow bool) (*httptest.Server, *int) {
+	connectCount := new(int)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || !allow {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		*connectCount++
+		targetConn, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer targetConn.Close()
+		w.WriteHeader(http.StatusOK)
+		clientConn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack proxy connection: %v", err)
+			return
+		}
+		defer clientConn.Close()
+		go func() { _, _ = io.Copy(targetConn, clientConn) }()
+		_, _ = io.Copy(clientConn, targetConn)
+	}))
+	return proxy, connectCount
+}
@@ -27,6 +27,31 @@ type RetryResult[T any] struct {
 	Err   error
 }
 
+// BackOffFn returns the duration to back off after the given failed attempt. Attempts start at 1.
+type BackOffFn func(attempt int) time.Duration
+
+// ConstantBackOff returns a BackOffFn which always backs off for the given duration.
+func ConstantBackOff(backOff time.Duration) BackOffFn {
+	return func(_ int) time.Duration {
+		return backOff
+	}
+}
+
+// ExponentialBackOff returns a BackOffFn which backs off for `initialBackOff` after the first failed attempt and doubles
+// the back off after every subsequent failed attempt, never exceeding `maxBackOff`.
+func ExponentialBackOff(initialBackOff time.Duration, maxBackOff time.Duration) BackOffFn {
+	return func(attempt int) time.Duration {
+		backOff := initialBackOff
+		for i := 1; i < attempt && backOff < maxBackOff; i++ {
+			backOff *= 2
+		}
+		if backOff > maxBackOff {
+			return maxBackOff
+		}
+		return backOff
+	}
+}
+
 // Retry retries an operation `fn`, `numAttempts` number of times with a given `backOff` until one of the conditions is met:
 // 1. Invocation of `fn` succeeds.
 // 2. `canRetry` returns false.
@@ -34,6 +59,11 @@ type RetryResult[T any] struct {
 // 4. `ctx` (context) has either been cancelled or it has expired.
 // The result is captured eventually in `RetryResult`.
 func Retry[T any](ctx context.Context, logger logr.Logger, operation string, fn func() (T, error), numAttempts int, backOff time.Duration, canRetry func(error) bool) RetryResult[T] {
+	return RetryWithBackOff(ctx, logger, operation, fn, numAttempts, ConstantBackOff(backOff), canRetry)
+}
+
+// RetryWithBackOff is similar to Retry but the duration to back off after each failed attempt is determined by `backOffFn`.
+func RetryWithBackOff[T any](ctx context.Context, logger logr.Logger, operation string, fn func() (T, error), numAttempts int, backOffFn BackOffFn, canRetry func(error) bool) RetryResult[T] {
 	var result T
 	var err error
 	for i := 1; i <= numAttempts; i++ {
@@ -55,7 +85,7 @@ func Retry[T any](ctx context.Context, logger logr.Logger, operation string, fn
 		case <-ctx.Done():
 			logger.Error(ctx.Err(), "Context has been cancelled, stopping retry", "operation", operation)
 			return RetryResult[T]{Err: ctx.Err()}
-		case <-time.After(backOff):
+		case <-time.After(backOffFn(i)):
 			logger.Info("Will attempt to retry operation", "operation", operation, "currentAttempt", i, "error", err)
 		}
 	}
@@ -210,3 +210,35 @@ func passEventually() func() (string, error) {
 func emptyList() {
 	list = nil
 }
+
+func TestRetryWithBackOffUsesBackOffFnForEachFailedAttempt(t *testing.T) {
+	g := NewWithT(t)
+	var attempts []int
+	backOffFn := func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return time.Millisecond
+	}
+	result := RetryWithBackOff(context.Background(), retryTestLogger, "", appendFail, numAttempts, backOffFn, AlwaysRetry)
+	g.Expect(result.Err.Error()).Should(Equal("appendFail"))
+	g.Expect(attempts).Should(Equal([]int{1, 2, 3}))
+	emptyList()
+}
+
+func TestExponentialBackOff(t *testing.T) {
+	g := NewWithT(t)
+	backOffFn := ExponentialBackOff(100*time.Millisecond, time.Second)
+	table := []struct {
+		attempt         int
+		expectedBackOff time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+	for _, entry := range table {
+		g.Expect(backOffFn(entry.attempt)).To(Equal(entry.expectedBackOff), fmt.Sprintf("unexpected back off for attempt %d", entry.attempt))
+	}
+}
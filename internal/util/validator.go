@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	multierr "github.com/hashicorp/go-multierror"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
@@ -69,6 +70,35 @@ func (v *Validator) MustNotBeNil(key string, value interface{}) bool {
 	return true
 }
 
+// MustBeOneOf checks whether the given value is one of the allowedValues and returns false if it is not.
+func (v *Validator) MustBeOneOf(key string, value string, allowedValues ...string) bool {
+	for _, allowedValue := range allowedValues {
+		if value == allowedValue {
+			return true
+		}
+	}
+	v.Error = multierr.Append(v.Error, fmt.Errorf("value %q for key %s is not supported, it must be one of %v", value, key, allowedValues))
+	return false
+}
+
+// MustBePositive checks whether the given value is greater than zero and returns false if it is not.
+func (v *Validator) MustBePositive(key string, value int) bool {
+	if value <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("value %d for key %s must be greater than zero", value, key))
+		return false
+	}
+	return true
+}
+
+// MustBePositiveDuration checks whether the given duration is greater than zero and returns false if it is not.
+func (v *Validator) MustBePositiveDuration(key string, value time.Duration) bool {
+	if value <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("duration %s for key %s must be greater than zero", value, key))
+		return false
+	}
+	return true
+}
+
 // ResourceRefMustBeValid validates the given resourceRef by parsing the apiVersion.
 func (v *Validator) ResourceRefMustBeValid(resourceRef *autoscalingv1.CrossVersionObjectReference, scheme *runtime.Scheme) bool {
 	gv, err := schema.ParseGroupVersion(resourceRef.APIVersion)
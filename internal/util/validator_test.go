@@ -18,6 +18,7 @@ package util
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
@@ -76,6 +77,75 @@ func TestMustNotBeNil(t *testing.T) {
 	}
 }
 
+func TestMustBeOneOf(t *testing.T) {
+	g := NewWithT(t)
+	tests := []struct {
+		key           string
+		value         string
+		allowedValues []string
+		result        bool
+	}{
+		{"k1", "Retry", []string{"Retry", "Rollback"}, true},
+		{"k2", "Rollback", []string{"Retry", "Rollback"}, true},
+		{"k3", "retry", []string{"Retry", "Rollback"}, false},
+		{"k4", "", []string{"Retry", "Rollback"}, false},
+		{"k5", "Retry", nil, false},
+	}
+
+	for _, entry := range tests {
+		v := Validator{}
+		actualResult := v.MustBeOneOf(entry.key, entry.value, entry.allowedValues...)
+		g.Expect(entry.result).To(Equal(actualResult))
+		if !actualResult {
+			g.Expect(v.Error).ToNot(BeNil())
+		}
+	}
+}
+
+func TestMustBePositive(t *testing.T) {
+	g := NewWithT(t)
+	tests := []struct {
+		key    string
+		value  int
+		result bool
+	}{
+		{"k1", 1, true},
+		{"k2", 0, false},
+		{"k3", -1, false},
+	}
+
+	for _, entry := range tests {
+		v := Validator{}
+		actualResult := v.MustBePositive(entry.key, entry.value)
+		g.Expect(entry.result).To(Equal(actualResult))
+		if !actualResult {
+			g.Expect(v.Error).ToNot(BeNil())
+		}
+	}
+}
+
+func TestMustBePositiveDuration(t *testing.T) {
+	g := NewWithT(t)
+	tests := []struct {
+		key    string
+		value  time.Duration
+		result bool
+	}{
+		{"k1", time.Second, true},
+		{"k2", 0, false},
+		{"k3", -time.Second, false},
+	}
+
+	for _, entry := range tests {
+		v := Validator{}
+		actualResult := v.MustBePositiveDuration(entry.key, entry.value)
+		g.Expect(entry.result).To(Equal(actualResult))
+		if !actualResult {
+			g.Expect(v.Error).ToNot(BeNil())
+		}
+	}
+}
+
 func TestResourceRefMustBeValid(t *testing.T) {
 	g := NewWithT(t)
 
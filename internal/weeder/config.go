@@ -15,19 +15,37 @@
 package weeder
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	"github.com/gardener/dependency-watchdog/internal/util"
 
 	multierr "github.com/hashicorp/go-multierror"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 const (
 	// defaultWatchDuration is the default duration after which the watch expires.
 	defaultWatchDuration = 5 * time.Minute
+	// defaultUseEndpointSlices is the default value of whether EndpointSlices are watched instead of Endpoints.
+	defaultUseEndpointSlices = false
+	// defaultWaitingReason is the default waiting reason of a container which triggers the deletion of its pod.
+	defaultWaitingReason = crashLoopBackOff
+	// defaultWeedingStrategy is the default strategy with which dependant pods are weeded out.
+	defaultWeedingStrategy = wapi.WeedingStrategyDeletePod
+	// defaultPodDeletionMode is the default mode with which pods are removed.
+	defaultPodDeletionMode = wapi.PodDeletionModeDelete
+	// defaultEvictionRetryInterval is the default interval with which an eviction that has been rejected with 429 is retried.
+	defaultEvictionRetryInterval = 5 * time.Second
+	// defaultAddressChangeDebounceWindow is the default duration for which the ready addresses of a service have to stay
+	// unchanged before a weeder is started for an address change.
+	defaultAddressChangeDebounceWindow = 30 * time.Second
 )
 
 // LoadConfig reads the weeder configuration from a file, unmarshalls it, fills in the default values and
@@ -58,7 +76,25 @@ func validate(c *wapi.Config) error {
 				continue
 			}
 		}
+		validateTriggerConditions(v, ds.TriggerConditions)
+		v.MustBeOneOf("strategy", string(*ds.Strategy), string(wapi.WeedingStrategyDeletePod), string(wapi.WeedingStrategyRolloutRestart))
+		if ds.WatchDuration != nil {
+			v.MustBePositiveDuration("watchDuration", ds.WatchDuration.Duration)
+		}
+		if ds.Limits != nil {
+			validateWeedingLimits(v, ds.Limits)
+		}
+		if ds.AddressChangeTrigger != nil {
+			v.MustBePositiveDuration("addressChangeTrigger.debounceWindow", ds.AddressChangeTrigger.DebounceWindow.Duration)
+		}
+	}
+	if c.Notifier != nil {
+		notifier.Validate(v, "notifier", c.Notifier)
+	}
+	if c.Audit != nil {
+		audit.Validate(v, "audit", c.Audit)
 	}
+	validatePodDeletion(v, c.PodDeletion)
 	return v.Error
 }
 
@@ -68,4 +104,82 @@ func fillDefaultValues(c *wapi.Config) {
 			Duration: defaultWatchDuration,
 		}
 	}
+	if c.UseEndpointSlices == nil {
+		c.UseEndpointSlices = pointer.Bool(defaultUseEndpointSlices)
+	}
+	for service, ds := range c.ServicesAndDependantSelectors {
+		if ds.TriggerConditions == nil {
+			ds.TriggerConditions = &wapi.TriggerConditions{
+				WaitingReasons: []string{defaultWaitingReason},
+			}
+		}
+		if ds.Strategy == nil {
+			ds.Strategy = new(wapi.WeedingStrategy)
+			*ds.Strategy = defaultWeedingStrategy
+		}
+		if ds.AddressChangeTrigger != nil && ds.AddressChangeTrigger.DebounceWindow == nil {
+			ds.AddressChangeTrigger.DebounceWindow = &metav1.Duration{
+				Duration: defaultAddressChangeDebounceWindow,
+			}
+		}
+		c.ServicesAndDependantSelectors[service] = ds
+	}
+	if c.Notifier != nil {
+		notifier.FillDefaultValues(c.Notifier)
+	}
+	if c.Audit != nil {
+		audit.FillDefaultValues(c.Audit)
+	}
+	if c.PodDeletion == nil {
+		c.PodDeletion = &wapi.PodDeletion{}
+	}
+	fillDefaultValuesForPodDeletion(c.PodDeletion)
+}
+
+func validateTriggerConditions(v *util.Validator, tc *wapi.TriggerConditions) {
+	if len(tc.WaitingReasons) == 0 && tc.RestartCountAbove == nil && tc.NotReadyFor == nil && len(tc.LastTerminationExitCodes) == 0 {
+		v.Error = multierr.Append(v.Error, errors.New("triggerConditions must specify at least one condition"))
+		return
+	}
+	for _, reason := range tc.WaitingReasons {
+		v.MustNotBeEmpty("triggerConditions.waitingReasons", reason)
+	}
+	if tc.RestartCountAbove != nil && *tc.RestartCountAbove < 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("value %d for key triggerConditions.restartCountAbove must not be negative", *tc.RestartCountAbove))
+	}
+	if tc.NotReadyFor != nil {
+		v.MustBePositiveDuration("triggerConditions.notReadyFor", tc.NotReadyFor.Duration)
+	}
+}
+
+func validateWeedingLimits(v *util.Validator, l *wapi.WeedingLimits) {
+	if l.MaxDeletionsPerPod != nil {
+		v.MustBePositive("limits.maxDeletionsPerPod", *l.MaxDeletionsPerPod)
+	}
+	if l.MaxDeletionsPerSelector != nil {
+		v.MustBePositive("limits.maxDeletionsPerSelector", *l.MaxDeletionsPerSelector)
+	}
+	if l.MaxDeletionsPerWeeder != nil {
+		v.MustBePositive("limits.maxDeletionsPerWeeder", *l.MaxDeletionsPerWeeder)
+	}
+}
+
+func validatePodDeletion(v *util.Validator, pd *wapi.PodDeletion) {
+	v.MustBeOneOf("podDeletion.mode", string(*pd.Mode), string(wapi.PodDeletionModeDelete), string(wapi.PodDeletionModeEvict))
+	v.MustBePositiveDuration("podDeletion.evictionRetryInterval", pd.EvictionRetryInterval.Duration)
+	if pd.MaxConcurrentPerOwner != nil {
+		v.MustBePositive("podDeletion.maxConcurrentPerOwner", *pd.MaxConcurrentPerOwner)
+	}
+}
+
+func fillDefaultValuesForPodDeletion(pd *wapi.PodDeletion) {
+	if pd.Mode == nil {
+		pd.Mode = new(wapi.PodDeletionMode)
+		*pd.Mode = defaultPodDeletionMode
+	}
+	if pd.EvictionRetryInterval == nil {
+		pd.EvictionRetryInterval = &metav1.Duration{
+			Duration: defaultEvictionRetryInterval,
+		}
+	}
 }
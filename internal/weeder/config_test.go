@@ -19,10 +19,14 @@ package weeder
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
 	multierr "github.com/hashicorp/go-multierror"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	. "github.com/onsi/gomega"
 )
@@ -48,6 +52,15 @@ func TestCheckIfDefaultValuesAreSetForAllOptionalMissingValues(t *testing.T) {
 	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give any error for a valid config file")
 	g.Expect(config).ToNot(BeNil(), "LoadConfig should not return nil for a valid config file")
 	g.Expect(*config.WatchDuration).To(Equal(metav1.Duration{Duration: defaultWatchDuration}), "LoadConfig should set watchDuration to defaultWatchDuration if not set in the config file")
+	g.Expect(*config.UseEndpointSlices).To(Equal(defaultUseEndpointSlices), "LoadConfig should set useEndpointSlices to defaultUseEndpointSlices if not set in the config file")
+	for _, ds := range config.ServicesAndDependantSelectors {
+		g.Expect(ds.TriggerConditions).To(Equal(&wapi.TriggerConditions{WaitingReasons: []string{defaultWaitingReason}}), "LoadConfig should set triggerConditions to defaultWaitingReason if not set in the config file")
+		g.Expect(*ds.Strategy).To(Equal(defaultWeedingStrategy), "LoadConfig should set strategy to defaultWeedingStrategy if not set in the config file")
+		g.Expect(ds.AddressChangeTrigger).To(BeNil(), "LoadConfig should not opt in to address change triggers if not set in the config file")
+	}
+	g.Expect(*config.PodDeletion.Mode).To(Equal(defaultPodDeletionMode), "LoadConfig should set podDeletion.mode to defaultPodDeletionMode if not set in the config file")
+	g.Expect(*config.PodDeletion.EvictionRetryInterval).To(Equal(metav1.Duration{Duration: defaultEvictionRetryInterval}), "LoadConfig should set podDeletion.evictionRetryInterval to defaultEvictionRetryInterval if not set in the config file")
+	g.Expect(config.PodDeletion.MaxConcurrentPerOwner).To(BeNil(), "LoadConfig should not limit the number of pods removed at the same time if not set in the config file")
 	t.Log("All default values are set")
 }
 
@@ -58,6 +71,13 @@ func TestMissingMandatoryFieldsShouldReturnErrorAndNilConfig(t *testing.T) {
 	}{
 		{"config_missing_mandatory_values.yaml", 1},
 		{"config_missing_pod_selectors.yaml", 1},
+		{"config_invalid_notifier.yaml", 3},
+		{"config_invalid_audit.yaml", 1},
+		{"config_invalid_pod_deletion.yaml", 3},
+		{"config_invalid_trigger_conditions.yaml", 4},
+		{"config_invalid_strategy.yaml", 1},
+		{"config_invalid_limits.yaml", 4},
+		{"config_invalid_address_change_trigger.yaml", 1},
 	}
 
 	for _, entry := range table {
@@ -86,6 +106,27 @@ func TestValidConfigShouldPassAllValidations(t *testing.T) {
 	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
 	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
 	g.Expect(len(config.ServicesAndDependantSelectors)).To(Equal(2), "LoadConfig did not load all the dependent resources")
+	g.Expect(config.Notifier).ToNot(BeNil(), "LoadConfig did not load the notifier")
+	g.Expect(config.Notifier.Webhooks).To(HaveLen(1), "LoadConfig did not load all the webhooks")
+	g.Expect(*config.Notifier.MaxAttempts).To(Equal(notifier.DefaultMaxAttempts), "LoadConfig should set the default values of the notifier")
+	g.Expect(*config.UseEndpointSlices).To(BeTrue(), "LoadConfig did not load useEndpointSlices")
+	g.Expect(config.ServicesAndDependantSelectors["etcd-main-client"].TriggerConditions).To(Equal(&wapi.TriggerConditions{
+		WaitingReasons:           []string{"CrashLoopBackOff", "Error"},
+		RestartCountAbove:        pointer.Int32(3),
+		NotReadyFor:              &metav1.Duration{Duration: 2 * time.Minute},
+		LastTerminationExitCodes: []int32{1},
+	}), "LoadConfig did not load triggerConditions")
+	g.Expect(*config.ServicesAndDependantSelectors["kube-apiserver"].Strategy).To(Equal(wapi.WeedingStrategyRolloutRestart), "LoadConfig did not load strategy")
+	g.Expect(*config.ServicesAndDependantSelectors["etcd-main-client"].WatchDuration).To(Equal(metav1.Duration{Duration: 10 * time.Minute}), "LoadConfig did not load the watchDuration of the service")
+	g.Expect(config.ServicesAndDependantSelectors["kube-apiserver"].WatchDuration).To(BeNil(), "LoadConfig should not set the watchDuration of a service if not set in the config file")
+	g.Expect(config.ServicesAndDependantSelectors["etcd-main-client"].Limits).To(Equal(&wapi.WeedingLimits{
+		MaxDeletionsPerPod:    pointer.Int(3),
+		MaxDeletionsPerWeeder: pointer.Int(10),
+	}), "LoadConfig did not load limits")
+	g.Expect(*config.ServicesAndDependantSelectors["etcd-main-client"].AddressChangeTrigger.DebounceWindow).To(Equal(metav1.Duration{Duration: defaultAddressChangeDebounceWindow}), "LoadConfig should set addressChangeTrigger.debounceWindow to defaultAddressChangeDebounceWindow if not set in the config file")
+	g.Expect(*config.ServicesAndDependantSelectors["kube-apiserver"].AddressChangeTrigger.DebounceWindow).To(Equal(metav1.Duration{Duration: time.Minute}), "LoadConfig did not load addressChangeTrigger.debounceWindow")
+	g.Expect(*config.PodDeletion.Mode).To(Equal(wapi.PodDeletionModeEvict), "LoadConfig did not load podDeletion.mode")
+	g.Expect(*config.PodDeletion.MaxConcurrentPerOwner).To(Equal(1), "LoadConfig did not load podDeletion.maxConcurrentPerOwner")
 
 	t.Log("Valid config is loaded correctly")
 }
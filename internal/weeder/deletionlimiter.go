@@ -0,0 +1,95 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"fmt"
+	"sync"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deletionLimiter counts the pods deleted by a weeder and stops further deletions once a wapi.WeedingLimits has been reached.
+type deletionLimiter struct {
+	maxPerPod      int
+	maxPerSelector int
+	maxPerWeeder   int
+	mu             sync.Mutex
+	perPod         map[types.NamespacedName]int
+	perSelector    map[string]int
+	total          int
+	// reported are the limits which have already been reported, so that every limit is only reported once.
+	reported map[string]struct{}
+}
+
+func newDeletionLimiter(l *wapi.WeedingLimits) *deletionLimiter {
+	dl := &deletionLimiter{
+		perPod:      make(map[types.NamespacedName]int),
+		perSelector: make(map[string]int),
+		reported:    make(map[string]struct{}),
+	}
+	if l == nil {
+		return dl
+	}
+	if l.MaxDeletionsPerPod != nil {
+		dl.maxPerPod = *l.MaxDeletionsPerPod
+	}
+	if l.MaxDeletionsPerSelector != nil {
+		dl.maxPerSelector = *l.MaxDeletionsPerSelector
+	}
+	if l.MaxDeletionsPerWeeder != nil {
+		dl.maxPerWeeder = *l.MaxDeletionsPerWeeder
+	}
+	return dl
+}
+
+// acquire counts the deletion of the pod, which has been selected by the selector, unless a limit has been reached.
+// If a limit has been reached, the description of the limit is returned along with whether it is the first time the
+// limit has been hit, in which case it should be reported.
+func (l *deletionLimiter) acquire(pod *v1.Pod, selector string) (bool, string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := client.ObjectKeyFromObject(pod)
+	var limit string
+	switch {
+	case l.maxPerWeeder > 0 && l.total >= l.maxPerWeeder:
+		limit = fmt.Sprintf("maximum of %d deletions per weeder", l.maxPerWeeder)
+	case l.maxPerSelector > 0 && l.perSelector[selector] >= l.maxPerSelector:
+		limit = fmt.Sprintf("maximum of %d deletions for selector %s", l.maxPerSelector, selector)
+	case l.maxPerPod > 0 && l.perPod[key] >= l.maxPerPod:
+		limit = fmt.Sprintf("maximum of %d deletions of pod %s", l.maxPerPod, key.Name)
+	}
+	if limit != "" {
+		_, reported := l.reported[limit]
+		l.reported[limit] = struct{}{}
+		return false, limit, !reported
+	}
+	l.total++
+	l.perSelector[selector]++
+	l.perPod[key]++
+	return true, "", false
+}
+
+// release uncounts the deletion of a pod which has failed.
+func (l *deletionLimiter) release(pod *v1.Pod, selector string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total--
+	l.perSelector[selector]--
+	l.perPod[client.ObjectKeyFromObject(pod)]--
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"testing"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+)
+
+func TestDeletionLimiter(t *testing.T) {
+	testcases := []struct {
+		name          string
+		limits        *wapi.WeedingLimits
+		deletions     []string // pod names, all selected by selector-a unless prefixed with "b/"
+		expectedLimit string
+	}{
+		{"no limits", nil, []string{"kcm", "kcm", "mcm", "mcm"}, ""},
+		{"per pod", &wapi.WeedingLimits{MaxDeletionsPerPod: pointer.Int(2)}, []string{"kcm", "mcm", "kcm", "kcm"}, "maximum of 2 deletions of pod kcm"},
+		{"per selector", &wapi.WeedingLimits{MaxDeletionsPerSelector: pointer.Int(2)}, []string{"kcm", "b/etcd", "mcm", "b/etcd", "kcm"}, "maximum of 2 deletions for selector selector-a"},
+		{"per weeder", &wapi.WeedingLimits{MaxDeletionsPerWeeder: pointer.Int(3)}, []string{"kcm", "b/etcd", "mcm", "kcm"}, "maximum of 3 deletions per weeder"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			l := newDeletionLimiter(tc.limits)
+			for i, name := range tc.deletions {
+				selector := "selector-a"
+				if len(name) > 2 && name[:2] == "b/" {
+					selector, name = "selector-b", name[2:]
+				}
+				acquired, limit, report := l.acquire(createTestPod(name, crashLoopBackOff), selector)
+				if i < len(tc.deletions)-1 || tc.expectedLimit == "" {
+					g.Expect(acquired).To(BeTrue(), "deletion %d of pod %s should not be limited", i, name)
+					continue
+				}
+				g.Expect(acquired).To(BeFalse())
+				g.Expect(limit).To(Equal(tc.expectedLimit))
+				g.Expect(report).To(BeTrue(), "a limit should be reported the first time it is hit")
+				_, _, report = l.acquire(createTestPod(name, crashLoopBackOff), selector)
+				g.Expect(report).To(BeFalse(), "a limit should only be reported once")
+			}
+		})
+	}
+}
+
+func TestDeletionLimiterReleasesFailedDeletions(t *testing.T) {
+	g := NewWithT(t)
+	l := newDeletionLimiter(&wapi.WeedingLimits{MaxDeletionsPerWeeder: pointer.Int(1)})
+	pod := createTestPod("kcm", crashLoopBackOff)
+	acquired, _, _ := l.acquire(pod, "selector-a")
+	g.Expect(acquired).To(BeTrue())
+	l.release(pod, "selector-a")
+	acquired, _, _ = l.acquire(pod, "selector-a")
+	g.Expect(acquired).To(BeTrue(), "a failed deletion should not count against the limits")
+}
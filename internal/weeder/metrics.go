@@ -0,0 +1,91 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "dwd"
+	metricsSubsystem = "weeder"
+)
+
+var (
+	// weedersStartedTotal counts the weeders which have been started, partitioned by the service whose recovery started them.
+	weedersStartedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "weeders_started_total",
+			Help:      "Number of weeders which have been started, partitioned by service.",
+		},
+		[]string{"service"},
+	)
+	// deletedPodsTotal counts the dependant pods which have been deleted or evicted by weeders.
+	deletedPodsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "deleted_pods_total",
+			Help:      "Number of dependant pods which have been deleted or evicted by weeders, partitioned by service and pod selector.",
+		},
+		[]string{"service", "selector"},
+	)
+	// deletionErrorsTotal counts the failed attempts of weeders to delete or evict a dependant pod.
+	deletionErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "deletion_errors_total",
+			Help:      "Number of failed attempts of weeders to delete or evict a dependant pod, partitioned by service and pod selector.",
+		},
+		[]string{"service", "selector"},
+	)
+	// activeWeeders is the number of weeders which are currently running.
+	activeWeeders = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "active_weeders",
+			Help:      "Number of weeders which are currently running, partitioned by service.",
+		},
+		[]string{"service"},
+	)
+	// openWatches is the number of pod selectors for which weeders are currently watching the dependant pods.
+	openWatches = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "open_watches",
+			Help:      "Number of pod selectors for which weeders are currently watching the dependant pods, partitioned by service.",
+		},
+		[]string{"service"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(weedersStartedTotal, deletedPodsTotal, deletionErrorsTotal, activeWeeders, openWatches)
+}
+
+// recordPodDeletionMetrics records the outcome of an attempt to delete or evict a dependant pod.
+func recordPodDeletionMetrics(service string, selector string, err error) {
+	if err != nil {
+		deletionErrorsTotal.WithLabelValues(service, selector).Inc()
+		return
+	}
+	deletedPodsTotal.WithLabelValues(service, selector).Inc()
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestShootPodIfNecessaryRecordsEventsAndMetricsForDeletedPods(t *testing.T) {
+	g := NewWithT(t)
+	const service = "kube-apiserver"
+	const selector = "role=controller-manager"
+	pod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	endpoints := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: service, Namespace: namespace}}
+	crClient := fake.NewClientBuilder().WithObjects(pod, endpoints).Build()
+	recorder := record.NewFakeRecorder(10)
+	w := NewWeeder(context.Background(), namespace, testWeederConfig, crClient, nil, service, nil, nil, recorder, logr.Discard())
+	defer w.cancelFn()
+	deletedBefore := metricValue(g, deletedPodsTotal.WithLabelValues(service, selector))
+
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, selector, pod)).To(Succeed())
+	w.removals.Wait()
+
+	g.Expect(metricValue(g, deletedPodsTotal.WithLabelValues(service, selector))).To(Equal(deletedBefore + 1))
+	g.Expect(recorder.Events).To(HaveLen(2))
+	g.Expect(<-recorder.Events).To(HavePrefix("Normal " + eventReasonPodDeleted + " deleted by dependency-watchdog after service " + service + " has recovered"))
+	g.Expect(<-recorder.Events).To(HavePrefix("Normal " + eventReasonDependantPodDeleted + " deleted dependant pod kube-controller-manager"))
+}
+
+func TestRunningWeederIsReportedInGauges(t *testing.T) {
+	g := NewWithT(t)
+	const service = "etcd-events-client"
+	config := &wapi.Config{
+		WatchDuration:                 &metav1.Duration{Duration: time.Minute},
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{service: testServicesAndDependantSelectors[epName]},
+	}
+	source, err := NewPodEventSource(&fakeInformer{}, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	w := NewWeeder(context.Background(), namespace, config, fake.NewClientBuilder().Build(), source, service, nil, nil, nil, logr.Discard())
+	startedBefore := metricValue(g, weedersStartedTotal.WithLabelValues(service))
+
+	done := make(chan struct{})
+	go func() {
+		w.Run()
+		close(done)
+	}()
+	g.Eventually(func() float64 { return metricValue(g, openWatches.WithLabelValues(service)) }).Should(Equal(1.0))
+	g.Expect(metricValue(g, activeWeeders.WithLabelValues(service))).To(Equal(1.0))
+	g.Expect(metricValue(g, weedersStartedTotal.WithLabelValues(service))).To(Equal(startedBefore + 1))
+
+	w.cancelFn()
+	g.Eventually(done).Should(BeClosed())
+	g.Expect(metricValue(g, activeWeeders.WithLabelValues(service))).To(BeZero())
+	g.Eventually(func() float64 { return metricValue(g, openWatches.WithLabelValues(service)) }).Should(BeZero())
+}
+
+func metricValue(g *WithT, metric prometheus.Metric) float64 {
+	m := &dto.Metric{}
+	g.Expect(metric.Write(m)).To(Succeed())
+	if m.Counter != nil {
+		return m.Counter.GetValue()
+	}
+	return m.Gauge.GetValue()
+}
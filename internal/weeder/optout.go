@@ -0,0 +1,78 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// IsWeedingEnabled checks if the annotations of a namespace allow weeders to be started for the service in the namespace.
+// Weeding is disabled for all services if the namespace is annotated with wapi.IgnoreWeedingAnnotationKey and limited to
+// the listed services if it is annotated with wapi.WeedingServicesAnnotationKey.
+func IsWeedingEnabled(namespaceAnnotations map[string]string, serviceName string) bool {
+	if ignoreWeeding(namespaceAnnotations) {
+		return false
+	}
+	services, ok := namespaceAnnotations[wapi.WeedingServicesAnnotationKey]
+	if !ok {
+		return true
+	}
+	for _, service := range strings.Split(services, ",") {
+		if strings.TrimSpace(service) == serviceName {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreWeeding checks if the annotations opt out of weeding via wapi.IgnoreWeedingAnnotationKey.
+func ignoreWeeding(annotations map[string]string) bool {
+	if val, ok := annotations[wapi.IgnoreWeedingAnnotationKey]; ok {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return false
+		}
+		return b
+	}
+	return false
+}
+
+// skippedPods remembers the pods which a weeder has skipped, so that each skipped pod is logged only once
+// instead of on every event of the pod.
+type skippedPods struct {
+	mu   sync.Mutex
+	uids map[types.UID]struct{}
+}
+
+func newSkippedPods() *skippedPods {
+	return &skippedPods{uids: make(map[types.UID]struct{})}
+}
+
+// add records the pod as skipped. It returns false if the pod has already been skipped before.
+func (s *skippedPods) add(pod *v1.Pod) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.uids[pod.UID]; ok {
+		return false
+	}
+	s.uids[pod.UID] = struct{}{}
+	return true
+}
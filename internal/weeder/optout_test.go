@@ -0,0 +1,87 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsWeedingEnabled(t *testing.T) {
+	table := []struct {
+		description string
+		annotations map[string]string
+		expected    bool
+	}{
+		{"no annotations", nil, true},
+		{"weeding ignored", map[string]string{wapi.IgnoreWeedingAnnotationKey: "true"}, false},
+		{"weeding not ignored", map[string]string{wapi.IgnoreWeedingAnnotationKey: "false"}, true},
+		{"invalid value of ignore weeding annotation", map[string]string{wapi.IgnoreWeedingAnnotationKey: "maybe"}, true},
+		{"weeding limited to the service", map[string]string{wapi.WeedingServicesAnnotationKey: "kube-apiserver," + epName}, true},
+		{"weeding limited to other services", map[string]string{wapi.WeedingServicesAnnotationKey: "kube-apiserver"}, false},
+		{"weeding limited to no service", map[string]string{wapi.WeedingServicesAnnotationKey: ""}, false},
+		{"weeding ignored overrides services", map[string]string{wapi.IgnoreWeedingAnnotationKey: "true", wapi.WeedingServicesAnnotationKey: epName}, false},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(IsWeedingEnabled(entry.annotations, epName)).To(Equal(entry.expected))
+		})
+	}
+}
+
+func TestPodWhichOptedOutOfWeedingIsNotDeleted(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPodWithLabels("kube-controller-manager", namespace, nil)
+	pod.Annotations = map[string]string{wapi.IgnoreWeedingAnnotationKey: "true"}
+	setCrashLoopBackOff(pod)
+	crClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	w := NewWeeder(context.Background(), namespace, testWeederConfig, crClient, nil, epName, nil, nil, nil, logr.Discard())
+	defer w.cancelFn()
+
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", pod)).To(Succeed())
+	w.removals.Wait()
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{})).To(Succeed(), "pod which opted out of weeding should not be deleted")
+	g.Expect(w.skippedPods.add(pod)).To(BeFalse(), "pod which opted out of weeding should be remembered as skipped")
+
+	pod.Annotations = nil
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", pod)).To(Succeed())
+	w.removals.Wait()
+	g.Expect(apierrors.IsNotFound(crClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{}))).To(BeTrue(), "pod should be deleted once the annotation has been removed")
+}
+
+func TestSkippedPodsAreOnlyReportedOnce(t *testing.T) {
+	g := NewWithT(t)
+	skipped := newSkippedPods()
+	pod := createTestPodWithLabels("kube-controller-manager", namespace, nil)
+	pod.UID = "uid-1"
+	other := createTestPodWithLabels("kube-scheduler", namespace, nil)
+	other.UID = "uid-2"
+
+	g.Expect(skipped.add(pod)).To(BeTrue())
+	g.Expect(skipped.add(pod)).To(BeFalse())
+	g.Expect(skipped.add(other)).To(BeTrue())
+}
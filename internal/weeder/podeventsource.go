@@ -0,0 +1,193 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// PodEventSource dispatches pod events observed by a single shared pod informer to the weeders which have registered
+// interest in them, instead of every weeder opening its own watches.
+type PodEventSource interface {
+	// Subscribe registers interest in pods of the namespace which match the selector. Added and updated pods are sent to
+	// the returned channel. If the subscriber falls behind, only the latest state of every pod which has not been received
+	// yet is sent. The subscription is removed and the channel closed once the context is done. Pods which
+	// already exist are not sent and have to be listed by the subscriber.
+	Subscribe(ctx context.Context, namespace string, selector labels.Selector) <-chan *v1.Pod
+}
+
+type podSubscription struct {
+	selector labels.Selector
+	events   chan *v1.Pod
+	// notify signals the forwarding goroutine that pending pods have been added.
+	notify chan struct{}
+	mu     sync.Mutex
+	// pending are the latest states of the pods which have not yet been sent, by their key. A newer state of a pod
+	// replaces a pending one, so that events are coalesced per pod instead of being dropped when the subscriber falls behind.
+	pending map[types.NamespacedName]*v1.Pod
+	// order is the order in which the keys of pending pods have been added.
+	order []types.NamespacedName
+}
+
+// enqueue adds the pod to the pending pods of the subscription. It returns true if an older pending state of the same
+// pod has been replaced.
+func (sub *podSubscription) enqueue(pod *v1.Pod) bool {
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	sub.mu.Lock()
+	_, replaced := sub.pending[key]
+	if !replaced {
+		sub.order = append(sub.order, key)
+	}
+	sub.pending[key] = pod
+	sub.mu.Unlock()
+	select {
+	case sub.notify <- struct{}{}:
+	default:
+	}
+	return replaced
+}
+
+// next removes and returns the pending pod which has been added first, or nil if there is no pending pod.
+func (sub *podSubscription) next() *v1.Pod {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if len(sub.order) == 0 {
+		return nil
+	}
+	key := sub.order[0]
+	sub.order = sub.order[1:]
+	pod := sub.pending[key]
+	delete(sub.pending, key)
+	return pod
+}
+
+// forward sends the pending pods to the events channel until the context is done.
+func (sub *podSubscription) forward(ctx context.Context) {
+	for {
+		pod := sub.next()
+		if pod == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.notify:
+				continue
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case sub.events <- pod:
+		}
+	}
+}
+
+type informerPodEventSource struct {
+	mu sync.RWMutex
+	// subscriptions are indexed by namespace so that a pod event is only matched against the subscriptions of its namespace.
+	subscriptions map[string]map[*podSubscription]struct{}
+	logger        logr.Logger
+}
+
+// NewPodEventSource creates a PodEventSource which registers a single event handler with the given shared pod informer,
+// typically obtained from the cache of the controller manager.
+func NewPodEventSource(informer ctrlcache.Informer, logger logr.Logger) (PodEventSource, error) {
+	s := &informerPodEventSource{
+		subscriptions: make(map[string]map[*podSubscription]struct{}),
+		logger:        logger,
+	}
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    s.dispatch,
+		UpdateFunc: func(_, newObj interface{}) { s.dispatch(newObj) },
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *informerPodEventSource) Subscribe(ctx context.Context, namespace string, selector labels.Selector) <-chan *v1.Pod {
+	sub := &podSubscription{
+		selector: selector,
+		events:   make(chan *v1.Pod),
+		notify:   make(chan struct{}, 1),
+		pending:  make(map[types.NamespacedName]*v1.Pod),
+	}
+	s.mu.Lock()
+	if s.subscriptions[namespace] == nil {
+		s.subscriptions[namespace] = make(map[*podSubscription]struct{})
+	}
+	s.subscriptions[namespace][sub] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		sub.forward(ctx)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscriptions[namespace], sub)
+		if len(s.subscriptions[namespace]) == 0 {
+			delete(s.subscriptions, namespace)
+		}
+		close(sub.events)
+	}()
+	return sub.events
+}
+
+// StripPod is a cache transform function which drops the fields of pods that weeders do not read before the pods are
+// stored in the shared pod informer. The pod selectors of all services cannot be combined into a single label selector
+// restricting the cache, so pods of the whole seed are cached and only their object metadata (without managed fields),
+// phase, conditions and container statuses are kept to limit the memory footprint.
+func StripPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	stripped := &v1.Pod{
+		TypeMeta:   pod.TypeMeta,
+		ObjectMeta: pod.ObjectMeta,
+		Status: v1.PodStatus{
+			Phase:                 pod.Status.Phase,
+			Conditions:            pod.Status.Conditions,
+			InitContainerStatuses: pod.Status.InitContainerStatuses,
+			ContainerStatuses:     pod.Status.ContainerStatuses,
+		},
+	}
+	stripped.ManagedFields = nil
+	return stripped, nil
+}
+
+// dispatch passes the pod to all subscriptions of its namespace whose selector matches the labels of the pod.
+func (s *informerPodEventSource) dispatch(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subscriptions[pod.Namespace] {
+		if !sub.selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if sub.enqueue(pod) {
+			s.logger.V(4).Info("Replaced pending pod event with the latest state of the pod as the subscriber has not received it yet", "namespace", pod.Namespace, "podName", pod.Name, "selector", sub.selector.String())
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeInformer captures the event handler which is added to it so that tests can send pod events to it.
+type fakeInformer struct {
+	ctrlcache.Informer
+	handler toolscache.ResourceEventHandler
+}
+
+func (f *fakeInformer) AddEventHandler(handler toolscache.ResourceEventHandler) (toolscache.ResourceEventHandlerRegistration, error) {
+	f.handler = handler
+	return nil, nil
+}
+
+func TestPodEventSourceDispatchesPodsToMatchingSubscriptions(t *testing.T) {
+	g := NewWithT(t)
+	informer := &fakeInformer{}
+	source, err := NewPodEventSource(informer, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	events := source.Subscribe(ctx, namespace, labels.SelectorFromSet(labels.Set{"role": "kcm"}))
+	matchingPod := createTestPodWithLabels("kcm", namespace, map[string]string{"role": "kcm"})
+	informer.handler.OnAdd(matchingPod)
+	informer.handler.OnUpdate(matchingPod, matchingPod)
+	informer.handler.OnAdd(createTestPodWithLabels("mcm", namespace, map[string]string{"role": "mcm"}))
+	informer.handler.OnAdd(createTestPodWithLabels("kcm", "other-namespace", map[string]string{"role": "kcm"}))
+	informer.handler.OnDelete(matchingPod)
+
+	g.Eventually(events).Should(Receive(Equal(matchingPod)))
+	g.Consistently(events, 100*time.Millisecond).ShouldNot(Receive())
+}
+
+func TestPodEventSourceSendsLatestStateOfPodsToSubscriberWhichFellBehind(t *testing.T) {
+	g := NewWithT(t)
+	informer := &fakeInformer{}
+	source, err := NewPodEventSource(informer, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	events := source.Subscribe(ctx, namespace, labels.Everything())
+	kcm := createTestPodWithLabels("kcm", namespace, nil)
+	mcm := createTestPodWithLabels("mcm", namespace, nil)
+	informer.handler.OnAdd(kcm)
+	informer.handler.OnAdd(mcm)
+	// many updates of a pod arrive before the subscriber receives any of them
+	for i := 0; i < 1000; i++ {
+		updatedKCM := kcm.DeepCopy()
+		updatedKCM.ResourceVersion = fmt.Sprint(i)
+		informer.handler.OnUpdate(kcm, updatedKCM)
+	}
+	latestKCM := kcm.DeepCopy()
+	setCrashLoopBackOff(latestKCM)
+	informer.handler.OnUpdate(kcm, latestKCM)
+
+	received := map[string]*v1.Pod{}
+	g.Eventually(func() int {
+		select {
+		case pod := <-events:
+			received[pod.Name] = pod
+		default:
+		}
+		return len(received)
+	}).Should(Equal(2))
+	g.Eventually(func() *v1.Pod {
+		select {
+		case pod := <-events:
+			received[pod.Name] = pod
+		default:
+		}
+		return received["kcm"]
+	}).Should(Equal(latestKCM), "the latest state of the pod must not be dropped")
+	g.Expect(received["mcm"]).To(Equal(mcm))
+}
+
+func TestPodEventSourceRemovesSubscriptionWhenContextIsDone(t *testing.T) {
+	g := NewWithT(t)
+	informer := &fakeInformer{}
+	source, err := NewPodEventSource(informer, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	events := source.Subscribe(ctx, namespace, labels.Everything())
+	cancelFn()
+
+	g.Eventually(events).Should(BeClosed())
+	g.Expect(source.(*informerPodEventSource).subscriptions).To(BeEmpty())
+	// events which arrive after the subscription has been removed must not be sent to the closed channel
+	informer.handler.OnAdd(createTestPodWithLabels("kcm", namespace, nil))
+}
+
+func TestPodWatcherDeletesExistingAndChangedPodsInCrashLoopBackOff(t *testing.T) {
+	g := NewWithT(t)
+	podLabels := map[string]string{"gardener.cloud/component": "control-plane"}
+	existingPod := createTestPodWithLabels("kube-controller-manager", namespace, podLabels)
+	setCrashLoopBackOff(existingPod)
+	changedPod := createTestPodWithLabels("machine-controller-manager", namespace, podLabels)
+	crClient := fake.NewClientBuilder().WithObjects(existingPod, changedPod).Build()
+	informer := &fakeInformer{}
+	source, err := NewPodEventSource(informer, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	w := NewWeeder(context.Background(), namespace, testWeederConfig, crClient, source, epName, nil, nil, nil, logr.Discard())
+	defer w.cancelFn()
+
+	go w.Run()
+	g.Eventually(func() bool {
+		return apierrors.IsNotFound(crClient.Get(context.Background(), client.ObjectKeyFromObject(existingPod), &v1.Pod{}))
+	}).WithTimeout(5*time.Second).Should(BeTrue(), "existing pod in CrashLoopBackOff should have been deleted")
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(changedPod), &v1.Pod{})).To(Succeed())
+
+	setCrashLoopBackOff(changedPod)
+	informer.handler.OnUpdate(changedPod, changedPod)
+	g.Eventually(func() bool {
+		return apierrors.IsNotFound(crClient.Get(context.Background(), client.ObjectKeyFromObject(changedPod), &v1.Pod{}))
+	}).WithTimeout(5*time.Second).Should(BeTrue(), "pod which turned into CrashLoopBackOff should have been deleted")
+}
+
+func TestPodWatcherOfClosedWeederDoesNotHandlePods(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPodWithLabels("kube-controller-manager", namespace, map[string]string{"gardener.cloud/component": "control-plane"})
+	setCrashLoopBackOff(pod)
+	crClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	source, err := NewPodEventSource(&fakeInformer{}, logr.Discard())
+	g.Expect(err).ToNot(HaveOccurred())
+	w := NewWeeder(context.Background(), namespace, testWeederConfig, crClient, source, epName, nil, nil, nil, logr.Discard())
+	handled := false
+	pw := newPodWatcher(w, &metav1.LabelSelector{}, func(_ context.Context, _ logr.Logger, _ client.Client, _ *v1.Pod) error {
+		handled = true
+		return nil
+	})
+	w.cancelFn()
+
+	pw.handlePod(pod)
+	pw.handleExistingPods(labels.Everything())
+	g.Expect(handled).To(BeFalse(), "pods should not be handled once the weeder has been closed")
+}
+
+func createTestPodWithLabels(name, namespace string, podLabels map[string]string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: podLabels}}
+}
+
+func setCrashLoopBackOff(pod *v1.Pod) {
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: crashLoopBackOff}}}}
+}
+
+func TestStripPodKeepsOnlyFieldsReadByWeeders(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPodWithLabels("kcm", namespace, map[string]string{"role": "kcm"})
+	pod.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubelet"}}
+	pod.Spec = v1.PodSpec{Containers: []v1.Container{{Name: "kcm", Image: "kcm:v1"}}}
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}}
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "kcm", RestartCount: 3}}
+
+	obj, err := StripPod(pod)
+	g.Expect(err).ToNot(HaveOccurred())
+	stripped, ok := obj.(*v1.Pod)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(stripped.Name).To(Equal(pod.Name))
+	g.Expect(stripped.Labels).To(Equal(pod.Labels))
+	g.Expect(stripped.ManagedFields).To(BeNil())
+	g.Expect(stripped.Spec).To(Equal(v1.PodSpec{}))
+	g.Expect(stripped.Status.Conditions).To(Equal(pod.Status.Conditions))
+	g.Expect(stripped.Status.ContainerStatuses).To(Equal(pod.Status.ContainerStatuses))
+	g.Expect(pod.ManagedFields).To(HaveLen(1))
+
+	other, err := StripPod("not a pod")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(other).To(Equal("not a pod"))
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// removalSlotCheckInterval is the interval with which it is checked if a pod of an owner which has reached
+// the maximum number of concurrently removed pods can be removed.
+const removalSlotCheckInterval = 2 * time.Second
+
+// podRemover removes pods as configured by wapi.PodDeletion. It keeps track of the pods it has removed which are not
+// yet gone, to limit the number of pods of an owner which are removed at the same time and to not remove a pod twice.
+type podRemover struct {
+	mode                  wapi.PodDeletionMode
+	evictionRetryInterval time.Duration
+	maxConcurrentPerOwner int
+	mu                    sync.Mutex
+	// removedPods are the UIDs of the pods which have been removed and are not yet gone, by the UID of their owner.
+	removedPods map[types.UID]map[types.NamespacedName]types.UID
+	// pendingPods are the UIDs of the pods whose removal is in progress.
+	pendingPods map[types.UID]struct{}
+}
+
+func newPodRemover(pd *wapi.PodDeletion) *podRemover {
+	r := &podRemover{
+		mode:                  defaultPodDeletionMode,
+		evictionRetryInterval: defaultEvictionRetryInterval,
+		removedPods:           make(map[types.UID]map[types.NamespacedName]types.UID),
+		pendingPods:           make(map[types.UID]struct{}),
+	}
+	if pd == nil {
+		return r
+	}
+	if pd.Mode != nil {
+		r.mode = *pd.Mode
+	}
+	if pd.EvictionRetryInterval != nil {
+		r.evictionRetryInterval = pd.EvictionRetryInterval.Duration
+	}
+	if pd.MaxConcurrentPerOwner != nil {
+		r.maxConcurrentPerOwner = *pd.MaxConcurrentPerOwner
+	}
+	return r
+}
+
+// startRemoval marks the removal of the pod as in progress. It returns false if the removal of the pod is already in progress.
+func (r *podRemover) startRemoval(pod *v1.Pod) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pendingPods[pod.UID]; ok {
+		return false
+	}
+	r.pendingPods[pod.UID] = struct{}{}
+	return true
+}
+
+// finishRemoval marks the removal of the pod as no longer in progress, irrespective of whether it has succeeded.
+func (r *podRemover) finishRemoval(pod *v1.Pod) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pendingPods, pod.UID)
+}
+
+// reserve reserves the removal of the pod. If the maximum number of pods of the owner of the pod is currently being
+// removed, it waits till one of them is gone or the context is done. It returns false if the pod has already been removed.
+func (r *podRemover) reserve(ctx context.Context, crClient client.Client, pod *v1.Pod) (bool, error) {
+	owner := ownerUID(pod)
+	for {
+		removed := r.getRemovedPods(owner)
+		if removedUID, ok := removed[client.ObjectKeyFromObject(pod)]; ok && removedUID == pod.UID {
+			return false, nil
+		}
+		gone := r.findGonePods(ctx, crClient, removed)
+		if r.tryReserve(owner, pod, gone) {
+			return true, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(removalSlotCheckInterval):
+		}
+	}
+}
+
+// release releases the reservation of a pod whose removal has failed.
+func (r *podRemover) release(pod *v1.Pod) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	owner := ownerUID(pod)
+	delete(r.removedPods[owner], client.ObjectKeyFromObject(pod))
+	if len(r.removedPods[owner]) == 0 {
+		delete(r.removedPods, owner)
+	}
+}
+
+// remove deletes or evicts the pod as per the configured mode.
+func (r *podRemover) remove(ctx context.Context, log logr.Logger, crClient client.Client, pod *v1.Pod) error {
+	if r.mode != wapi.PodDeletionModeEvict {
+		return crClient.Delete(ctx, pod)
+	}
+	for {
+		eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+		err := crClient.SubResource("eviction").Create(ctx, pod, eviction)
+		if !apierrors.IsTooManyRequests(err) {
+			return err
+		}
+		log.V(4).Info("Eviction of pod has been rejected, will retry", "namespace", pod.Namespace, "podName", pod.Name, "reason", err.Error(), "retryInterval", r.evictionRetryInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.evictionRetryInterval):
+		}
+	}
+}
+
+// auditAction returns the action with which the removal of a pod is recorded in the audit log.
+func (r *podRemover) auditAction() audit.Action {
+	if r.mode == wapi.PodDeletionModeEvict {
+		return audit.ActionEvictPod
+	}
+	return audit.ActionDeletePod
+}
+
+// removalVerb returns the verb with which the removal of a pod is described in notifications.
+func (r *podRemover) removalVerb() string {
+	if r.mode == wapi.PodDeletionModeEvict {
+		return "evicted"
+	}
+	return "deleted"
+}
+
+func (r *podRemover) getRemovedPods(owner types.UID) map[types.NamespacedName]types.UID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := make(map[types.NamespacedName]types.UID, len(r.removedPods[owner]))
+	for key, uid := range r.removedPods[owner] {
+		removed[key] = uid
+	}
+	return removed
+}
+
+// findGonePods returns the keys of the removed pods which no longer exist or have been replaced by a pod with the same name.
+// A pod whose existence cannot be checked is assumed to still exist.
+func (r *podRemover) findGonePods(ctx context.Context, crClient client.Client, removed map[types.NamespacedName]types.UID) []types.NamespacedName {
+	var gone []types.NamespacedName
+	for key, uid := range removed {
+		pod := &v1.Pod{}
+		err := crClient.Get(ctx, key, pod)
+		if apierrors.IsNotFound(err) || (err == nil && pod.UID != uid) {
+			gone = append(gone, key)
+		}
+	}
+	return gone
+}
+
+func (r *podRemover) tryReserve(owner types.UID, pod *v1.Pod, gone []types.NamespacedName) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range gone {
+		delete(r.removedPods[owner], key)
+	}
+	if r.maxConcurrentPerOwner > 0 && len(r.removedPods[owner]) >= r.maxConcurrentPerOwner {
+		return false
+	}
+	if r.removedPods[owner] == nil {
+		r.removedPods[owner] = make(map[types.NamespacedName]types.UID)
+	}
+	r.removedPods[owner][client.ObjectKeyFromObject(pod)] = pod.UID
+	return true
+}
+
+// ownerUID returns the UID of the controller of the pod. A pod without a controller is its own owner.
+func ownerUID(pod *v1.Pod) types.UID {
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		return ref.UID
+	}
+	return pod.UID
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// evictingClient evicts pods by deleting them, after rejecting the first evictions with 429 (Too Many Requests).
+type evictingClient struct {
+	client.Client
+	rejections int
+	evictions  int
+}
+
+func (c *evictingClient) SubResource(subResource string) client.SubResourceClient {
+	if subResource != "eviction" {
+		return c.Client.SubResource(subResource)
+	}
+	return &evictionSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), c: c}
+}
+
+type evictionSubResourceClient struct {
+	client.SubResourceClient
+	c *evictingClient
+}
+
+func (e *evictionSubResourceClient) Create(ctx context.Context, obj client.Object, _ client.Object, _ ...client.SubResourceCreateOption) error {
+	e.c.evictions++
+	if e.c.evictions <= e.c.rejections {
+		return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+	}
+	return e.c.Client.Delete(ctx, obj)
+}
+
+func TestEvictionIsRetriedWhenRejected(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	crClient := &evictingClient{Client: fake.NewClientBuilder().WithObjects(pod).Build(), rejections: 2}
+	mode := wapi.PodDeletionModeEvict
+	r := newPodRemover(&wapi.PodDeletion{Mode: &mode, EvictionRetryInterval: &metav1.Duration{Duration: time.Millisecond}})
+
+	g.Expect(r.remove(context.Background(), logr.Discard(), crClient, pod)).To(Succeed())
+	g.Expect(crClient.evictions).To(Equal(3))
+	err := crClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "pod should have been evicted")
+	g.Expect(r.auditAction()).To(Equal(audit.ActionEvictPod))
+}
+
+func TestEvictionIsNotRetriedOnceContextIsDone(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	crClient := &evictingClient{Client: fake.NewClientBuilder().WithObjects(pod).Build(), rejections: 100}
+	mode := wapi.PodDeletionModeEvict
+	r := newPodRemover(&wapi.PodDeletion{Mode: &mode, EvictionRetryInterval: &metav1.Duration{Duration: time.Hour}})
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelFn()
+
+	g.Expect(r.remove(ctx, logr.Discard(), crClient, pod)).To(MatchError(context.DeadlineExceeded))
+	g.Expect(crClient.evictions).To(Equal(1))
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{})).To(Succeed())
+}
+
+func TestPodIsDeletedByDefault(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	crClient := &evictingClient{Client: fake.NewClientBuilder().WithObjects(pod).Build()}
+	r := newPodRemover(nil)
+
+	g.Expect(r.remove(context.Background(), logr.Discard(), crClient, pod)).To(Succeed())
+	g.Expect(crClient.evictions).To(BeZero())
+	err := crClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "pod should have been deleted")
+	g.Expect(r.auditAction()).To(Equal(audit.ActionDeletePod))
+}
+
+func TestMaxConcurrentRemovalsPerOwner(t *testing.T) {
+	g := NewWithT(t)
+	owner := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "kcm-6b7d5", UID: types.UID("kcm-6b7d5"), Controller: pointer.Bool(true)}
+	podA := createTestPod("kcm-6b7d5-a", crashLoopBackOff)
+	podA.UID, podA.OwnerReferences = "a", []metav1.OwnerReference{owner}
+	podB := createTestPod("kcm-6b7d5-b", crashLoopBackOff)
+	podB.UID, podB.OwnerReferences = "b", []metav1.OwnerReference{owner}
+	unownedPod := createTestPod("mcm", crashLoopBackOff)
+	unownedPod.UID = "c"
+	crClient := fake.NewClientBuilder().WithObjects(podA, podB, unownedPod).Build()
+	r := newPodRemover(&wapi.PodDeletion{MaxConcurrentPerOwner: pointer.Int(1)})
+
+	g.Expect(r.reserve(context.Background(), crClient, podA)).To(BeTrue())
+	g.Expect(r.reserve(context.Background(), crClient, podA)).To(BeFalse(), "a pod which has already been removed should not be removed again")
+	g.Expect(r.reserve(context.Background(), crClient, unownedPod)).To(BeTrue(), "pods of other owners should not be limited")
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelFn()
+	_, err := r.reserve(ctx, crClient, podB)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded), "a further pod of the owner should not be removed while a removed pod is not yet gone")
+
+	g.Expect(crClient.Delete(context.Background(), podA)).To(Succeed())
+	g.Expect(r.reserve(context.Background(), crClient, podB)).To(BeTrue(), "a further pod of the owner should be removed once the removed pod is gone")
+
+	r.release(podB)
+	g.Expect(r.reserve(context.Background(), crClient, podB)).To(BeTrue(), "a pod whose removal has failed should be removed again")
+}
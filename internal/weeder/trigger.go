@@ -0,0 +1,158 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// podPredicate checks if a pod meets a trigger condition. If it does, it also returns a description of the condition which is met.
+type podPredicate func(pod *v1.Pod) (bool, string)
+
+// newTriggerPredicates creates a podPredicate for each of the configured trigger conditions. serviceReadyTime is the time
+// at which the service has become ready. If no trigger conditions are configured, pods are checked for containers, but not
+// init containers, in CrashLoopBackOff.
+func newTriggerPredicates(tc *wapi.TriggerConditions, serviceReadyTime time.Time) []podPredicate {
+	if tc == nil {
+		return []podPredicate{newWaitingReasonPredicate([]string{crashLoopBackOff}, false)}
+	}
+	includeInitContainers := tc.IncludeInitContainers != nil && *tc.IncludeInitContainers
+	var predicates []podPredicate
+	if len(tc.WaitingReasons) > 0 {
+		predicates = append(predicates, newWaitingReasonPredicate(tc.WaitingReasons, includeInitContainers))
+	}
+	if tc.RestartCountAbove != nil {
+		predicates = append(predicates, newRestartCountPredicate(*tc.RestartCountAbove, serviceReadyTime, includeInitContainers).isMet)
+	}
+	if tc.NotReadyFor != nil {
+		predicates = append(predicates, newNotReadyPredicate(tc.NotReadyFor.Duration, time.Now))
+	}
+	if len(tc.LastTerminationExitCodes) > 0 {
+		predicates = append(predicates, newLastTerminationExitCodePredicate(tc.LastTerminationExitCodes, includeInitContainers))
+	}
+	return predicates
+}
+
+// newWaitingReasonPredicate creates a podPredicate which is met if any container, or init container if they are included,
+// is waiting with one of the reasons.
+func newWaitingReasonPredicate(reasons []string, includeInitContainers bool) podPredicate {
+	return func(pod *v1.Pod) (bool, string) {
+		for _, cs := range containerStatuses(pod, includeInitContainers) {
+			if cs.State.Waiting == nil {
+				continue
+			}
+			for _, reason := range reasons {
+				if cs.State.Waiting.Reason == reason {
+					return true, fmt.Sprintf("container %s is waiting with reason %s", cs.Name, reason)
+				}
+			}
+		}
+		return false, ""
+	}
+}
+
+// newNotReadyPredicate creates a podPredicate which is met if the pod has not been ready for longer than the duration.
+func newNotReadyPredicate(duration time.Duration, now func() time.Time) podPredicate {
+	return func(pod *v1.Pod) (bool, string) {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type != v1.PodReady || condition.Status == v1.ConditionTrue {
+				continue
+			}
+			if now().Sub(condition.LastTransitionTime.Time) > duration {
+				return true, fmt.Sprintf("pod has not been ready for more than %s", duration)
+			}
+		}
+		return false, ""
+	}
+}
+
+// newLastTerminationExitCodePredicate creates a podPredicate which is met if the last termination of any container, or
+// init container if they are included, which is not ready or is waiting, has ended with one of the exit codes. A container
+// which has recovered from its last termination and is ready again does not meet the predicate.
+func newLastTerminationExitCodePredicate(exitCodes []int32, includeInitContainers bool) podPredicate {
+	return func(pod *v1.Pod) (bool, string) {
+		for _, cs := range containerStatuses(pod, includeInitContainers) {
+			terminated := cs.LastTerminationState.Terminated
+			if terminated == nil || (cs.Ready && cs.State.Waiting == nil) {
+				continue
+			}
+			for _, exitCode := range exitCodes {
+				if terminated.ExitCode == exitCode {
+					return true, fmt.Sprintf("last termination of container %s has ended with exit code %d", cs.Name, exitCode)
+				}
+			}
+		}
+		return false, ""
+	}
+}
+
+// restartCountPredicate is met if any container, or init container if they are included, of a pod has restarted more often
+// than the threshold since the service has become ready. The restarts of the containers of a pod which has been created before the service
+// became ready are counted from the time the pod is checked first.
+type restartCountPredicate struct {
+	threshold             int32
+	serviceReadyTime      time.Time
+	includeInitContainers bool
+	mu                    sync.Mutex
+	// baselines are the restart counts of the containers of a pod, by the UID of the pod, from which restarts are counted.
+	baselines map[types.UID]map[string]int32
+}
+
+func newRestartCountPredicate(threshold int32, serviceReadyTime time.Time, includeInitContainers bool) *restartCountPredicate {
+	return &restartCountPredicate{
+		threshold:             threshold,
+		serviceReadyTime:      serviceReadyTime,
+		includeInitContainers: includeInitContainers,
+		baselines:             make(map[types.UID]map[string]int32),
+	}
+}
+
+func (p *restartCountPredicate) isMet(pod *v1.Pod) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	baseline, ok := p.baselines[pod.UID]
+	if !ok {
+		baseline = make(map[string]int32)
+		if pod.CreationTimestamp.Time.Before(p.serviceReadyTime) {
+			for _, cs := range containerStatuses(pod, p.includeInitContainers) {
+				baseline[cs.Name] = cs.RestartCount
+			}
+		}
+		p.baselines[pod.UID] = baseline
+	}
+	for _, cs := range containerStatuses(pod, p.includeInitContainers) {
+		if restarts := cs.RestartCount - baseline[cs.Name]; restarts > p.threshold {
+			return true, fmt.Sprintf("container %s has restarted %d times since the service has become ready", cs.Name, restarts)
+		}
+	}
+	return false, ""
+}
+
+// containerStatuses returns the statuses of the containers of the pod, preceded by those of its init containers if they
+// are included.
+func containerStatuses(pod *v1.Pod, includeInitContainers bool) []v1.ContainerStatus {
+	if !includeInitContainers {
+		return pod.Status.ContainerStatuses
+	}
+	statuses := make([]v1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	return append(statuses, pod.Status.ContainerStatuses...)
+}
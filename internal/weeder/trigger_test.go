@@ -0,0 +1,151 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestWaitingReasonPredicate(t *testing.T) {
+	g := NewWithT(t)
+	isMet := newWaitingReasonPredicate([]string{crashLoopBackOff, "Error"}, true)
+
+	waitingInitContainerPod := createTestPod("kube-apiserver", "")
+	waitingInitContainerPod.Status.InitContainerStatuses = []v1.ContainerStatus{{Name: "wait-for-etcd", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "Error"}}}}
+	met, reason := isMet(waitingInitContainerPod)
+	g.Expect(met).To(BeTrue())
+	g.Expect(reason).To(ContainSubstring("wait-for-etcd"))
+	met, _ = newWaitingReasonPredicate([]string{"Error"}, false)(waitingInitContainerPod)
+	g.Expect(met).To(BeFalse(), "init containers should only be checked if they are included")
+
+	met, _ = isMet(createTestPod("kube-controller-manager", crashLoopBackOff))
+	g.Expect(met).To(BeTrue())
+	met, _ = isMet(createTestPod("kube-controller-manager", "ContainerCreating"))
+	g.Expect(met).To(BeFalse())
+	met, _ = isMet(createTestPod("kube-controller-manager", ""))
+	g.Expect(met).To(BeFalse())
+}
+
+func TestNotReadyPredicate(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	isMet := newNotReadyPredicate(time.Minute, func() time.Time { return now })
+
+	testcases := []struct {
+		name     string
+		status   v1.ConditionStatus
+		since    time.Duration
+		expected bool
+	}{
+		{"not ready for longer than the duration", v1.ConditionFalse, 2 * time.Minute, true},
+		{"not ready for shorter than the duration", v1.ConditionFalse, 30 * time.Second, false},
+		{"ready for longer than the duration", v1.ConditionTrue, 2 * time.Minute, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := createTestPod("kube-controller-manager", "")
+			pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: tc.status, LastTransitionTime: metav1.NewTime(now.Add(-tc.since))}}
+			met, _ := isMet(pod)
+			g.Expect(met).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestLastTerminationExitCodePredicate(t *testing.T) {
+	g := NewWithT(t)
+	isMet := newLastTerminationExitCodePredicate([]int32{1, 255}, false)
+
+	pod := createTestPod("kube-controller-manager", "")
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "kcm", LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 255}}}}
+	met, reason := isMet(pod)
+	g.Expect(met).To(BeTrue())
+	g.Expect(reason).To(ContainSubstring("255"))
+
+	pod.Status.ContainerStatuses[0].Ready = true
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	met, _ = isMet(pod)
+	g.Expect(met).To(BeFalse(), "a container which is running and ready again should not be weeded for its last termination")
+
+	pod.Status.ContainerStatuses[0].Ready = false
+	pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.ExitCode = 137
+	met, _ = isMet(pod)
+	g.Expect(met).To(BeFalse())
+}
+
+func TestRestartCountPredicateCountsRestartsSinceServiceHasBecomeReady(t *testing.T) {
+	g := NewWithT(t)
+	serviceReadyTime := time.Now()
+	p := newRestartCountPredicate(2, serviceReadyTime, true)
+
+	oldPod := createTestPod("kube-controller-manager", "")
+	oldPod.UID = "old"
+	oldPod.CreationTimestamp = metav1.NewTime(serviceReadyTime.Add(-time.Hour))
+	oldPod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "kcm", RestartCount: 10}}
+	met, _ := p.isMet(oldPod)
+	g.Expect(met).To(BeFalse(), "restarts before the service has become ready should not be counted")
+	oldPod.Status.ContainerStatuses[0].RestartCount = 12
+	met, _ = p.isMet(oldPod)
+	g.Expect(met).To(BeFalse())
+	oldPod.Status.ContainerStatuses[0].RestartCount = 13
+	met, reason := p.isMet(oldPod)
+	g.Expect(met).To(BeTrue())
+	g.Expect(reason).To(ContainSubstring("restarted 3 times"))
+
+	newPod := createTestPod("machine-controller-manager", "")
+	newPod.UID = "new"
+	newPod.CreationTimestamp = metav1.NewTime(serviceReadyTime.Add(time.Minute))
+	newPod.Status.InitContainerStatuses = []v1.ContainerStatus{{Name: "wait-for-etcd", RestartCount: 3}}
+	met, _ = p.isMet(newPod)
+	g.Expect(met).To(BeTrue(), "all restarts of a pod created after the service has become ready should be counted")
+}
+
+func TestNewTriggerPredicates(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(newTriggerPredicates(nil, time.Now())).To(HaveLen(1), "pods should be checked for CrashLoopBackOff if no trigger conditions are configured")
+	g.Expect(newTriggerPredicates(&wapi.TriggerConditions{
+		WaitingReasons:           []string{crashLoopBackOff},
+		RestartCountAbove:        pointer.Int32(3),
+		NotReadyFor:              &metav1.Duration{Duration: time.Minute},
+		LastTerminationExitCodes: []int32{1},
+	}, time.Now())).To(HaveLen(4))
+	g.Expect(newTriggerPredicates(&wapi.TriggerConditions{NotReadyFor: &metav1.Duration{Duration: time.Minute}}, time.Now())).To(HaveLen(1))
+}
+
+func TestDefaultTriggerPredicateIgnoresInitContainers(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPod("kube-apiserver", "")
+	pod.Status.InitContainerStatuses = []v1.ContainerStatus{{Name: "wait-for-etcd", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: crashLoopBackOff}}}}
+
+	for _, tc := range []*wapi.TriggerConditions{nil, {WaitingReasons: []string{crashLoopBackOff}}} {
+		predicates := newTriggerPredicates(tc, time.Now())
+		g.Expect(predicates).To(HaveLen(1))
+		met, _ := predicates[0](pod)
+		g.Expect(met).To(BeFalse(), "a crashlooping init container should not trigger the deletion of a pod by default")
+	}
+
+	predicates := newTriggerPredicates(&wapi.TriggerConditions{WaitingReasons: []string{crashLoopBackOff}, IncludeInitContainers: pointer.Bool(true)}, time.Now())
+	met, reason := predicates[0](pod)
+	g.Expect(met).To(BeTrue(), "a crashlooping init container should trigger the deletion of a pod if init containers are included")
+	g.Expect(reason).To(ContainSubstring("wait-for-etcd"))
+}
@@ -16,28 +16,22 @@ package weeder
 
 import (
 	"context"
-	"fmt"
 	"time"
 
-	"github.com/gardener/dependency-watchdog/internal/util"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const watchCreationRetryInterval = 500 * time.Millisecond
-
 type podEventHandler func(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) error
 
-// podWatcher watches a pod for status changes
+// podWatcher watches the pods matching a selector for status changes
 type podWatcher struct {
 	weeder         *Weeder
 	selector       *metav1.LabelSelector
 	eventHandlerFn podEventHandler
-	k8sWatch       watch.Interface
 	log            logr.Logger
 }
 
@@ -46,69 +40,62 @@ func newPodWatcher(weeder *Weeder, selector *metav1.LabelSelector, eventHandlerF
 		weeder:         weeder,
 		selector:       selector,
 		eventHandlerFn: eventHandlerFn,
-		k8sWatch:       nil,
 		log:            weeder.logger,
 	}
 }
 
-func (pw *podWatcher) close() {
-	if pw.k8sWatch != nil {
-		pw.k8sWatch.Stop()
-	}
-}
-
+// watch subscribes to the events of the pods matching the selector and handles them till the context of the weeder is done.
+// Pods which already exist are handled once the subscription has been registered, so that no change in between is missed.
 func (pw *podWatcher) watch() {
-	defer pw.close()
-	pw.createK8sWatch(pw.weeder.ctx)
+	selector, err := metav1.LabelSelectorAsSelector(pw.selector)
+	if err != nil {
+		pw.log.Error(err, "Invalid pod selector, not watching pods", "namespace", pw.weeder.namespace, "endpoint", pw.weeder.serviceName, "selector", pw.selector.String())
+		return
+	}
+	openWatches.WithLabelValues(pw.weeder.serviceName).Inc()
+	defer openWatches.WithLabelValues(pw.weeder.serviceName).Dec()
+	events := pw.weeder.podEventSource.Subscribe(pw.weeder.ctx, pw.weeder.namespace, selector)
 	pw.log.Info("Watching for pods in CrashLoopBackoff")
+	pw.handleExistingPods(selector)
+	var recheck <-chan time.Time
+	if pw.weeder.recheckInterval > 0 {
+		ticker := time.NewTicker(pw.weeder.recheckInterval)
+		defer ticker.Stop()
+		recheck = ticker.C
+	}
 	for {
 		select {
+		case <-recheck:
+			pw.handleExistingPods(selector)
 		case <-pw.weeder.ctx.Done():
-			pw.log.Info("Exiting watch as context has timed-out or has been cancelled", "namespace", pw.weeder.namespace, "endpoint", pw.weeder.endpoints.Name, "selector", pw.selector.String())
+			pw.log.Info("Exiting watch as context has timed-out or has been cancelled", "namespace", pw.weeder.namespace, "endpoint", pw.weeder.serviceName, "selector", pw.selector.String())
 			return
-		case event, ok := <-pw.k8sWatch.ResultChan():
+		case targetPod, ok := <-events:
 			if !ok {
-				pw.log.V(3).Info("Watch has stopped, recreating kubernetes watch", "namespace", pw.weeder.namespace, "endpoint", pw.weeder.endpoints.Name, "selector", pw.selector.String())
-				pw.createK8sWatch(pw.weeder.ctx)
-				continue
-			}
-			if !canProcessEvent(event) {
-				continue
-			}
-			targetPod := event.Object.(*v1.Pod)
-			if err := pw.eventHandlerFn(pw.weeder.ctx, pw.log, pw.weeder.ctrlClient, targetPod); err != nil {
-				pw.log.Error(err, "Error processing pod", "namespace", pw.weeder.namespace, "podName", targetPod.Name)
+				return
 			}
+			pw.handlePod(targetPod)
 		}
 	}
 }
 
-func (pw *podWatcher) createK8sWatch(ctx context.Context) {
-	operation := fmt.Sprintf("Creating kubernetes watch for namespace %s, service %s with selector %s", pw.weeder.namespace, pw.weeder.endpoints.Name, pw.selector)
-	util.RetryOnError(ctx, pw.log, operation, func() error {
-		w, err := doCreateK8sWatch(ctx, pw.weeder.watchClient, pw.weeder.namespace, pw.selector)
-		if err != nil {
-			return err
-		}
-		pw.k8sWatch = w
-		return nil
-	}, watchCreationRetryInterval)
-}
-
-func doCreateK8sWatch(ctx context.Context, client kubernetes.Interface, namespace string, lSelector *metav1.LabelSelector) (watch.Interface, error) {
-	selector, err := metav1.LabelSelectorAsSelector(lSelector)
-	if err != nil {
-		return nil, err
+func (pw *podWatcher) handleExistingPods(selector labels.Selector) {
+	pods := &v1.PodList{}
+	if err := pw.weeder.ctrlClient.List(pw.weeder.ctx, pods, client.InNamespace(pw.weeder.namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		pw.log.Error(err, "Failed to list existing pods, only pods which change will be handled", "namespace", pw.weeder.namespace, "selector", pw.selector.String())
+		return
 	}
-	w, err := client.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
-		LabelSelector: selector.String(),
-	})
-	if err != nil {
-		return nil, err
+	for i := range pods.Items {
+		pw.handlePod(&pods.Items[i])
 	}
-	return w, nil
 }
 
-func canProcessEvent(ev watch.Event) bool {
-	return ev.Type == watch.Added || ev.Type == watch.Modified
+func (pw *podWatcher) handlePod(targetPod *v1.Pod) {
+	// a closed weeder must not act on pods anymore, even if events or existing pods are still pending
+	if pw.weeder.ctx.Err() != nil {
+		return
+	}
+	if err := pw.eventHandlerFn(pw.weeder.ctx, pw.log, pw.weeder.ctrlClient, targetPod); err != nil {
+		pw.log.Error(err, "Error processing pod", "namespace", pw.weeder.namespace, "podName", targetPod.Name)
+	}
 }
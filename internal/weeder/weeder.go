@@ -16,40 +16,86 @@ package weeder
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
 	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const crashLoopBackOff = "CrashLoopBackOff"
+const (
+	crashLoopBackOff = "CrashLoopBackOff"
+	// notReadyRecheckInterval is the interval with which dependant pods are checked again if a NotReadyFor trigger condition is configured.
+	notReadyRecheckInterval = 10 * time.Second
+
+	// eventReasonPodDeleted is the reason of the event recorded on a dependant pod which has been deleted or evicted.
+	eventReasonPodDeleted = "DWDPodDeleted"
+	// eventReasonDependantPodDeleted is the reason of the event recorded on the Endpoints of the service after a dependant
+	// pod has been deleted or evicted.
+	eventReasonDependantPodDeleted = "DWDDependantPodDeleted"
+)
 
 // Weeder represents an actor which will be responsible for watching dependent pods and weeding them out if they
-// are in CrashLoopBackOff.
+// meet any of the trigger conditions, which by default is being in CrashLoopBackOff.
 type Weeder struct {
 	namespace          string
-	endpoints          *v1.Endpoints
+	serviceName        string
 	ctrlClient         client.Client
-	watchClient        kubernetes.Interface
+	podEventSource     PodEventSource
 	dependantSelectors wapi.DependantSelectors
+	notifier           notifier.Notifier
+	auditSink          audit.Sink
+	eventRecorder      record.EventRecorder
+	podRemover         *podRemover
+	triggerPredicates  []podPredicate
+	strategy           wapi.WeedingStrategy
+	workloadRestarter  *workloadRestarter
+	deletionLimiter    *deletionLimiter
+	skippedPods        *skippedPods
 	ctx                context.Context
 	cancelFn           context.CancelFunc
 	logger             logr.Logger
+	// recheckInterval is the interval with which all dependant pods are checked again, as time based trigger
+	// conditions can be met without the pod being changed. It is zero if no such condition is configured.
+	recheckInterval time.Duration
+	// removals tracks the removals of pods which are carried out in the background.
+	removals *sync.WaitGroup
 }
 
-// NewWeeder creates a new Weeder for a service/endpoint.
-func NewWeeder(parentCtx context.Context, namespace string, config *wapi.Config, ctrlClient client.Client, seedClient kubernetes.Interface, ep *v1.Endpoints, logger logr.Logger) *Weeder {
-	wLogger := logger.WithValues("weederRunning", true, "watchDuration", (*config.WatchDuration).String())
-	ctx, cancelFn := context.WithTimeout(parentCtx, config.WatchDuration.Duration)
-	dependantSelectors := config.ServicesAndDependantSelectors[ep.Name]
+// NewWeeder creates a new Weeder for a service, identified by the name of its Endpoints or EndpointSlices.
+func NewWeeder(parentCtx context.Context, namespace string, config *wapi.Config, ctrlClient client.Client, podEventSource PodEventSource, serviceName string, notifier notifier.Notifier, auditSink audit.Sink, eventRecorder record.EventRecorder, logger logr.Logger) *Weeder {
+	dependantSelectors := config.ServicesAndDependantSelectors[serviceName]
+	watchDuration := config.WatchDuration
+	if dependantSelectors.WatchDuration != nil {
+		watchDuration = dependantSelectors.WatchDuration
+	}
+	wLogger := logger.WithValues("weederRunning", true, "watchDuration", watchDuration.String())
+	ctx, cancelFn := context.WithTimeout(parentCtx, watchDuration.Duration)
 	return &Weeder{
 		namespace:          namespace,
-		endpoints:          ep,
+		serviceName:        serviceName,
 		ctrlClient:         ctrlClient,
-		watchClient:        seedClient,
+		podEventSource:     podEventSource,
 		dependantSelectors: dependantSelectors,
+		notifier:           notifier,
+		auditSink:          auditSink,
+		eventRecorder:      eventRecorder,
+		podRemover:         newPodRemover(config.PodDeletion),
+		triggerPredicates:  newTriggerPredicates(dependantSelectors.TriggerConditions, time.Now()),
+		recheckInterval:    recheckIntervalFor(dependantSelectors.TriggerConditions),
+		strategy:           weedingStrategyFor(dependantSelectors),
+		workloadRestarter:  newWorkloadRestarter(),
+		deletionLimiter:    newDeletionLimiter(dependantSelectors.Limits),
+		skippedPods:        newSkippedPods(),
+		removals:           new(sync.WaitGroup),
 		ctx:                ctx,
 		cancelFn:           cancelFn,
 		logger:             wLogger,
@@ -58,39 +104,186 @@ func NewWeeder(parentCtx context.Context, namespace string, config *wapi.Config,
 
 // Run runs the Weeder which will intern create one go-routine for dependents identified by respective PodSelector.
 func (w *Weeder) Run() {
+	weedersStartedTotal.WithLabelValues(w.serviceName).Inc()
+	activeWeeders.WithLabelValues(w.serviceName).Inc()
+	defer activeWeeders.WithLabelValues(w.serviceName).Dec()
 	for _, ps := range w.dependantSelectors.PodSelectors {
-		go newPodWatcher(w, ps, shootPodIfNecessary).watch()
+		selector := metav1.FormatLabelSelector(ps)
+		go newPodWatcher(w, ps, func(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) error {
+			return w.shootPodIfNecessary(ctx, log, crClient, selector, targetPod)
+		}).watch()
 	}
 	// weeder should wait till the context expires
 	<-w.ctx.Done()
+	// removals which are still in progress are cancelled along with the context of the weeder
+	w.removals.Wait()
 }
 
-func shootPodIfNecessary(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) error {
-	if !shouldDeletePod(targetPod) {
+func (w *Weeder) shootPodIfNecessary(ctx context.Context, log logr.Logger, crClient client.Client, selector string, targetPod *v1.Pod) error {
+	shouldDelete, reason := w.shouldDeletePod(targetPod)
+	if !shouldDelete {
+		return nil
+	}
+	if w.strategy == wapi.WeedingStrategyRolloutRestart {
+		return w.restartWorkloadOfPod(ctx, log, crClient, targetPod, reason)
+	}
+	// The removal of a pod can be delayed, e.g. while its eviction is rejected due to a PodDisruptionBudget or the maximum
+	// number of pods of its owner is being removed. It is therefore carried out in the background, so that it does not hold
+	// up the handling of the other pods of the selector.
+	if !w.podRemover.startRemoval(targetPod) {
 		return nil
 	}
-	log.Info("Deleting pod", "namespace", targetPod.Namespace, "podName", targetPod.Name)
-	return crClient.Delete(ctx, targetPod)
+	w.removals.Add(1)
+	go func() {
+		defer w.removals.Done()
+		defer w.podRemover.finishRemoval(targetPod)
+		if err := w.removePod(ctx, log, crClient, selector, targetPod, reason); err != nil {
+			log.Error(err, "Error removing pod", "namespace", targetPod.Namespace, "podName", targetPod.Name)
+		}
+	}()
+	return nil
 }
 
-// shouldDeletePod checks if a pod should be deleted for quicker recovery. A pod can be deleted
-// only if it is not marked for deletion and is currently in CrashLoopBackOff state
-func shouldDeletePod(pod *v1.Pod) bool {
-	podNotMarkedForDeletion := pod.DeletionTimestamp == nil
-	return podNotMarkedForDeletion && isPodInCrashloopBackoff(pod.Status)
+// removePod removes the pod, unless it has already been removed or a weeding limit has been reached.
+func (w *Weeder) removePod(ctx context.Context, log logr.Logger, crClient client.Client, selector string, targetPod *v1.Pod, reason string) error {
+	reserved, err := w.podRemover.reserve(ctx, crClient, targetPod)
+	if err != nil || !reserved {
+		return err
+	}
+	if acquired, limit, report := w.deletionLimiter.acquire(targetPod, selector); !acquired {
+		w.podRemover.release(targetPod)
+		if report {
+			w.reportLimitReached(log, targetPod, limit)
+		}
+		return nil
+	}
+	log.Info("Deleting pod", "namespace", targetPod.Namespace, "podName", targetPod.Name, "mode", w.podRemover.mode, "reason", reason)
+	err = w.podRemover.remove(ctx, log, crClient, targetPod)
+	w.audit(ctx, log, w.podRemover.auditAction(), "Pod/"+targetPod.Name, err)
+	recordPodDeletionMetrics(w.serviceName, selector, err)
+	if err != nil {
+		w.podRemover.release(targetPod)
+		w.deletionLimiter.release(targetPod, selector)
+		return err
+	}
+	w.notify(napi.EventTypePodDeleted, "Pod/"+targetPod.Name, fmt.Sprintf("%s pod after service %s has recovered as %s", w.podRemover.removalVerb(), w.serviceName, reason))
+	w.recordPodDeletedEvents(ctx, log, crClient, targetPod, reason)
+	return nil
 }
 
-// isPodInCrashloopBackoff checks if any container in a pod is in CrashLoopBackOff
-func isPodInCrashloopBackoff(status v1.PodStatus) bool {
-	for _, containerStatus := range status.ContainerStatuses {
-		if isContainerInCrashLoopBackOff(containerStatus.State) {
-			return true
-		}
+// recordPodDeletedEvents records an event on the deleted pod and on the Endpoints of the service which triggered the weeder,
+// if an event recorder has been configured, so that the owners of the pod can see why it has been restarted.
+func (w *Weeder) recordPodDeletedEvents(ctx context.Context, log logr.Logger, crClient client.Client, pod *v1.Pod, reason string) {
+	if w.eventRecorder == nil {
+		return
+	}
+	verb := w.podRemover.removalVerb()
+	w.eventRecorder.Eventf(pod, v1.EventTypeNormal, eventReasonPodDeleted, "%s by dependency-watchdog after service %s has recovered as %s", verb, w.serviceName, reason)
+	// The Endpoints are fetched to populate their UID which is used to associate events with them.
+	endpoints := &metav1.PartialObjectMetadata{}
+	endpoints.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Endpoints"))
+	endpoints.SetNamespace(w.namespace)
+	endpoints.SetName(w.serviceName)
+	if err := crClient.Get(ctx, client.ObjectKeyFromObject(endpoints), endpoints); err != nil {
+		log.V(4).Info("Failed to get endpoints to record event, recording event without UID", "namespace", w.namespace, "endpoint", w.serviceName, "err", err.Error())
+	}
+	w.eventRecorder.Eventf(endpoints, v1.EventTypeNormal, eventReasonDependantPodDeleted, "%s dependant pod %s after service has recovered as %s", verb, pod.Name, reason)
+}
+
+// reportLimitReached reports that pods are no longer deleted as a limit has been reached.
+func (w *Weeder) reportLimitReached(log logr.Logger, pod *v1.Pod, limit string) {
+	log.Info("Not deleting pod as a weeding limit has been reached", "namespace", pod.Namespace, "podName", pod.Name, "limit", limit)
+	w.notify(napi.EventTypeWeedingLimitReached, "Pod/"+pod.Name, fmt.Sprintf("stopped deleting pods after service %s has recovered as the %s has been reached", w.serviceName, limit))
+}
+
+// restartWorkloadOfPod triggers a rollout restart of the workload which owns the pod, unless it has already been restarted by this weeder.
+func (w *Weeder) restartWorkloadOfPod(ctx context.Context, log logr.Logger, crClient client.Client, pod *v1.Pod, reason string) error {
+	workload, err := resolveWorkload(ctx, crClient, pod)
+	if err != nil {
+		return err
+	}
+	restarted, err := w.workloadRestarter.restart(ctx, crClient, workload)
+	if err == nil && !restarted {
+		log.V(4).Info("Workload owning pod has already been restarted", "namespace", pod.Namespace, "podName", pod.Name, "workload", workloadName(workload))
+		return nil
 	}
-	return false
+	w.audit(ctx, log, audit.ActionRestartWorkload, workloadName(workload), err)
+	if err != nil {
+		return err
+	}
+	log.Info("Triggered rollout restart of workload owning pod", "namespace", pod.Namespace, "podName", pod.Name, "workload", workloadName(workload), "reason", reason)
+	w.notify(napi.EventTypeWorkloadRestarted, workloadName(workload), fmt.Sprintf("restarted %s owning pod %s after service %s has recovered as %s", workloadName(workload), pod.Name, w.serviceName, reason))
+	return nil
+}
+
+// weedingStrategyFor returns the configured weeding strategy of the dependant selectors.
+func weedingStrategyFor(ds wapi.DependantSelectors) wapi.WeedingStrategy {
+	if ds.Strategy == nil {
+		return defaultWeedingStrategy
+	}
+	return *ds.Strategy
+}
+
+// recheckIntervalFor returns the interval with which dependant pods have to be checked again for the trigger conditions.
+func recheckIntervalFor(tc *wapi.TriggerConditions) time.Duration {
+	if tc == nil || tc.NotReadyFor == nil {
+		return 0
+	}
+	return notReadyRecheckInterval
+}
+
+// audit appends a record of the action of the weeder on the resource to the audit log, if one has been configured.
+// A record which cannot be written is logged but does not fail the action.
+func (w *Weeder) audit(ctx context.Context, log logr.Logger, action audit.Action, resource string, err error) {
+	if w.auditSink == nil {
+		return
+	}
+	record := audit.Record{
+		ShootNamespace: w.namespace,
+		Action:         action,
+		Resource:       resource,
+		Service:        w.serviceName,
+		Result:         audit.ResultSucceeded,
+	}
+	if err != nil {
+		record.Result = audit.ResultFailed
+		record.Error = err.Error()
+	}
+	if auditErr := w.auditSink.Write(ctx, record); auditErr != nil {
+		log.Error(auditErr, "Failed to write audit record", "action", record.Action, "resource", resource)
+	}
+}
+
+// notify notifies about the action of the weeder on the resource, if a notifier has been configured.
+func (w *Weeder) notify(event napi.EventType, resource string, message string) {
+	if w.notifier == nil {
+		return
+	}
+	w.notifier.Notify(notifier.Notification{
+		Event:          event,
+		ShootNamespace: w.namespace,
+		Resources:      []string{resource},
+		Message:        message,
+	})
 }
 
-// isContainerInCrashLoopBackOff checks if a container is in CrashLoopBackOff
-func isContainerInCrashLoopBackOff(containerState v1.ContainerState) bool {
-	return containerState.Waiting != nil && containerState.Waiting.Reason == crashLoopBackOff
+// shouldDeletePod checks if a pod should be deleted for quicker recovery. A pod can be deleted
+// only if it is not marked for deletion, has not opted out of weeding and meets any of the trigger conditions. If it should be deleted,
+// the description of the trigger condition which is met is returned as well.
+func (w *Weeder) shouldDeletePod(pod *v1.Pod) (bool, string) {
+	if pod.DeletionTimestamp != nil {
+		return false, ""
+	}
+	if ignoreWeeding(pod.Annotations) {
+		if w.skippedPods.add(pod) {
+			w.logger.Info("Skipping pod as it has opted out of weeding via annotation", "namespace", pod.Namespace, "podName", pod.Name, "annotation", wapi.IgnoreWeedingAnnotationKey)
+		}
+		return false, ""
+	}
+	for _, isMet := range w.triggerPredicates {
+		if met, reason := isMet(pod); met {
+			return true, reason
+		}
+	}
+	return false, ""
 }
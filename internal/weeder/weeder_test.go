@@ -0,0 +1,162 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	napi "github.com/gardener/dependency-watchdog/api/notifier"
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/gardener/dependency-watchdog/internal/notifier"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordingNotifier records all notifications instead of delivering them.
+type recordingNotifier struct {
+	notifications []notifier.Notification
+}
+
+func (r *recordingNotifier) Notify(notification notifier.Notification) {
+	r.notifications = append(r.notifications, notification)
+}
+
+// recordingSink records all audit records instead of writing them.
+type recordingSink struct {
+	records []audit.Record
+}
+
+func (r *recordingSink) Write(_ context.Context, record audit.Record) error {
+	r.records = append(r.records, record)
+	return nil
+}
+
+func TestShootPodIfNecessaryNotifiesAboutAndAuditsDeletedPods(t *testing.T) {
+	g := NewWithT(t)
+	crashingPod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	healthyPod := createTestPod("machine-controller-manager", "")
+	crClient := fake.NewClientBuilder().WithObjects(crashingPod, healthyPod).Build()
+	rn := &recordingNotifier{}
+	rs := &recordingSink{}
+	w := NewWeeder(context.Background(), namespace, testWeederConfig, crClient, nil, epName, rn, rs, nil, logr.Discard())
+	defer w.cancelFn()
+
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", crashingPod)).To(Succeed())
+	w.removals.Wait()
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", healthyPod)).To(Succeed())
+	w.removals.Wait()
+
+	err := crClient.Get(context.Background(), client.ObjectKeyFromObject(crashingPod), &v1.Pod{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "pod in CrashLoopBackOff should have been deleted")
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(healthyPod), &v1.Pod{})).To(Succeed(), "healthy pod should not have been deleted")
+	g.Expect(rn.notifications).To(HaveLen(1))
+	g.Expect(rn.notifications[0].Event).To(Equal(napi.EventTypePodDeleted))
+	g.Expect(rn.notifications[0].ShootNamespace).To(Equal(namespace))
+	g.Expect(rn.notifications[0].Resources).To(ConsistOf("Pod/kube-controller-manager"))
+	g.Expect(rs.records).To(Equal([]audit.Record{{
+		ShootNamespace: namespace,
+		Action:         audit.ActionDeletePod,
+		Resource:       "Pod/kube-controller-manager",
+		Service:        epName,
+		Result:         audit.ResultSucceeded,
+	}}))
+}
+
+func TestShootPodIfNecessaryDoesNotWaitForRejectedEvictions(t *testing.T) {
+	g := NewWithT(t)
+	pod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	crClient := &evictingClient{Client: fake.NewClientBuilder().WithObjects(pod).Build(), rejections: 100}
+	mode := wapi.PodDeletionModeEvict
+	config := &wapi.Config{
+		WatchDuration:                 testWeederConfig.WatchDuration,
+		ServicesAndDependantSelectors: testWeederConfig.ServicesAndDependantSelectors,
+		PodDeletion:                   &wapi.PodDeletion{Mode: &mode, EvictionRetryInterval: &metav1.Duration{Duration: time.Hour}},
+	}
+	w := NewWeeder(context.Background(), namespace, config, crClient, nil, epName, nil, nil, nil, logr.Discard())
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	g.Expect(w.shootPodIfNecessary(ctx, logr.Discard(), crClient, "", pod)).To(Succeed(), "rejected eviction should be retried in the background")
+	g.Expect(w.shootPodIfNecessary(ctx, logr.Discard(), crClient, "", pod)).To(Succeed())
+	cancelFn()
+	w.removals.Wait()
+
+	g.Expect(crClient.evictions).To(Equal(1), "pod whose removal is in progress should not be removed again")
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{})).To(Succeed())
+}
+
+func TestShootPodIfNecessaryStopsOnceLimitIsReached(t *testing.T) {
+	g := NewWithT(t)
+	firstPod := createTestPod("kube-controller-manager", crashLoopBackOff)
+	secondPod := createTestPod("machine-controller-manager", crashLoopBackOff)
+	crClient := fake.NewClientBuilder().WithObjects(firstPod, secondPod).Build()
+	config := &wapi.Config{
+		WatchDuration: testWeederConfig.WatchDuration,
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{epName: {
+			Limits: &wapi.WeedingLimits{MaxDeletionsPerWeeder: pointer.Int(1)},
+		}},
+	}
+	rn := &recordingNotifier{}
+	w := NewWeeder(context.Background(), namespace, config, crClient, nil, epName, rn, nil, nil, logr.Discard())
+	defer w.cancelFn()
+
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", firstPod)).To(Succeed())
+	w.removals.Wait()
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", secondPod)).To(Succeed())
+	w.removals.Wait()
+
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(secondPod), &v1.Pod{})).To(Succeed(), "pod should not be deleted once the limit has been reached")
+	g.Expect(rn.notifications).To(HaveLen(2))
+	g.Expect(rn.notifications[1].Event).To(Equal(napi.EventTypeWeedingLimitReached))
+	g.Expect(rn.notifications[1].Message).To(ContainSubstring("maximum of 1 deletions per weeder"))
+}
+
+func TestWatchDurationCanBeOverriddenPerService(t *testing.T) {
+	g := NewWithT(t)
+	config := &wapi.Config{
+		WatchDuration: &metav1.Duration{Duration: time.Minute},
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+			epName:           {WatchDuration: &metav1.Duration{Duration: time.Hour}},
+			"kube-apiserver": {},
+		},
+	}
+	etcdWeeder := NewWeeder(context.Background(), namespace, config, nil, nil, epName, nil, nil, nil, logr.Discard())
+	defer etcdWeeder.cancelFn()
+	kapiWeeder := NewWeeder(context.Background(), namespace, config, nil, nil, "kube-apiserver", nil, nil, nil, logr.Discard())
+	defer kapiWeeder.cancelFn()
+
+	deadline, _ := etcdWeeder.ctx.Deadline()
+	g.Expect(time.Until(deadline)).To(BeNumerically(">", 59*time.Minute))
+	deadline, _ = kapiWeeder.ctx.Deadline()
+	g.Expect(time.Until(deadline)).To(BeNumerically("<=", time.Minute))
+}
+
+func createTestPod(name string, waitingReason string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if waitingReason != "" {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: waitingReason}}}}
+	}
+	return pod
+}
@@ -115,5 +115,10 @@ func (wm *weederManager) GetWeederRegistration(key string) (Registration, bool)
 
 // createKey creates a key to uniquely identify a weeder
 func createKey(w Weeder) string {
-	return w.namespace + "/" + w.endpoints.Name
+	return CreateKey(w.namespace, w.serviceName)
+}
+
+// CreateKey creates the key with which the weeder of the service in the namespace is registered.
+func CreateKey(namespace, serviceName string) string {
+	return namespace + "/" + serviceName
 }
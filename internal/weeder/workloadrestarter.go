@@ -0,0 +1,111 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weeder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restartedAtAnnotationKey is the pod template annotation with which kubectl triggers a rollout restart of a workload.
+const restartedAtAnnotationKey = "kubectl.kubernetes.io/restartedAt"
+
+// workloadRestarter triggers rollout restarts of the workloads owning dependant pods. It remembers the workloads it has
+// restarted, so that each workload is restarted at most once, however many of its pods meet a trigger condition.
+type workloadRestarter struct {
+	mu        sync.Mutex
+	restarted map[string]struct{}
+}
+
+func newWorkloadRestarter() *workloadRestarter {
+	return &workloadRestarter{restarted: make(map[string]struct{})}
+}
+
+// resolveWorkload resolves the workload which owns the pod and supports a rollout restart, i.e. the Deployment owning the
+// ReplicaSet of the pod or the StatefulSet of the pod.
+func resolveWorkload(ctx context.Context, crClient client.Client, pod *v1.Pod) (client.Object, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref != nil && ref.APIVersion == appsv1.SchemeGroupVersion.String() {
+		switch ref.Kind {
+		case "StatefulSet":
+			return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: pod.Namespace}}, nil
+		case "ReplicaSet":
+			rs := &metav1.PartialObjectMetadata{}
+			rs.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))
+			if err := crClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, rs); err != nil {
+				return nil, fmt.Errorf("failed to get ReplicaSet %s owning pod %s: %w", ref.Name, pod.Name, err)
+			}
+			if rsRef := metav1.GetControllerOf(rs); rsRef != nil && rsRef.APIVersion == appsv1.SchemeGroupVersion.String() && rsRef.Kind == "Deployment" {
+				return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: rsRef.Name, Namespace: pod.Namespace}}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("pod %s is not owned by a Deployment or StatefulSet which can be restarted", pod.Name)
+}
+
+// restart triggers a rollout restart of the workload by patching the restartedAt annotation of its pod template, unless
+// the workload has already been restarted. It returns false if the workload has already been restarted.
+func (r *workloadRestarter) restart(ctx context.Context, crClient client.Client, workload client.Object) (bool, error) {
+	key := workloadName(workload)
+	r.mu.Lock()
+	if _, ok := r.restarted[key]; ok {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.restarted[key] = struct{}{}
+	r.mu.Unlock()
+
+	patchBytes, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{restartedAtAnnotationKey: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err == nil {
+		err = crClient.Patch(ctx, workload, client.RawPatch(types.MergePatchType, patchBytes))
+	}
+	if err != nil {
+		// a failed restart may be re-attempted for another pod of the workload
+		r.mu.Lock()
+		delete(r.restarted, key)
+		r.mu.Unlock()
+		return false, err
+	}
+	return true, nil
+}
+
+// workloadName returns the kind and name of the workload, e.g. Deployment/kube-apiserver.
+func workloadName(workload client.Object) string {
+	switch workload.(type) {
+	case *appsv1.Deployment:
+		return "Deployment/" + workload.GetName()
+	case *appsv1.StatefulSet:
+		return "StatefulSet/" + workload.GetName()
+	default:
+		return workload.GetName()
+	}
+}
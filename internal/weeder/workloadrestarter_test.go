@@ -0,0 +1,105 @@
+// Copyright 2023 SAP SE or an SAP affiliate company
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/audit"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: types.UID(kind + "-" + name), Controller: pointer.Bool(true)}}
+}
+
+func TestResolveWorkload(t *testing.T) {
+	g := NewWithT(t)
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "kcm-6b7d5", Namespace: namespace, OwnerReferences: controllerRef("Deployment", "kcm")}}
+	orphanRS := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: namespace}}
+	crClient := fake.NewClientBuilder().WithObjects(rs, orphanRS).Build()
+
+	testcases := []struct {
+		name             string
+		ownerReferences  []metav1.OwnerReference
+		expectedWorkload string
+	}{
+		{"pod of a Deployment", controllerRef("ReplicaSet", "kcm-6b7d5"), "Deployment/kcm"},
+		{"pod of a StatefulSet", controllerRef("StatefulSet", "etcd-main"), "StatefulSet/etcd-main"},
+		{"pod of a ReplicaSet without Deployment", controllerRef("ReplicaSet", "orphan"), ""},
+		{"pod of a missing ReplicaSet", controllerRef("ReplicaSet", "missing"), ""},
+		{"pod of a DaemonSet", controllerRef("DaemonSet", "node-exporter"), ""},
+		{"pod without owner", nil, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := createTestPod("pod", crashLoopBackOff)
+			pod.OwnerReferences = tc.ownerReferences
+			workload, err := resolveWorkload(context.Background(), crClient, pod)
+			if tc.expectedWorkload == "" {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(workloadName(workload)).To(Equal(tc.expectedWorkload))
+			g.Expect(workload.GetNamespace()).To(Equal(namespace))
+		})
+	}
+}
+
+func TestRolloutRestartStrategyRestartsWorkloadOnce(t *testing.T) {
+	g := NewWithT(t)
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "kcm", Namespace: namespace}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "kcm-6b7d5", Namespace: namespace, OwnerReferences: controllerRef("Deployment", "kcm")}}
+	podA := createTestPod("kcm-6b7d5-a", crashLoopBackOff)
+	podA.OwnerReferences = controllerRef("ReplicaSet", "kcm-6b7d5")
+	podB := createTestPod("kcm-6b7d5-b", crashLoopBackOff)
+	podB.OwnerReferences = controllerRef("ReplicaSet", "kcm-6b7d5")
+	crClient := fake.NewClientBuilder().WithObjects(deployment, rs, podA, podB).Build()
+	strategy := wapi.WeedingStrategyRolloutRestart
+	config := &wapi.Config{
+		WatchDuration:                 testWeederConfig.WatchDuration,
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{epName: {Strategy: &strategy}},
+	}
+	rn := &recordingNotifier{}
+	sink := &recordingSink{}
+	w := NewWeeder(context.Background(), namespace, config, crClient, nil, epName, rn, sink, nil, logr.Discard())
+	defer w.cancelFn()
+
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", podA)).To(Succeed())
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+	restartedAt := deployment.Spec.Template.Annotations[restartedAtAnnotationKey]
+	g.Expect(restartedAt).ToNot(BeEmpty(), "rollout restart of the deployment should have been triggered")
+
+	g.Expect(w.shootPodIfNecessary(context.Background(), logr.Discard(), crClient, "", podB)).To(Succeed())
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(podA), &v1.Pod{})).To(Succeed(), "pods should not be deleted")
+	g.Expect(crClient.Get(context.Background(), client.ObjectKeyFromObject(podB), &v1.Pod{})).To(Succeed(), "pods should not be deleted")
+	g.Expect(rn.notifications).To(HaveLen(1), "the deployment should only be restarted once")
+	g.Expect(rn.notifications[0].Resources).To(ConsistOf("Deployment/kcm"))
+	g.Expect(sink.records).To(HaveLen(1))
+	g.Expect(sink.records[0].Action).To(Equal(audit.ActionRestartWorkload))
+}